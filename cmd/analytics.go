@@ -53,8 +53,13 @@ nomnom analytics --dir /path/to/files`,
 			presenter.Warnf("No model usage has been recorded yet.")
 		} else {
 			for _, model := range models {
+				route := ""
+				if model.Route != "" {
+					route = "[" + model.Route + "] "
+				}
 				presenter.Infof(
-					"%s/%s: requests=%d vision=%d tokens=%d (prompt=%d completion=%d)",
+					"%s%s/%s: requests=%d vision=%d tokens=%d (prompt=%d completion=%d)",
+					route,
 					model.Provider,
 					model.Model,
 					model.Requests,
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"nomnom/internal/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditFull bool
+	auditLog  string
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect recorded provider requests",
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show <file>",
+	Short: "Show what was sent to and returned by the provider for a file",
+	Long: `Show every recorded request for a file, across all sessions.
+
+The audit logs are found in the .nomnom/audit directory of the file's
+directory or the nearest parent that has one; --log reads a single session
+log or audit directory instead.`,
+	Example: `nomnom audit show ~/Downloads/taxes/scan.pdf
+nomnom audit show taxes/scan.pdf --full
+nomnom audit show ~/Downloads/taxes/scan.pdf --log ~/Downloads/.nomnom/audit/1718000000000000000.jsonl`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var logs []string
+		var err error
+		if auditLog != "" {
+			logs, err = utils.AuditLogsIn(auditLog)
+		} else {
+			logs, err = utils.FindAuditLogs(args[0])
+		}
+		if err != nil {
+			return err
+		}
+
+		entries, err := utils.AuditEntriesFor(logs, args[0])
+		if err != nil {
+			return err
+		}
+
+		presenter := newCLIPresenter()
+		presenter.Titlef("Audit trail: %s", args[0])
+		presenter.Divider()
+		for _, entry := range entries {
+			printAuditEntry(entry, auditFull)
+		}
+		if len(entries) == 0 {
+			presenter.Warnf("No audit entries found for %s in %d session logs.", args[0], len(logs))
+		}
+		return nil
+	},
+}
+
+func init() {
+	auditShowCmd.Flags().BoolVar(&auditFull, "full", false, "Print the prompt, context and raw response of every request")
+	auditShowCmd.Flags().StringVar(&auditLog, "log", "", "Read this session log or audit directory instead of finding .nomnom/audit")
+	auditCmd.AddCommand(auditShowCmd)
+	rootCmd.AddCommand(auditCmd)
+}
+
+func printAuditEntry(entry utils.AuditEntry, full bool) {
+	subject := entry.File
+	if subject == "" {
+		subject = "(directory)"
+	}
+
+	model := entry.Provider + "/" + entry.Model
+	if entry.Route != "" {
+		model = "[" + entry.Route + "] " + model
+	}
+
+	fmt.Printf("%s %s %s #%d %s %dms\n",
+		color.CyanString(entry.Timestamp.Local().Format("15:04:05")),
+		subject,
+		entry.Kind,
+		entry.Attempt,
+		model,
+		entry.LatencyMS)
+
+	if entry.RetryHint != "" {
+		fmt.Printf("  %s %s\n", color.YellowString("retry after:"), entry.RetryHint)
+	}
+	switch {
+	case entry.Error != "":
+		fmt.Printf("  %s %s\n", color.RedString("error:"), entry.Error)
+	case entry.Name != "":
+		name := entry.Name
+		if entry.Folder != "" {
+			name = entry.Folder + "/" + name
+		}
+		fmt.Printf("  %s %s (confidence %.2f)\n", color.GreenString("name:"), name, entry.Confidence)
+	}
+
+	if !full {
+		fmt.Printf("  %s %s\n", color.BlueString("response:"), oneLine(entry.Response))
+		return
+	}
+
+	printAuditBlock("prompt", entry.Prompt)
+	printAuditBlock("context", entry.Context)
+	if entry.Image != "" {
+		printAuditBlock("image", entry.Image)
+	}
+	printAuditBlock("response", entry.Response)
+}
+
+func printAuditBlock(label, text string) {
+	fmt.Printf("  %s\n", color.BlueString(label+":"))
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		fmt.Printf("    %s\n", line)
+	}
+}
+
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
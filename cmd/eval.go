@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+
+	app "nomnom/internal/app"
+	eval "nomnom/internal/eval"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	evalDataset    string
+	evalConfigPath string
+	evalPrompts    []string
+	evalModels     []string
+	evalOutput     string
+	evalReplay     []string
+	evalVerbose    bool
+)
+
+var evalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Score generated names against a dataset of expected names",
+	Long: `Eval names every file of a dataset with the configured provider, or with each
+variant of the dataset, and reports exact-match, token-overlap and
+policy-compliance scores. Outputs are saved so later runs can be compared with
+--replay without contacting the provider again.`,
+	Example: `nomnom eval --dataset cases.json
+nomnom eval --dataset cases.json --prompt research --prompt images
+nomnom eval --dataset cases.json --model deepseek-chat --model deepseek-reasoner
+nomnom eval --dataset cases.json --replay .nomnom/eval/1718000000000000000.json --replay .nomnom/eval/1718100000000000000.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		presenter := newCLIPresenter()
+		service := app.NewService()
+
+		if len(evalReplay) > 0 {
+			results, err := service.ReplayEval(evalDataset, evalReplay)
+			if err != nil {
+				return err
+			}
+			printEvalResults(presenter, results, evalVerbose)
+			return nil
+		}
+
+		presenter.Banner()
+		presenter.Divider()
+		results, path, err := service.Evaluate(app.EvalOptions{
+			DatasetPath: evalDataset,
+			ConfigPath:  evalConfigPath,
+			Prompts:     evalPrompts,
+			Models:      evalModels,
+			OutputPath:  evalOutput,
+		}, presenter)
+		if err != nil {
+			return err
+		}
+
+		printEvalResults(presenter, results, evalVerbose)
+		presenter.Divider()
+		presenter.Infof("Outputs saved to %s", path)
+		return nil
+	},
+}
+
+func init() {
+	evalCmd.Flags().StringVar(&evalDataset, "dataset", "", "Dataset JSON file with the cases to score")
+	evalCmd.Flags().StringVarP(&evalConfigPath, "config", "c", "", "Config file path")
+	evalCmd.Flags().StringArrayVarP(&evalPrompts, "prompt", "p", nil, "Prompt name or text to evaluate, repeatable")
+	evalCmd.Flags().StringArrayVarP(&evalModels, "model", "m", nil, "Model to evaluate with the configured provider, repeatable")
+	evalCmd.Flags().StringVarP(&evalOutput, "output", "o", "", "Where to save the outputs (default .nomnom/eval/<time>.json next to the dataset)")
+	evalCmd.Flags().StringArrayVar(&evalReplay, "replay", nil, "Score saved outputs instead of running the provider, repeatable")
+	evalCmd.Flags().BoolVarP(&evalVerbose, "verbose", "v", false, "Print every case, not only the misses")
+	evalCmd.MarkFlagRequired("dataset")
+	rootCmd.AddCommand(evalCmd)
+}
+
+func printEvalResults(presenter cliPresenter, results eval.Results, verbose bool) {
+	for _, run := range results.Runs {
+		presenter.Divider()
+		presenter.Titlef("%s", run.Label())
+		for _, output := range run.Outputs {
+			if !verbose && output.Exact && len(output.Violations) == 0 {
+				continue
+			}
+			printEvalOutput(output)
+		}
+	}
+
+	presenter.Divider()
+	presenter.Titlef("Scores")
+	for _, run := range results.Runs {
+		summary := run.Summary
+		fmt.Printf("%s exact=%s overlap=%s compliance=%s named=%d/%d %.1fs\n",
+			color.GreenString(run.Label()),
+			percent(summary.ExactMatch),
+			percent(summary.Overlap),
+			percent(summary.Compliance),
+			summary.Named,
+			summary.Cases,
+			run.Seconds)
+	}
+}
+
+func printEvalOutput(output eval.Output) {
+	switch {
+	case output.Error != "":
+		fmt.Printf("  %s %s\n", output.File, color.RedString("error: "+output.Error))
+	case output.Name == "":
+		fmt.Printf("  %s %s\n", output.File, color.RedString("no name"))
+	default:
+		mark := color.YellowString("~")
+		if output.Exact {
+			mark = color.GreenString("=")
+		}
+		fmt.Printf("  %s %s -> %s (overlap %.2f)\n", mark, output.File, output.Name, output.Overlap)
+	}
+	for _, violation := range output.Violations {
+		fmt.Printf("      %s %s\n", color.YellowString("policy:"), violation)
+	}
+}
+
+func percent(rate float64) string {
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	files "nomnom/internal/files"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var formatsCmd = &cobra.Command{
+	Use:   "formats",
+	Short: "List the file formats NomNom can read and how they are categorized",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		presenter := newCLIPresenter()
+		presenter.Titlef("Supported formats")
+		presenter.Infof("Files recognized by their magic bytes are matched by content type, others by extension.")
+		presenter.Infof("Commands in content_extraction.extractors run before these and fall back to them on failure.")
+		presenter.Divider()
+
+		for _, format := range files.Formats() {
+			name := color.GreenString(format.Name)
+			if format.Vision {
+				name += " " + color.MagentaString("(vision)")
+			}
+			fmt.Printf("%s %s\n", name, color.CyanString("-> "+format.Category))
+			if format.Description != "" {
+				fmt.Printf("  %s\n", format.Description)
+			}
+			if len(format.Extensions) > 0 {
+				fmt.Printf("  %s %s\n", color.BlueString("extensions:"), strings.Join(format.Extensions, " "))
+			}
+			if len(format.MIMETypes) > 0 {
+				fmt.Printf("  %s %s\n", color.BlueString("types:"), strings.Join(format.MIMETypes, " "))
+			}
+		}
+
+		fmt.Printf("%s %s\n", color.GreenString("other"), color.CyanString("-> "+files.CategoryOthers))
+		fmt.Println("  Anything else: text is sent as is, binaries are described by their detected type")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(formatsCmd)
+}
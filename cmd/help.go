@@ -57,7 +57,10 @@ Flags:
 		color.CyanString("Organize files into folders based on content"))
 
 	rootCmd.Flags().StringVarP(&cmdArgs.prompt, "prompt", "p", "",
-		color.CyanString("Custom AI prompt (use 'research' or 'images' for built-in prompts)"))
+		color.CyanString("Prompt name or custom prompt text (see 'nomnom prompts list')"))
+
+	rootCmd.Flags().BoolVarP(&cmdArgs.taxonomy, "taxonomy", "t", false,
+		color.CyanString("Propose a folder taxonomy for the whole directory before naming files"))
 
 	rootCmd.SetHelpTemplate(helpTemplate)
 
@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 
+	ai "nomnom/internal/ai"
 	content "nomnom/internal/content"
 	"nomnom/internal/utils"
 
@@ -49,9 +51,14 @@ _  _                 _  _
 }
 
 func (cliPresenter) Approve(action, oldName, newName string) (utils.ApprovalDecision, error) {
+	items := []string{"yes", "no", "approve all"}
+	if action == "rename" {
+		items = append(items, "refine")
+	}
+
 	prompt := promptui.Select{
 		Label: fmt.Sprintf("Approve %s for %s to %s", action, oldName, newName),
-		Items: []string{"yes", "no", "approve all"},
+		Items: items,
 	}
 	_, result, err := prompt.Run()
 	if err != nil {
@@ -63,11 +70,95 @@ func (cliPresenter) Approve(action, oldName, newName string) (utils.ApprovalDeci
 		return utils.ApprovalYes, nil
 	case "approve all":
 		return utils.ApprovalAll, nil
+	case "refine":
+		return utils.ApprovalRefine, nil
 	default:
 		return utils.ApprovalNo, nil
 	}
 }
 
+func (cliPresenter) Feedback(oldName, newName string) (string, error) {
+	return promptText(fmt.Sprintf("How should %s (suggested %s) change", oldName, newName), "", nonEmptyValidator("feedback"))
+}
+
+func (p cliPresenter) ReviewTaxonomy(folders []utils.TaxonomyFolder) ([]utils.TaxonomyFolder, error) {
+	folders = slices.Clone(folders)
+	for {
+		p.Titlef("Proposed folders")
+		for _, folder := range folders {
+			p.Infof("%s: %s", folder.Name, folder.Description)
+		}
+
+		action, err := promptSelect("Review the folder taxonomy", []string{"approve", "add a folder", "edit a folder", "remove a folder", "discard taxonomy"}, "approve")
+		if err != nil {
+			return nil, err
+		}
+
+		switch action {
+		case "approve":
+			if len(folders) == 0 {
+				p.Warnf("No folders left. Falling back to category folders.")
+				return nil, nil
+			}
+			return folders, nil
+		case "discard taxonomy":
+			p.Warnf("Taxonomy discarded. Falling back to category folders.")
+			return nil, nil
+		case "add a folder":
+			folder, err := promptFolder(utils.TaxonomyFolder{})
+			if err != nil {
+				return nil, err
+			}
+			folders = append(folders, folder)
+		case "edit a folder", "remove a folder":
+			if len(folders) == 0 {
+				p.Warnf("There are no folders to change.")
+				continue
+			}
+			index, err := promptFolderIndex(folders)
+			if err != nil {
+				return nil, err
+			}
+			if action == "remove a folder" {
+				folders = slices.Delete(folders, index, index+1)
+				continue
+			}
+			folder, err := promptFolder(folders[index])
+			if err != nil {
+				return nil, err
+			}
+			folders[index] = folder
+		}
+	}
+}
+
+func promptFolder(current utils.TaxonomyFolder) (utils.TaxonomyFolder, error) {
+	name, err := promptText("Folder name", current.Name, ai.ValidateFolderName)
+	if err != nil {
+		return utils.TaxonomyFolder{}, err
+	}
+	description, err := promptOptionalText("Folder description", current.Description)
+	if err != nil {
+		return utils.TaxonomyFolder{}, err
+	}
+	return utils.TaxonomyFolder{Name: name, Description: description}, nil
+}
+
+func promptFolderIndex(folders []utils.TaxonomyFolder) (int, error) {
+	names := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		names = append(names, folder.Name)
+	}
+
+	prompt := promptui.Select{
+		Label: "Folder",
+		Items: names,
+		Size:  min(len(names), 10),
+	}
+	index, _, err := prompt.Run()
+	return index, err
+}
+
 func (cliPresenter) PrintSummary(results []content.ProcessResult) {
 	success := color.New(color.FgGreen).SprintFunc()
 	failed := color.New(color.FgRed).SprintFunc()
@@ -76,6 +167,9 @@ func (cliPresenter) PrintSummary(results []content.ProcessResult) {
 	fmt.Println(color.CyanString("══════════════════════"))
 
 	for _, result := range results {
+		if printLowConfidence(result) {
+			continue
+		}
 		if result.Success {
 			fmt.Printf("%s \033]8;;file://%s\033\\%s\033]8;;\033\\ → \033]8;;file://%s\033\\%s\033]8;;\033\\\n",
 				success("✓"),
@@ -94,6 +188,26 @@ func (cliPresenter) PrintSummary(results []content.ProcessResult) {
 	}
 }
 
+// printLowConfidence prints results that were left untouched because of ai.min_confidence.
+func printLowConfidence(result content.ProcessResult) bool {
+	switch result.Status {
+	case content.PlanKeptOriginal:
+		fmt.Printf("%s %s (confidence %.2f)\n",
+			color.BlueString("⏸  Kept original name:"),
+			filepath.Base(result.OriginalPath),
+			result.Confidence)
+	case content.PlanNeedsReview:
+		fmt.Printf("%s %s → %s? (confidence %.2f)\n",
+			color.YellowString("⚠️  Needs review:"),
+			filepath.Base(result.OriginalPath),
+			result.SuggestedName,
+			result.Confidence)
+	default:
+		return false
+	}
+	return true
+}
+
 func (cliPresenter) PrintResults(results []content.ProcessResult, dryRun bool) int {
 	successCount := 0
 
@@ -101,6 +215,9 @@ func (cliPresenter) PrintResults(results []content.ProcessResult, dryRun bool) i
 	fmt.Println(color.CyanString("══════════════════════"))
 
 	for _, result := range results {
+		if printLowConfidence(result) {
+			continue
+		}
 		if !result.Success {
 			fmt.Printf("%s %s (Error: %v)\n",
 				color.RedString("❌ Failed to process:"),
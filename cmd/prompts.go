@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	prompts "nomnom/internal/prompts"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	promptsConfigPath string
+	promptAddFile     string
+	promptAddText     string
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Manage named prompts for --prompt",
+	Long: `Named prompts can be passed to --prompt by name. Built-in prompts ship with
+NomNom; your own prompts are plain .txt files in a "prompts" directory next to
+the config file, so teams can share them by copying the files.`,
+}
+
+var promptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List built-in and user prompts",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		library, err := prompts.ForConfig(promptsConfigPath)
+		if err != nil {
+			return err
+		}
+		list, err := library.List()
+		if err != nil {
+			return err
+		}
+
+		presenter := newCLIPresenter()
+		presenter.Titlef("Prompts")
+		presenter.Infof("User prompt directory: %s", library.Dir())
+		presenter.Divider()
+		for _, prompt := range list {
+			source := string(prompt.Source)
+			if prompt.Overrides {
+				source += ", overrides built-in"
+			}
+			fmt.Printf("%s %s %s\n", color.GreenString(prompt.Name), color.CyanString("("+source+")"), firstLine(prompt.Text))
+		}
+		return nil
+	},
+}
+
+var promptsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print a prompt",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		library, err := prompts.ForConfig(promptsConfigPath)
+		if err != nil {
+			return err
+		}
+		prompt, ok, err := library.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("prompt %q not found", args[0])
+		}
+
+		fmt.Println(strings.TrimRight(prompt.Text, "\n"))
+		return nil
+	},
+}
+
+var promptsAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a user prompt from text, a file, or your editor",
+	Example: `nomnom prompts add invoices --text "Name invoices as vendor_yyyy-mm-dd_amount."
+nomnom prompts add invoices --file team-prompts/invoices.txt
+nomnom prompts add invoices`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		library, err := prompts.ForConfig(promptsConfigPath)
+		if err != nil {
+			return err
+		}
+		if err := prompts.ValidateName(args[0]); err != nil {
+			return err
+		}
+
+		var text string
+		switch {
+		case promptAddText != "" && promptAddFile != "":
+			return fmt.Errorf("use either --text or --file, not both")
+		case promptAddText != "":
+			text = promptAddText
+		case promptAddFile == "-":
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("read prompt from stdin: %w", err)
+			}
+			text = string(data)
+		case promptAddFile != "":
+			data, err := os.ReadFile(promptAddFile)
+			if err != nil {
+				return fmt.Errorf("read prompt file: %w", err)
+			}
+			text = string(data)
+		default:
+			text, err = editText("")
+			if err != nil {
+				return err
+			}
+		}
+
+		prompt, err := library.Add(args[0], text)
+		if err != nil {
+			return err
+		}
+		newCLIPresenter().Infof("Added prompt %s at %s", prompt.Name, prompt.Path)
+		return nil
+	},
+}
+
+var promptsEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Edit a user prompt in $EDITOR, copying a built-in prompt first if needed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		library, err := prompts.ForConfig(promptsConfigPath)
+		if err != nil {
+			return err
+		}
+		prompt, ok, err := library.Lookup(args[0])
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("prompt %q not found, use add to create it", args[0])
+		}
+
+		text, err := editText(prompt.Text)
+		if err != nil {
+			return err
+		}
+		if text == prompt.Text {
+			newCLIPresenter().Infof("Prompt %s unchanged", prompt.Name)
+			return nil
+		}
+
+		saved, err := library.Save(prompt.Name, text)
+		if err != nil {
+			return err
+		}
+		newCLIPresenter().Infof("Saved prompt %s at %s", saved.Name, saved.Path)
+		return nil
+	},
+}
+
+var promptsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a user prompt",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		library, err := prompts.ForConfig(promptsConfigPath)
+		if err != nil {
+			return err
+		}
+		if err := library.Remove(args[0]); err != nil {
+			return err
+		}
+		newCLIPresenter().Infof("Removed prompt %s", args[0])
+		return nil
+	},
+}
+
+func init() {
+	promptsCmd.PersistentFlags().StringVarP(&promptsConfigPath, "config", "c", "", "Config file whose prompt directory to use")
+	promptsAddCmd.Flags().StringVar(&promptAddFile, "file", "", "Read the prompt from a file, or - for stdin")
+	promptsAddCmd.Flags().StringVar(&promptAddText, "text", "", "Prompt text")
+	promptsCmd.AddCommand(promptsListCmd, promptsShowCmd, promptsAddCmd, promptsEditCmd, promptsRemoveCmd)
+	rootCmd.AddCommand(promptsCmd)
+}
+
+// editText opens initial in the user's editor and returns the saved text.
+func editText(initial string) (string, error) {
+	file, err := os.CreateTemp("", "nomnom-prompt-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("create temporary prompt file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(initial); err != nil {
+		file.Close()
+		return "", fmt.Errorf("write temporary prompt file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("write temporary prompt file: %w", err)
+	}
+
+	editor := strings.Fields(editorCommand())
+	command := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("run editor %q: %w", editor[0], err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("read edited prompt: %w", err)
+	}
+	return string(data), nil
+}
+
+func editorCommand() string {
+	for _, variable := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(variable)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	if len(line) > 72 {
+		line = strings.ToValidUTF8(line[:72], "") + "..."
+	}
+	return line
+}
@@ -20,6 +20,7 @@ type args struct {
 	revert      string
 	organize    bool
 	prompt      string
+	taxonomy    bool
 }
 
 var cmdArgs = &args{}
@@ -33,6 +34,7 @@ nomnom analytics -d ~/Documents/files
 nomnom -d ~/Documents/files
 nomnom -d ~/Documents/files -n=false
 nomnom -d ~/Documents/files -p research
+nomnom -d ~/Documents/files -t
 nomnom -r .nomnom/logs/changes_123.json`,
 	Run: func(cmd *cobra.Command, _ []string) {
 		presenter := newCLIPresenter()
@@ -83,9 +85,24 @@ nomnom -r .nomnom/logs/changes_123.json`,
 			outputText = fmt.Sprintf("Output directory would be set up at: %s", run.OutputDir)
 		}
 		presenter.Titlef(outputText)
+		if auditPath := run.Query.Audit.Path(); auditPath != "" {
+			presenter.Infof("Recording provider requests to: %s", auditPath)
+		}
 
 		presenter.Divider()
 
+		if cmdArgs.taxonomy {
+			presenter.Titlef("Proposing a folder taxonomy for the whole directory")
+			if !cmdArgs.organize {
+				presenter.Warnf("Taxonomy folders are only used for output when --organize is enabled")
+			}
+			if err := service.ProposeTaxonomy(run, presenter); err != nil {
+				color.Red("Error proposing taxonomy: %v\n", err)
+				os.Exit(1)
+			}
+			presenter.Divider()
+		}
+
 		presenter.Titlef("Processing files with AI to generate new names")
 
 		if err := service.GeneratePlan(run); err != nil {
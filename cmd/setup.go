@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -50,23 +51,27 @@ nomnom setup -c ~/.config/nomnom/config.json`,
 
 		presenter.Titlef("Core Configuration")
 
-		provider, err := promptSelect("AI provider", []string{"openrouter", "deepseek", "ollama"}, config.AI.Provider)
+		provider, err := promptSelect("AI provider", []string{"openrouter", "deepseek", "ollama", "metadata"}, config.AI.Provider)
 		if err != nil {
 			return err
 		}
 		config.AI.Provider = provider
 
-		defaultModel := modelDefaultForProvider(provider)
-		if config.AI.Model == "" || providerChangedModel(provider, config.AI.Model) {
-			config.AI.Model = defaultModel
-		}
-		model, err := promptText("Model", config.AI.Model, nonEmptyValidator("model"))
-		if err != nil {
-			return err
+		if provider == "metadata" {
+			config.AI.Model = ""
+		} else {
+			defaultModel := modelDefaultForProvider(provider)
+			if config.AI.Model == "" || providerChangedModel(provider, config.AI.Model) {
+				config.AI.Model = defaultModel
+			}
+			model, err := promptText("Model", config.AI.Model, nonEmptyValidator("model"))
+			if err != nil {
+				return err
+			}
+			config.AI.Model = model
 		}
-		config.AI.Model = model
 
-		if provider == "ollama" {
+		if provider == "ollama" || provider == "metadata" {
 			config.AI.APIKey = ""
 		} else {
 			apiKey, err := promptAPIKey(config.AI.APIKey)
@@ -159,7 +164,7 @@ func runAdvancedSetup(config *utils.Config) error {
 	}
 	config.AI.MaxTokens = maxTokens
 
-	temperature, err := promptFloat("Temperature", config.AI.Temperature)
+	temperature, err := promptFloat("Temperature", config.AI.Temperature, 2)
 	if err != nil {
 		return err
 	}
@@ -189,6 +194,44 @@ func runAdvancedSetup(config *utils.Config) error {
 	}
 	config.FileHandling.AutoApprove = autoApprove
 
+	siblingContext, err := promptBool("Share neighboring file names for consistent naming?", config.Naming.SiblingContext)
+	if err != nil {
+		return err
+	}
+	config.Naming.SiblingContext = siblingContext
+
+	language, err := promptOptionalText("Language for generated names (leave blank to let the model decide)", config.Naming.Language)
+	if err != nil {
+		return err
+	}
+	config.Naming.Language = language
+
+	ascii, err := promptBool("Transliterate generated names to ASCII?", config.Naming.ASCII)
+	if err != nil {
+		return err
+	}
+	config.Naming.ASCII = ascii
+
+	redact, err := promptBool("Redact emails, phone numbers, IBANs and keys before sending content to hosted providers?", !config.Redaction.Disabled)
+	if err != nil {
+		return err
+	}
+	config.Redaction.Disabled = !redact
+
+	minConfidence, err := promptFloat("Minimum confidence to rename a file (0 disables)", config.AI.MinConfidence, 1)
+	if err != nil {
+		return err
+	}
+	config.AI.MinConfidence = minConfidence
+
+	if minConfidence > 0 {
+		lowConfidence, err := promptSelect("Low-confidence files", []string{"review", "keep"}, config.AI.LowConfidence)
+		if err != nil {
+			return err
+		}
+		config.AI.LowConfidence = lowConfidence
+	}
+
 	customPrompt, err := promptOptionalText("Default custom prompt (leave blank to use NomNom default)", config.AI.Prompt)
 	if err != nil {
 		return err
@@ -227,11 +270,28 @@ func mergeConfig(base, override utils.Config) utils.Config {
 	if override.AI.Prompt != "" {
 		base.AI.Prompt = override.AI.Prompt
 	}
+	base.AI.HTTP = override.AI.HTTP
+	base.AI.MinConfidence = override.AI.MinConfidence
+	if override.AI.LowConfidence != "" {
+		base.AI.LowConfidence = override.AI.LowConfidence
+	}
+	if len(override.AI.Routes) > 0 {
+		base.AI.Routes = override.AI.Routes
+	}
+	if len(override.AI.Metadata.Templates) > 0 {
+		base.AI.Metadata = override.AI.Metadata
+	}
+	base.Naming.SiblingContext = override.Naming.SiblingContext
+	if override.Naming.Language != "" {
+		base.Naming.Language = override.Naming.Language
+	}
+	base.Naming.ASCII = override.Naming.ASCII
+	base.Redaction = override.Redaction
 	if override.FileHandling.MaxSize != "" {
 		base.FileHandling.MaxSize = override.FileHandling.MaxSize
 	}
 	base.FileHandling.AutoApprove = override.FileHandling.AutoApprove
-	if override.ContentExtraction != (utils.ContentExtractionConfig{}) {
+	if !reflect.DeepEqual(override.ContentExtraction, utils.ContentExtractionConfig{}) {
 		base.ContentExtraction = override.ContentExtraction
 	}
 	if override.Performance.AI.Workers != 0 {
@@ -391,14 +451,14 @@ func promptInt(label string, current int) (int, error) {
 	return strconv.Atoi(value)
 }
 
-func promptFloat(label string, current float64) (float64, error) {
+func promptFloat(label string, current, maxValue float64) (float64, error) {
 	value, err := promptText(label, strconv.FormatFloat(current, 'f', -1, 64), func(input string) error {
 		number, parseErr := strconv.ParseFloat(strings.TrimSpace(input), 64)
 		if parseErr != nil {
 			return errors.New("enter a valid number")
 		}
-		if number < 0 || number > 2 {
-			return errors.New("enter a value between 0 and 2")
+		if number < 0 || number > maxValue {
+			return fmt.Errorf("enter a value between 0 and %g", maxValue)
 		}
 		return nil
 	})
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -21,12 +23,70 @@ type QueryOpts struct {
 	Case        string
 	MaxTokens   int
 	Temperature float64
+	Language    string
+	ASCII       bool
+	Confidence  bool   // ask the model to self-report how sure it is
+	Route       string // ai.routes entry the request belongs to, for analytics
 }
 
+// suggestion is a single naming decision returned by a provider.
+type suggestion struct {
+	Name       string
+	Folder     string
+	Confidence float64
+}
+
+type nameFunc func(content.ScannedFile, string) (suggestion, error)
+
 func HandleAI(config utils.Config, query content.Query) (content.Query, error) {
-	reporter := reporterFor(query)
-	if config.AI == (utils.AIConfig{}) {
-		return content.Query{}, fmt.Errorf("AI configuration is empty")
+	config, provider, err := resolveProvider(config, reporterFor(query))
+	if err != nil {
+		return content.Query{}, err
+	}
+
+	if config.AI.APIKey == "dummy-key" {
+		return query, nil
+	}
+
+	var result content.Query
+	if len(config.AI.Routes) > 0 {
+		result, err = sendQueryWithRoutes(config, provider, query)
+		if err != nil {
+			return content.Query{}, err
+		}
+		checkConfidence(&result, config.AI)
+		return result, nil
+	}
+
+	query, err = redactForProvider(config, provider, query, true)
+	if err != nil {
+		return content.Query{}, err
+	}
+
+	switch provider {
+	case "deepseek":
+		result, err = SendQueryWithDeepSeek(config, query)
+	case "ollama":
+		result, err = SendQueryWithOllama(config, query)
+	case "openrouter":
+		result, err = SendQueryWithOpenRouter(config, query)
+	case "metadata":
+		result, err = SendQueryWithMetadata(config, query)
+	default:
+		return content.Query{}, fmt.Errorf("invalid AI provider: %s", provider)
+	}
+	if err != nil {
+		return content.Query{}, err
+	}
+
+	checkConfidence(&result, config.AI)
+	return result, nil
+}
+
+// resolveProvider validates the configured provider and fills the API key from the environment when needed.
+func resolveProvider(config utils.Config, reporter utils.Reporter) (utils.Config, string, error) {
+	if reflect.DeepEqual(config.AI, utils.AIConfig{}) {
+		return config, "", fmt.Errorf("AI configuration is empty")
 	}
 
 	provider := config.AI.Provider
@@ -34,11 +94,16 @@ func HandleAI(config utils.Config, query content.Query) (content.Query, error) {
 		provider = "deepseek"
 		reporter.Infof("No AI provider set, defaulting to deepseek")
 	}
-	if provider != "deepseek" && provider != "openrouter" && provider != "ollama" {
-		return content.Query{}, fmt.Errorf("invalid AI provider: %s", provider)
+	if provider != "deepseek" && provider != "openrouter" && provider != "ollama" && provider != "metadata" {
+		return config, "", fmt.Errorf("invalid AI provider: %s", provider)
 	}
 
-	if provider != "ollama" && config.AI.APIKey == "" {
+	if !needsAPIKey(provider) {
+		config.AI.Provider = provider
+		return config, provider, nil
+	}
+
+	if config.AI.APIKey == "" {
 		switch provider {
 		case "deepseek":
 			config.AI.APIKey = os.Getenv("DEEPSEEK_API_KEY")
@@ -47,27 +112,51 @@ func HandleAI(config utils.Config, query content.Query) (content.Query, error) {
 		}
 	}
 
-	if provider != "ollama" && config.AI.APIKey == "" {
-		return content.Query{}, fmt.Errorf("no API key found for provider %s", provider)
+	if config.AI.APIKey == "" {
+		return config, "", fmt.Errorf("no API key found for provider %s", provider)
 	}
 
-	if config.AI.APIKey == "dummy-key" {
-		return query, nil
-	}
+	config.AI.Provider = provider
+	return config, provider, nil
+}
+
+// needsAPIKey reports whether provider talks to a hosted API.
+func needsAPIKey(provider string) bool {
+	return provider != "ollama" && provider != "metadata"
+}
 
+// newChatClient builds the chat client for an already resolved provider.
+func newChatClient(provider string, config utils.Config) (chatClient, QueryOpts, error) {
 	switch provider {
-	case "deepseek":
-		return SendQueryWithDeepSeek(config, query)
+	case "deepseek", "openrouter":
+		newClient := newDeepSeekClient
+		if provider == "openrouter" {
+			newClient = newOpenRouterClient
+		}
+		client, opts, err := newClient(config)
+		if err != nil {
+			return nil, QueryOpts{}, err
+		}
+		_, _, timeout, err := aiRuntime(config)
+		if err != nil {
+			return nil, QueryOpts{}, err
+		}
+		client.Timeout = timeout
+		return deepseekChat{client: client}, opts, nil
 	case "ollama":
-		return SendQueryWithOllama(config, query)
-	case "openrouter":
-		return SendQueryWithOpenRouter(config, query)
+		client, opts, err := newOllamaClient(config)
+		if err != nil {
+			return nil, QueryOpts{}, err
+		}
+		return ollamaChat{client: client}, opts, nil
+	case "metadata":
+		return nil, QueryOpts{}, fmt.Errorf("the metadata provider does not support conversations with a model")
 	default:
-		return content.Query{}, fmt.Errorf("invalid AI provider: %s", provider)
+		return nil, QueryOpts{}, fmt.Errorf("invalid AI provider: %s", provider)
 	}
 }
 
-func SendQueryToLLM(client *deepseek.Client, config utils.Config, query content.Query, opts QueryOpts) error {
+func SendQueryToLLM(client *deepseek.Client, config utils.Config, query *content.Query, opts QueryOpts) error {
 	if client == nil {
 		return fmt.Errorf("nil client")
 	}
@@ -81,20 +170,40 @@ func SendQueryToLLM(client *deepseek.Client, config utils.Config, query content.
 	}
 
 	client.Timeout = timeout
-	reporter := reporterFor(query)
+	reporter := reporterFor(*query)
 	reporter.Infof("AI processing configuration - Workers: %d, Timeout: %s, Retries: %d", workers, timeout, retries)
 
-	plan := buildRenamePlan(query.Scan.Files, workers, retries, reporter, func(file content.ScannedFile, retryHint string) (string, error) {
-		if config.AI.Vision.Enabled && hasVisionSource(file) {
-			return requestVisionName(client, query.Prompt, file, retryHint, opts, query.Analytics)
-		}
-		return requestTextName(client, query.Prompt, file, retryHint, opts, query.Analytics)
-	})
-
-	query.Plan = plan
+	planNames(deepseekChat{client: client}, config, query, query.Prompt, workers, retries, opts)
 	return nil
 }
 
+// planNames asks the chat client for a name for every scanned file and keeps
+// the same client available for refining individual suggestions later.
+func planNames(client chatClient, config utils.Config, query *content.Query, prompt string, workers, retries int, opts QueryOpts) {
+	n := newNamer(client, config, *query, prompt, opts, siblingsFor(config))
+	query.Plan = buildRenamePlan(query.Scan.Files, workers, retries, reporterFor(*query), n.name)
+	query.Refine = n.refine
+}
+
+func newNamer(client chatClient, config utils.Config, query content.Query, prompt string, opts QueryOpts, siblings *siblingRegistry) *namer {
+	return &namer{
+		client:   client,
+		query:    query,
+		prompt:   prompt,
+		opts:     opts,
+		vision:   config.AI.Vision.Enabled,
+		siblings: siblings,
+	}
+}
+
+// siblingsFor returns the registry shared by every request of a run, or nil when sibling context is off.
+func siblingsFor(config utils.Config) *siblingRegistry {
+	if !config.Naming.SiblingContext {
+		return nil
+	}
+	return newSiblingRegistry()
+}
+
 func aiRuntime(config utils.Config) (workers int, retries int, timeout time.Duration, err error) {
 	workers = config.Performance.AI.Workers
 	if workers == 0 {
@@ -119,7 +228,7 @@ func aiRuntime(config utils.Config) (workers int, retries int, timeout time.Dura
 	return workers, retries, timeout, nil
 }
 
-func buildRenamePlan(files []content.ScannedFile, workers, retries int, reporter utils.Reporter, nameFunc func(content.ScannedFile, string) (string, error)) []content.RenamePlanEntry {
+func buildRenamePlan(files []content.ScannedFile, workers, retries int, reporter utils.Reporter, nameFunc nameFunc) []content.RenamePlanEntry {
 	results := make([]content.RenamePlanEntry, len(files))
 	sem := make(chan struct{}, workers)
 	var wg sync.WaitGroup
@@ -133,9 +242,12 @@ func buildRenamePlan(files []content.ScannedFile, workers, retries int, reporter
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
+			named := nameWithRetry(file, retries, reporter, nameFunc)
 			results[index] = content.RenamePlanEntry{
 				File:          file,
-				SuggestedName: nameWithRetry(file, retries, reporter, nameFunc),
+				SuggestedName: named.Name,
+				Folder:        named.Folder,
+				Confidence:    named.Confidence,
 			}
 		}()
 	}
@@ -144,14 +256,14 @@ func buildRenamePlan(files []content.ScannedFile, workers, retries int, reporter
 	return results
 }
 
-func nameWithRetry(file content.ScannedFile, retries int, reporter utils.Reporter, nameFunc func(content.ScannedFile, string) (string, error)) string {
+func nameWithRetry(file content.ScannedFile, retries int, reporter utils.Reporter, nameFunc nameFunc) suggestion {
 	retryHint := ""
 	var lastErr error
 
 	for attempt := 0; attempt <= retries; attempt++ {
-		name, err := nameFunc(file, retryHint)
+		named, err := nameFunc(file, retryHint)
 		if err == nil {
-			return name
+			return named
 		}
 
 		lastErr = err
@@ -162,61 +274,149 @@ func nameWithRetry(file content.ScannedFile, retries int, reporter utils.Reporte
 	}
 
 	reporter.Errorf("Failed to process file: %s. Error: %v", file.OriginalName, lastErr)
-	return ""
+	return suggestion{}
 }
 
-func requestTextName(client *deepseek.Client, prompt string, file content.ScannedFile, retryHint string, opts QueryOpts, analytics *utils.AnalyticsStore) (string, error) {
-	request := &deepseek.ChatCompletionRequest{
-		Model: opts.Model,
-		Messages: []deepseek.ChatCompletionMessage{
-			{Role: deepseek.ChatMessageRoleSystem, Content: prompt},
-			{Role: deepseek.ChatMessageRoleUser, Content: promptContext(file, retryHint)},
-		},
+// namer requests names for individual files from a single chat client.
+type namer struct {
+	client   chatClient
+	query    content.Query
+	prompt   string
+	opts     QueryOpts
+	vision   bool
+	siblings *siblingRegistry
+}
+
+func (n *namer) name(file content.ScannedFile, retryHint string) (suggestion, error) {
+	dir := filepath.Dir(file.RelativePath)
+
+	var decided []string
+	if n.siblings != nil {
+		decided = n.siblings.decided(dir)
 	}
 
-	response, err := client.CreateChatCompletion(context.Background(), request)
+	named, err := n.complete(file, n.messages(file, promptContext(file, retryHint, decided)), utils.AuditEntry{Kind: utils.AuditRename, RetryHint: retryHint})
 	if err != nil {
-		return "", fmt.Errorf("error creating chat completion: %w", err)
+		return suggestion{}, err
 	}
-	if response.Choices == nil || len(response.Choices) == 0 {
-		return "", fmt.Errorf("no choices in AI response")
+
+	if n.siblings != nil {
+		if err := n.siblings.reserve(dir, named.Name); err != nil {
+			return suggestion{}, err
+		}
 	}
-	recordAnalyticsUsage(analytics, opts.Provider, response.Model, response.Usage.PromptTokens, response.Usage.CompletionTokens, response.Usage.TotalTokens, false)
-	return normalizeSuggestedName(response.Choices[0].Message.Content, file, opts.Case)
+	return named, nil
 }
 
-func requestVisionName(client *deepseek.Client, prompt string, file content.ScannedFile, retryHint string, opts QueryOpts, analytics *utils.AnalyticsStore) (string, error) {
-	base64Image, err := deepseek.ImageToBase64(visionSourcePath(file))
+// refine re-queries the provider with the original context followed by every
+// previous suggestion and the feedback the user gave on it.
+func (n *namer) refine(entry content.RenamePlanEntry, history []content.Refinement) (content.RenamePlanEntry, error) {
+	dir := filepath.Dir(entry.File.RelativePath)
+
+	var decided []string
+	if n.siblings != nil {
+		decided = n.siblings.decided(dir)
+	}
+
+	messages := n.messages(entry.File, promptContext(entry.File, "", decided))
+	for _, round := range history {
+		previous := round.Suggestion
+		if round.Folder != "" {
+			previous = round.Folder + "/" + previous
+		}
+		messages = append(messages,
+			chatMessage{Role: "assistant", Content: previous},
+			chatMessage{Role: "user", Content: "Feedback on the previous name: " + round.Feedback + "\nPlease return only the revised filename with the original extension."},
+		)
+	}
+
+	named, err := n.complete(entry.File, messages, utils.AuditEntry{Kind: utils.AuditRefine})
 	if err != nil {
-		return "", fmt.Errorf("error opening image file: %w", err)
+		return entry, err
 	}
 
-	request := &deepseek.ChatCompletionRequestWithImage{
-		Model: opts.Model,
-		Messages: []deepseek.ChatCompletionMessageWithImage{
-			{Role: deepseek.ChatMessageRoleSystem, Content: prompt},
-			deepseek.NewImageMessage("user", promptContext(file, retryHint), base64Image),
-		},
+	if n.siblings != nil {
+		n.siblings.release(dir, entry.SuggestedName)
+		if err := n.siblings.reserve(dir, named.Name); err != nil {
+			_ = n.siblings.reserve(dir, entry.SuggestedName)
+			return entry, err
+		}
 	}
 
-	response, err := client.CreateChatCompletionWithImage(context.Background(), request)
+	entry.SuggestedName = named.Name
+	entry.Folder = named.Folder
+	entry.Confidence = named.Confidence
+	return entry, nil
+}
+
+func (n *namer) messages(file content.ScannedFile, context string) []chatMessage {
+	user := chatMessage{Role: "user", Content: context}
+	if n.vision && hasVisionSource(file) {
+		user.ImagePath = visionSourcePath(file)
+	}
+
+	return []chatMessage{
+		{Role: "system", Content: withNamingRules(withTaxonomy(n.prompt, n.query.Taxonomy), n.opts)},
+		user,
+	}
+}
+
+// complete sends messages, validates the reply and records the exchange in the audit log.
+func (n *namer) complete(file content.ScannedFile, messages []chatMessage, audit utils.AuditEntry) (named suggestion, err error) {
+	started := time.Now()
+	var reply chatReply
+	defer func() {
+		audit.File = file.RelativePath
+		audit.Response = reply.Content
+		audit.Model = reply.Model
+		recordAudit(n.query.Audit, audit, n.opts, messages, named, err, started)
+	}()
+
+	reply, err = n.client.Chat(context.Background(), n.opts.Model, messages)
+	if err != nil {
+		return suggestion{}, err
+	}
+
+	model := reply.Model
+	if model == "" {
+		model = n.opts.Model
+	}
+	recordAnalyticsUsage(n.query.Analytics, n.opts, model, reply.PromptTokens, reply.CompletionTokens, reply.TotalTokens, hasImages(messages))
+
+	raw, reported, hasReport := splitConfidence(reply.Content)
+	folder := ""
+	if len(n.query.Taxonomy) > 0 {
+		folder, raw, err = splitFolderChoice(raw, n.query.Taxonomy)
+		if err != nil {
+			return suggestion{}, err
+		}
+	}
+
+	name, err := normalizeSuggestedName(raw, file, n.opts)
 	if err != nil {
-		return "", fmt.Errorf("error creating chat completion: %w", err)
+		return suggestion{}, err
 	}
-	if response.Choices == nil || len(response.Choices) == 0 {
-		return "", fmt.Errorf("no choices in AI response")
+
+	confidence := contextConfidence(file, hasImages(messages))
+	if hasReport {
+		confidence = min(confidence, reported)
 	}
-	recordAnalyticsUsage(analytics, opts.Provider, response.Model, response.Usage.PromptTokens, response.Usage.CompletionTokens, response.Usage.TotalTokens, true)
-	return normalizeSuggestedName(response.Choices[0].Message.Content, file, opts.Case)
+	return suggestion{Name: name, Folder: folder, Confidence: confidence}, nil
 }
 
-func normalizeSuggestedName(raw string, file content.ScannedFile, caseStyle string) (string, error) {
+func normalizeSuggestedName(raw string, file content.ScannedFile, opts QueryOpts) (string, error) {
 	if strings.TrimSpace(raw) == "" {
 		return "", fmt.Errorf("empty response from AI")
 	}
 
 	refinedName := fileutils.RefinedName(raw)
-	newName := utils.ConvertCase(refinedName, "snake", caseStyle)
+	if opts.ASCII {
+		refinedName = utils.ToASCII(refinedName)
+		if !utils.IsASCII(refinedName) {
+			return "", fmt.Errorf("invalid response from AI: The file name must only contain ASCII characters. Transliterate or translate any other characters.")
+		}
+	}
+	newName := utils.ConvertCase(refinedName, "snake", opts.Case)
 	newName = strings.ReplaceAll(newName, "\n", "")
 	newName = strings.ReplaceAll(newName, " ", "")
 	newName = fileutils.CheckAndAddExtension(newName, file.OriginalName)
@@ -228,12 +428,27 @@ func normalizeSuggestedName(raw string, file content.ScannedFile, caseStyle stri
 	return newName, nil
 }
 
-func promptContext(file content.ScannedFile, retryHint string) string {
+// withNamingRules appends the configured output language and character set rules to the system prompt.
+func withNamingRules(prompt string, opts QueryOpts) string {
+	if language := strings.TrimSpace(opts.Language); language != "" {
+		prompt += "\n\nWrite the words of the filename in " + language + ", translating them from the source language if needed."
+	}
+	if opts.ASCII {
+		prompt += "\n\nUse only ASCII letters, digits, underscores, hyphens and periods in the filename. Transliterate accented letters and romanize non-Latin scripts."
+	}
+	if opts.Confidence {
+		prompt += confidenceInstruction
+	}
+	return prompt
+}
+
+func promptContext(file content.ScannedFile, retryHint string, decided []string) string {
+	context := file.Context + siblingContext(file.Siblings, decided)
 	if retryHint == "" {
-		return file.Context
+		return context
 	}
 
-	return "Previous filename suggestion failed validation for this reason: " + retryHint + "\nPlease return only a valid filename with the original extension.\n\n" + file.Context
+	return "Previous filename suggestion failed validation for this reason: " + retryHint + "\nPlease return only a valid filename with the original extension.\n\n" + context
 }
 
 func retryReason(err error) string {
@@ -263,13 +478,14 @@ func visionSourcePath(file content.ScannedFile) string {
 	return file.SourcePath
 }
 
-func recordAnalyticsUsage(analytics *utils.AnalyticsStore, provider, model string, promptTokens, completionTokens, totalTokens int, vision bool) {
+func recordAnalyticsUsage(analytics *utils.AnalyticsStore, opts QueryOpts, model string, promptTokens, completionTokens, totalTokens int, vision bool) {
 	if analytics == nil {
 		return
 	}
 
 	analytics.RecordAIUsage(utils.AnalyticsUsage{
-		Provider:         provider,
+		Route:            opts.Route,
+		Provider:         opts.Provider,
 		Model:            model,
 		PromptTokens:     promptTokens,
 		CompletionTokens: completionTokens,
@@ -181,3 +181,26 @@ func TestHandleAIProviderSelection(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeSuggestedNameASCII(t *testing.T) {
+	file := contentprocessors.ScannedFile{OriginalName: "scan.pdf"}
+
+	name, err := normalizeSuggestedName("jahresübersicht_größe.pdf", file, QueryOpts{Case: "snake", ASCII: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "jahresuebersicht_groesse.pdf", name)
+
+	_, err = normalizeSuggestedName("会議メモ.pdf", file, QueryOpts{Case: "snake", ASCII: true})
+	assert.Error(t, err)
+	assert.NotEmpty(t, retryReason(err))
+
+	name, err = normalizeSuggestedName("会議メモ.pdf", file, QueryOpts{Case: "snake"})
+	assert.NoError(t, err)
+	assert.Equal(t, "会議メモ.pdf", name)
+}
+
+func TestWithNamingRules(t *testing.T) {
+	prompt := withNamingRules("base", QueryOpts{Language: "English", ASCII: true})
+	assert.Contains(t, prompt, "in English")
+	assert.Contains(t, prompt, "ASCII")
+	assert.Equal(t, "base", withNamingRules("base", QueryOpts{}))
+}
@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	utils "nomnom/internal/utils"
+)
+
+// recordAudit completes entry from the request and its outcome and appends it to the audit log.
+func recordAudit(audit *utils.AuditLog, entry utils.AuditEntry, opts QueryOpts, messages []chatMessage, named suggestion, err error, started time.Time) {
+	if audit == nil {
+		return
+	}
+
+	entry.Route = opts.Route
+	entry.Provider = opts.Provider
+	if entry.Model == "" {
+		entry.Model = opts.Model
+	}
+	if len(messages) > 0 && messages[0].Role == "system" {
+		entry.Prompt = messages[0].Content
+		messages = messages[1:]
+	}
+	entry.Context = conversationText(messages)
+	for _, message := range messages {
+		if message.ImagePath != "" {
+			entry.Image = message.ImagePath
+		}
+	}
+	entry.Name = named.Name
+	entry.Folder = named.Folder
+	entry.Confidence = named.Confidence
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	entry.LatencyMS = time.Since(started).Milliseconds()
+
+	_ = audit.Record(entry)
+}
+
+// conversationText returns a single user message as is and labels each turn of longer conversations.
+func conversationText(messages []chatMessage) string {
+	if len(messages) == 1 {
+		return messages[0].Content
+	}
+
+	turns := make([]string, 0, len(messages))
+	for _, message := range messages {
+		turns = append(turns, message.Role+": "+message.Content)
+	}
+	return strings.Join(turns, "\n\n")
+}
+
+// metadataText lists metadata values in key order, the context of the metadata provider.
+func metadataText(values map[string]string) string {
+	lines := make([]string, 0, len(values))
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, values[key]))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,153 @@
+package ai
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	deepseek "github.com/cohesion-org/deepseek-go"
+	api "github.com/ollama/ollama/api"
+)
+
+// chatMessage is a provider-neutral conversation turn. ImagePath attaches an
+// image to the turn for vision-capable models.
+type chatMessage struct {
+	Role      string
+	Content   string
+	ImagePath string
+}
+
+// chatReply is the provider-neutral result of a chat completion.
+type chatReply struct {
+	Content          string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// chatClient sends a conversation to a model and returns its reply.
+type chatClient interface {
+	Chat(ctx context.Context, model string, messages []chatMessage) (chatReply, error)
+}
+
+// deepseekChat adapts the OpenAI-compatible deepseek-go client used for DeepSeek and OpenRouter.
+type deepseekChat struct {
+	client *deepseek.Client
+}
+
+func (c deepseekChat) Chat(ctx context.Context, model string, messages []chatMessage) (chatReply, error) {
+	if c.client == nil {
+		return chatReply{}, fmt.Errorf("nil client")
+	}
+
+	var (
+		response *deepseek.ChatCompletionResponse
+		err      error
+	)
+	if hasImages(messages) {
+		request := &deepseek.ChatCompletionRequestWithImage{Model: model}
+		for _, message := range messages {
+			if message.ImagePath == "" {
+				request.Messages = append(request.Messages, deepseek.ChatCompletionMessageWithImage{Role: message.Role, Content: message.Content})
+				continue
+			}
+			base64Image, imageErr := deepseek.ImageToBase64(message.ImagePath)
+			if imageErr != nil {
+				return chatReply{}, fmt.Errorf("error opening image file: %w", imageErr)
+			}
+			request.Messages = append(request.Messages, deepseek.NewImageMessage(message.Role, message.Content, base64Image))
+		}
+		response, err = c.client.CreateChatCompletionWithImage(ctx, request)
+	} else {
+		request := &deepseek.ChatCompletionRequest{Model: model}
+		for _, message := range messages {
+			request.Messages = append(request.Messages, deepseek.ChatCompletionMessage{Role: message.Role, Content: message.Content})
+		}
+		response, err = c.client.CreateChatCompletion(ctx, request)
+	}
+	if err != nil {
+		return chatReply{}, fmt.Errorf("error creating chat completion: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return chatReply{}, fmt.Errorf("no choices in AI response")
+	}
+
+	return chatReply{
+		Content:          response.Choices[0].Message.Content,
+		Model:            response.Model,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		TotalTokens:      response.Usage.TotalTokens,
+	}, nil
+}
+
+// ollamaChat adapts the native Ollama API client.
+type ollamaChat struct {
+	client *api.Client
+}
+
+func (c ollamaChat) Chat(ctx context.Context, model string, messages []chatMessage) (chatReply, error) {
+	if c.client == nil {
+		return chatReply{}, fmt.Errorf("nil client")
+	}
+
+	ollamaMessages := make([]api.Message, 0, len(messages))
+	for _, message := range messages {
+		converted := api.Message{Role: message.Role, Content: message.Content}
+		if message.ImagePath != "" {
+			image, err := ollamaImage(message.ImagePath)
+			if err != nil {
+				return chatReply{}, err
+			}
+			converted.Images = []api.ImageData{image}
+		}
+		ollamaMessages = append(ollamaMessages, converted)
+	}
+
+	var lastResponse api.ChatResponse
+	stream := false
+	err := c.client.Chat(ctx, &api.ChatRequest{
+		Model:    model,
+		Messages: ollamaMessages,
+		Stream:   &stream,
+	}, func(response api.ChatResponse) error {
+		lastResponse = response
+		return nil
+	})
+	if err != nil {
+		return chatReply{}, fmt.Errorf("error creating chat completion: %w", err)
+	}
+
+	return chatReply{
+		Content:          removeThink(lastResponse.Message.Content),
+		Model:            lastResponse.Model,
+		PromptTokens:     lastResponse.PromptEvalCount,
+		CompletionTokens: lastResponse.EvalCount,
+		TotalTokens:      lastResponse.PromptEvalCount + lastResponse.EvalCount,
+	}, nil
+}
+
+func ollamaImage(path string) (api.ImageData, error) {
+	imageData, err := deepseek.ImageToBase64(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert image to base64: %w", err)
+	}
+
+	base64Str := strings.Split(imageData, ",")[1]
+	bytes, err := base64.StdEncoding.DecodeString(base64Str)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image data: %w", err)
+	}
+	return bytes, nil
+}
+
+func hasImages(messages []chatMessage) bool {
+	for _, message := range messages {
+		if message.ImagePath != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"strconv"
+	"strings"
+
+	content "nomnom/internal/content"
+	utils "nomnom/internal/utils"
+)
+
+const confidenceInstruction = "\n\nAfter the filename, add a second line in the form `confidence: <number between 0 and 1>` saying how sure you are that the name describes the file. Use a low number when the content gives you little to go on."
+
+// Context quality scores used when the model does not report its own confidence.
+const (
+	sparseConfidence       = 0.3
+	sparseVisionConfidence = 0.6
+)
+
+// splitConfidence removes a self-reported "confidence: 0.8" line from a reply.
+func splitConfidence(raw string) (string, float64, bool) {
+	var (
+		kept     []string
+		score    float64
+		reported bool
+	)
+	for _, line := range strings.Split(raw, "\n") {
+		label, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found || !strings.EqualFold(strings.Trim(label, "*` "), "confidence") {
+			kept = append(kept, line)
+			continue
+		}
+
+		parsed, err := strconv.ParseFloat(strings.Trim(value, "*` "), 64)
+		if err != nil {
+			continue
+		}
+		score = min(max(parsed, 0), 1)
+		reported = true
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n")), score, reported
+}
+
+// contextConfidence scores how much a file's extracted context can support a name.
+func contextConfidence(file content.ScannedFile, vision bool) float64 {
+	if !file.Sparse {
+		return 1
+	}
+	if vision {
+		return sparseVisionConfidence
+	}
+	return sparseConfidence
+}
+
+// applyMinConfidence marks entries scoring below ai.min_confidence so they are not renamed.
+func applyMinConfidence(plan []content.RenamePlanEntry, config utils.AIConfig) {
+	if config.MinConfidence <= 0 {
+		return
+	}
+
+	status := content.PlanNeedsReview
+	if config.LowConfidence == "keep" {
+		status = content.PlanKeptOriginal
+	}
+	for index := range plan {
+		if plan[index].SuggestedName != "" && plan[index].Confidence < config.MinConfidence {
+			plan[index].Status = status
+		}
+	}
+}
+
+// checkConfidence applies ai.min_confidence to the plan and to every
+// suggestion refined later, so a refined name scoring too low is kept or sent
+// to review just like it would have been on the first pass.
+func checkConfidence(query *content.Query, config utils.AIConfig) {
+	applyMinConfidence(query.Plan, config)
+	refine := query.Refine
+	if refine == nil || config.MinConfidence <= 0 {
+		return
+	}
+	query.Refine = func(entry content.RenamePlanEntry, history []content.Refinement) (content.RenamePlanEntry, error) {
+		refined, err := refine(entry, history)
+		if err != nil {
+			return refined, err
+		}
+		checked := []content.RenamePlanEntry{refined}
+		applyMinConfidence(checked, config)
+		return checked[0], nil
+	}
+}
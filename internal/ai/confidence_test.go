@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	content "nomnom/internal/content"
+	utils "nomnom/internal/utils"
+)
+
+func TestSplitConfidence(t *testing.T) {
+	name, score, ok := splitConfidence("acme_invoice.pdf\nConfidence: 0.42")
+	if !ok || name != "acme_invoice.pdf" || score != 0.42 {
+		t.Fatalf("splitConfidence() = (%q, %v, %v)", name, score, ok)
+	}
+
+	name, _, ok = splitConfidence("acme_invoice.pdf")
+	if ok || name != "acme_invoice.pdf" {
+		t.Fatalf("splitConfidence() without score = (%q, %v)", name, ok)
+	}
+}
+
+func TestNamerScoresSparseContext(t *testing.T) {
+	client := &fakeChat{replies: []string{"quarterly_report.pdf\nconfidence: 0.9"}}
+	file := content.ScannedFile{OriginalName: "scan.pdf", Context: "Document extraction fallback.", Sparse: true}
+
+	n := &namer{client: client, prompt: "prompt", opts: QueryOpts{Model: "test", Case: "snake", Confidence: true}}
+	named, err := n.name(file, "")
+	if err != nil {
+		t.Fatalf("name() error = %v", err)
+	}
+	if named.Name != "quarterly_report.pdf" || named.Confidence != sparseConfidence {
+		t.Fatalf("name() = %#v", named)
+	}
+	if system := client.requests[0][0].Content; !strings.Contains(system, "confidence:") {
+		t.Fatalf("system prompt %q does not ask for a confidence", system)
+	}
+}
+
+func TestApplyMinConfidence(t *testing.T) {
+	plan := []content.RenamePlanEntry{
+		{SuggestedName: "sure.pdf", Confidence: 0.9},
+		{SuggestedName: "guess.pdf", Confidence: 0.3},
+		{Confidence: 0},
+	}
+
+	applyMinConfidence(plan, utils.AIConfig{MinConfidence: 0.5, LowConfidence: "keep"})
+
+	want := []content.PlanStatus{content.PlanRename, content.PlanKeptOriginal, content.PlanRename}
+	for index, status := range want {
+		if plan[index].Status != status {
+			t.Fatalf("plan[%d].Status = %q, want %q", index, plan[index].Status, status)
+		}
+	}
+}
+
+func TestCheckConfidenceAppliesToRefinedSuggestions(t *testing.T) {
+	query := content.Query{
+		Plan: []content.RenamePlanEntry{{SuggestedName: "invoice.pdf", Confidence: 0.9}},
+		Refine: func(entry content.RenamePlanEntry, _ []content.Refinement) (content.RenamePlanEntry, error) {
+			entry.SuggestedName = "acme_invoice.pdf"
+			entry.Confidence = 0.2
+			return entry, nil
+		},
+	}
+
+	checkConfidence(&query, utils.AIConfig{MinConfidence: 0.5})
+	if query.Plan[0].Status != content.PlanRename {
+		t.Fatalf("plan status = %q, want rename", query.Plan[0].Status)
+	}
+	refined, err := query.Refine(query.Plan[0], nil)
+	if err != nil {
+		t.Fatalf("Refine() error = %v", err)
+	}
+	if refined.SuggestedName != "acme_invoice.pdf" || refined.Status != content.PlanNeedsReview {
+		t.Fatalf("refined = %#v, want the new name marked for review", refined)
+	}
+}
@@ -10,28 +10,48 @@ import (
 )
 
 func SendQueryWithDeepSeek(config configutils.Config, query content.Query) (content.Query, error) {
+	client, opts, err := newDeepSeekClient(config)
+	if err != nil {
+		return content.Query{}, err
+	}
+
+	reporterFor(query).Infof("You're using DeepSeek with model: %s", opts.Model)
+	if err := SendQueryToLLM(client, config, &query, opts); err != nil {
+		return content.Query{}, err
+	}
+
+	return query, nil
+}
+
+func newDeepSeekClient(config configutils.Config) (*deepseek.Client, QueryOpts, error) {
 	if config.AI.APIKey == "" {
-		return content.Query{}, fmt.Errorf("no API key provided for DeepSeek")
+		return nil, QueryOpts{}, fmt.Errorf("no API key provided for DeepSeek")
 	}
 
-	client := deepseek.NewClient(config.AI.APIKey)
+	httpClient, err := newHTTPClient(config.AI.HTTP)
+	if err != nil {
+		return nil, QueryOpts{}, err
+	}
+
+	client := deepseek.NewClient(config.AI.APIKey, providerBaseURL(config, "https://api.deepseek.com/"))
+	if client == nil {
+		return nil, QueryOpts{}, fmt.Errorf("invalid DeepSeek base URL: %s", providerBaseURL(config, "https://api.deepseek.com/"))
+	}
+	client.HTTPClient = httpClient
+
 	model := config.AI.Model
 	if model == "" {
 		model = deepseek.DeepSeekChat
 	}
 
-	opts := QueryOpts{
+	return client, QueryOpts{
 		Provider:    "deepseek",
 		Model:       model,
 		Case:        config.Case,
 		MaxTokens:   config.AI.MaxTokens,
 		Temperature: config.AI.Temperature,
-	}
-
-	reporterFor(query).Infof("You're using DeepSeek with model: %s", model)
-	if err := SendQueryToLLM(client, config, query, opts); err != nil {
-		return content.Query{}, err
-	}
-
-	return query, nil
+		Language:    config.Naming.Language,
+		ASCII:       config.Naming.ASCII,
+		Confidence:  config.AI.MinConfidence > 0,
+	}, nil
 }
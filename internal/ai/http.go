@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+
+	utils "nomnom/internal/utils"
+)
+
+// newHTTPClient builds the HTTP client shared by every provider client from
+// the ai.http settings: proxy, extra trusted CAs, TLS verification and headers.
+func newHTTPClient(config utils.HTTPConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.Proxy != "" {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ai.http.proxy %q: %w", config.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if config.CAFile != "" || config.InsecureSkipVerify {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: config.InsecureSkipVerify,
+		}
+
+		if config.CAFile != "" {
+			pool, err := x509.SystemCertPool()
+			if err != nil || pool == nil {
+				pool = x509.NewCertPool()
+			}
+
+			pem, err := os.ReadFile(config.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ai.http.ca_file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("ai.http.ca_file %s does not contain any PEM certificates", config.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if len(config.Headers) > 0 {
+		roundTripper = headerTransport{base: transport, headers: maps.Clone(config.Headers)}
+	}
+
+	return &http.Client{Transport: roundTripper}, nil
+}
+
+// headerTransport adds configured headers, such as OpenRouter's HTTP-Referer and X-Title, to every request.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// providerBaseURL returns the configured base URL override or the provider default.
+func providerBaseURL(config utils.Config, fallback string) string {
+	if config.AI.HTTP.BaseURL != "" {
+		return config.AI.HTTP.BaseURL
+	}
+	return fallback
+}
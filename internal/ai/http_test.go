@@ -0,0 +1,92 @@
+package ai
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	utils "nomnom/internal/utils"
+)
+
+func TestNewHTTPClientAddsHeaders(t *testing.T) {
+	var referer, title string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		referer = r.Header.Get("HTTP-Referer")
+		title = r.Header.Get("X-Title")
+	}))
+	defer server.Close()
+
+	client, err := newHTTPClient(utils.HTTPConfig{Headers: map[string]string{
+		"HTTP-Referer": "https://example.com",
+		"X-Title":      "NomNom",
+	}})
+	if err != nil {
+		t.Fatalf("newHTTPClient() error = %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if referer != "https://example.com" || title != "NomNom" {
+		t.Fatalf("headers = (%q, %q), want configured values", referer, title)
+	}
+}
+
+func TestNewHTTPClientTrustsCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	untrusted, err := newHTTPClient(utils.HTTPConfig{})
+	if err != nil {
+		t.Fatalf("newHTTPClient() error = %v", err)
+	}
+	if _, err := untrusted.Get(server.URL); err == nil {
+		t.Fatal("Get() without CA error = nil, want certificate error")
+	}
+
+	trusted, err := newHTTPClient(utils.HTTPConfig{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("newHTTPClient() error = %v", err)
+	}
+	resp, err := trusted.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() with CA error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewHTTPClientRejectsInvalidSettings(t *testing.T) {
+	if _, err := newHTTPClient(utils.HTTPConfig{Proxy: "://bad"}); err == nil {
+		t.Fatal("newHTTPClient() with bad proxy error = nil")
+	}
+	if _, err := newHTTPClient(utils.HTTPConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Fatal("newHTTPClient() with missing CA file error = nil")
+	}
+}
+
+func TestNewDeepSeekClientUsesBaseURLOverride(t *testing.T) {
+	config := utils.Config{AI: utils.AIConfig{APIKey: "key", HTTP: utils.HTTPConfig{BaseURL: "https://proxy.internal/v1/"}}}
+
+	client, _, err := newDeepSeekClient(config)
+	if err != nil {
+		t.Fatalf("newDeepSeekClient() error = %v", err)
+	}
+	if client.BaseURL != "https://proxy.internal/v1/" {
+		t.Fatalf("BaseURL = %q, want override", client.BaseURL)
+	}
+	if client.HTTPClient == nil {
+		t.Fatal("HTTPClient = nil, want shared client")
+	}
+}
@@ -0,0 +1,162 @@
+package ai
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	content "nomnom/internal/content"
+	utils "nomnom/internal/utils"
+)
+
+// defaultMetadataTemplates name files per category when ai.metadata.templates does not override them.
+var defaultMetadataTemplates = map[string]string{
+	"Audios":    "{artist}_{title}",
+	"Documents": "{author}_{year}_{title}",
+	"Images":    "{date}_{original}",
+	"Videos":    "{date}_{title|original}",
+	"default":   "{title}",
+}
+
+var templatePlaceholder = regexp.MustCompile(`\{([a-z0-9_|]+)\}`)
+
+// SendQueryWithMetadata builds names from extracted metadata using per-category
+// templates. Nothing leaves the machine, so no API key or model is needed.
+func SendQueryWithMetadata(config utils.Config, query content.Query) (content.Query, error) {
+	if len(query.Scan.Files) == 0 {
+		return content.Query{}, fmt.Errorf("no files to process")
+	}
+
+	workers := config.Performance.AI.Workers
+	if workers == 0 {
+		workers = 1
+	}
+
+	reporterFor(query).Infof("You're using offline metadata naming, nothing is sent to a model")
+	query.Plan = buildRenamePlan(query.Scan.Files, workers, 0, reporterFor(query), metadataNameFunc(config, query, metadataOpts(config)))
+	return query, nil
+}
+
+func metadataOpts(config utils.Config) QueryOpts {
+	return QueryOpts{
+		Provider: "metadata",
+		Model:    "templates",
+		Case:     config.Case,
+		Language: config.Naming.Language,
+		ASCII:    config.Naming.ASCII,
+	}
+}
+
+// metadataNameFunc renders the category template of each file from its metadata.
+func metadataNameFunc(config utils.Config, query content.Query, opts QueryOpts) nameFunc {
+	templates := metadataTemplates(config)
+	return func(file content.ScannedFile, _ string) (named suggestion, err error) {
+		template := templateForFile(templates, file)
+		values := metadataValues(file)
+		started := time.Now()
+		var raw string
+		defer func() {
+			audit := utils.AuditEntry{Kind: utils.AuditRename, File: file.RelativePath, Response: raw}
+			recordAudit(query.Audit, audit, opts, []chatMessage{
+				{Role: "system", Content: template},
+				{Role: "user", Content: metadataText(values)},
+			}, named, err, started)
+		}()
+
+		raw, err = renderMetadataTemplate(template, values)
+		if err != nil {
+			return suggestion{}, err
+		}
+
+		recordAnalyticsUsage(query.Analytics, opts, opts.Model, 0, 0, 0, false)
+		name, err := normalizeSuggestedName(raw, file, opts)
+		if err != nil {
+			return suggestion{}, err
+		}
+		return suggestion{Name: name, Confidence: 1}, nil
+	}
+}
+
+func metadataTemplates(config utils.Config) map[string]string {
+	templates := make(map[string]string, len(defaultMetadataTemplates))
+	for category, template := range defaultMetadataTemplates {
+		templates[category] = template
+	}
+	for category, template := range config.AI.Metadata.Templates {
+		if strings.TrimSpace(template) != "" {
+			templates[category] = template
+		}
+	}
+	return templates
+}
+
+func templateForFile(templates map[string]string, file content.ScannedFile) string {
+	if template, ok := templates[file.Category]; ok {
+		return template
+	}
+	return templates["default"]
+}
+
+// metadataValues merges extracted metadata with fields every file has.
+func metadataValues(file content.ScannedFile) map[string]string {
+	values := make(map[string]string, len(file.Metadata)+6)
+	for key, value := range file.Metadata {
+		values[key] = value
+	}
+
+	values["original"] = strings.TrimSuffix(file.OriginalName, filepath.Ext(file.OriginalName))
+	values["category"] = file.Category
+	if !file.ModifiedAt.IsZero() {
+		values["modified"] = file.ModifiedAt.Format("2006-01-02")
+		values["modified_year"] = file.ModifiedAt.Format("2006")
+	}
+
+	for _, key := range []string{"date_taken", "created", "modified"} {
+		if values[key] != "" {
+			values["date"] = values[key]
+			break
+		}
+	}
+	return values
+}
+
+// renderMetadataTemplate fills {key} and {key|fallback} placeholders. Missing
+// values are dropped; the template fails when nothing but the original name resolved.
+func renderMetadataTemplate(template string, values map[string]string) (string, error) {
+	resolved := false
+	rendered := templatePlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		for _, key := range strings.Split(strings.Trim(match, "{}"), "|") {
+			value := slugify(values[key])
+			if value == "" {
+				continue
+			}
+			if key != "original" {
+				resolved = true
+			}
+			return value
+		}
+		return ""
+	})
+
+	rendered = collapseSeparators(rendered)
+	if !resolved || rendered == "" {
+		return "", fmt.Errorf("not enough metadata to fill template %q", template)
+	}
+	return rendered, nil
+}
+
+// slugify lowercases value and joins its words with underscores, keeping hyphens.
+func slugify(value string) string {
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	return strings.Join(words, "_")
+}
+
+func collapseSeparators(value string) string {
+	parts := strings.FieldsFunc(value, func(r rune) bool { return r == '_' })
+	return strings.Trim(strings.Join(parts, "_"), "-_")
+}
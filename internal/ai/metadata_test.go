@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	content "nomnom/internal/content"
+	utils "nomnom/internal/utils"
+)
+
+func TestRenderMetadataTemplate(t *testing.T) {
+	values := map[string]string{
+		"artist":   "Daft Punk",
+		"title":    "One More Time!",
+		"original": "track01",
+	}
+
+	name, err := renderMetadataTemplate("{artist}_{title}", values)
+	assert.NoError(t, err)
+	assert.Equal(t, "daft_punk_one_more_time", name)
+
+	name, err = renderMetadataTemplate("{album}_{title|original}", values)
+	assert.NoError(t, err)
+	assert.Equal(t, "one_more_time", name)
+
+	_, err = renderMetadataTemplate("{album}_{original}", values)
+	assert.Error(t, err)
+}
+
+func TestSendQueryWithMetadata(t *testing.T) {
+	query := content.Query{
+		Scan: content.ScanResult{Files: []content.ScannedFile{
+			{
+				SourcePath:   "/tmp/a.mp3",
+				OriginalName: "a.mp3",
+				Extension:    ".mp3",
+				Category:     "Audios",
+				Metadata:     map[string]string{"artist": "Nina Simone", "title": "Feeling Good"},
+			},
+			{
+				SourcePath:   "/tmp/IMG_0001.jpg",
+				OriginalName: "IMG_0001.jpg",
+				Extension:    ".jpg",
+				Category:     "Images",
+				ModifiedAt:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			},
+			{
+				SourcePath:   "/tmp/notes.txt",
+				OriginalName: "notes.txt",
+				Extension:    ".txt",
+				Category:     "Documents",
+			},
+		}},
+	}
+
+	config := utils.Config{
+		Case: "snake",
+		AI: utils.AIConfig{
+			Provider: "metadata",
+			Metadata: utils.MetadataConfig{Templates: map[string]string{"Images": "{date}_photo"}},
+		},
+	}
+
+	result, err := HandleAI(config, query)
+	assert.NoError(t, err)
+
+	names := map[string]string{}
+	for _, entry := range result.Plan {
+		names[entry.File.OriginalName] = entry.SuggestedName
+	}
+	assert.Equal(t, "nina_simone_feeling_good.mp3", names["a.mp3"])
+	assert.Equal(t, "2024-05-01_photo.jpg", names["IMG_0001.jpg"])
+	assert.Empty(t, names["notes.txt"])
+}
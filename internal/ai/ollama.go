@@ -1,25 +1,23 @@
 package ai
 
 import (
-	"context"
-	"encoding/base64"
 	"fmt"
+	"net/url"
 	"strings"
 
 	content "nomnom/internal/content"
 	configutils "nomnom/internal/utils"
 
-	"github.com/cohesion-org/deepseek-go"
 	api "github.com/ollama/ollama/api"
+	"github.com/ollama/ollama/envconfig"
 )
 
+const ollamaDefaultPrompt = "You are a desktop organizer that creates nice names for the files with their context. Please follow snake case naming convention. Only respond with the new name and the file extension. Do not change the file extension."
+
 func SendQueryWithOllama(config configutils.Config, query content.Query) (content.Query, error) {
-	client, err := api.ClientFromEnvironment()
+	client, opts, err := newOllamaClient(config)
 	if err != nil {
-		return content.Query{}, fmt.Errorf("failed to create client: %w", err)
-	}
-	if config.AI.Model == "" {
-		return content.Query{}, fmt.Errorf("no model provided")
+		return content.Query{}, err
 	}
 	if len(query.Scan.Files) == 0 {
 		return content.Query{}, fmt.Errorf("no files to process")
@@ -37,13 +35,53 @@ func SendQueryWithOllama(config configutils.Config, query content.Query) (conten
 	reporterFor(query).Infof("You're using Ollama with model: %s", config.AI.Model)
 	reporterFor(query).Infof("AI processing configuration - Workers: %d, Retries: %d", workers, retries)
 
-	query.Plan = buildRenamePlan(query.Scan.Files, workers, retries, reporterFor(query), func(file content.ScannedFile, retryHint string) (string, error) {
-		return requestOllamaName(client, config, query, file, retryHint)
-	})
-
+	planNames(ollamaChat{client: client}, config, &query, ollamaPrompt(config, query), workers, retries, opts)
 	return query, nil
 }
 
+func newOllamaClient(config configutils.Config) (*api.Client, QueryOpts, error) {
+	httpClient, err := newHTTPClient(config.AI.HTTP)
+	if err != nil {
+		return nil, QueryOpts{}, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	base := envconfig.Host()
+	if config.AI.HTTP.BaseURL != "" {
+		base, err = url.Parse(config.AI.HTTP.BaseURL)
+		if err != nil {
+			return nil, QueryOpts{}, fmt.Errorf("invalid Ollama base URL %q: %w", config.AI.HTTP.BaseURL, err)
+		}
+	}
+
+	client := api.NewClient(base, httpClient)
+	if config.AI.Model == "" {
+		return nil, QueryOpts{}, fmt.Errorf("no model provided")
+	}
+
+	return client, QueryOpts{
+		Provider:    "ollama",
+		Model:       config.AI.Model,
+		Case:        config.Case,
+		MaxTokens:   config.AI.MaxTokens,
+		Temperature: config.AI.Temperature,
+		Language:    config.Naming.Language,
+		ASCII:       config.Naming.ASCII,
+		Confidence:  config.AI.MinConfidence > 0,
+	}, nil
+}
+
+// ollamaPrompt keeps Ollama's historical precedence where the config prompt wins over the query prompt.
+func ollamaPrompt(config configutils.Config, query content.Query) string {
+	prompt := config.AI.Prompt
+	if prompt == "" {
+		prompt = query.Prompt
+	}
+	if prompt == "" {
+		prompt = ollamaDefaultPrompt
+	}
+	return prompt
+}
+
 func removeThink(s string) string {
 	startTag := "<think>"
 	endTag := "</think>"
@@ -69,76 +107,3 @@ func removeThink(s string) string {
 	result = strings.ReplaceAll(result, "\n", "")
 	return result
 }
-
-func requestOllamaName(client *api.Client, config configutils.Config, query content.Query, file content.ScannedFile, retryHint string) (string, error) {
-	prompt := config.AI.Prompt
-	if prompt == "" {
-		prompt = query.Prompt
-	}
-	if prompt == "" {
-		prompt = "You are a desktop organizer that creates nice names for the files with their context. Please follow snake case naming convention. Only respond with the new name and the file extension. Do not change the file extension."
-	}
-
-	messages, err := createOllamaMessages(file, config.AI.Vision.Enabled && hasVisionSource(file), prompt, promptContext(file, retryHint))
-	if err != nil {
-		return "", err
-	}
-
-	var newName string
-	var lastResponse api.ChatResponse
-	stream := false
-	err = client.Chat(context.Background(), &api.ChatRequest{
-		Model:    config.AI.Model,
-		Messages: messages,
-		Stream:   &stream,
-	}, func(response api.ChatResponse) error {
-		lastResponse = response
-		newName = removeThink(response.Message.Content)
-		return nil
-	})
-	if err != nil {
-		return "", fmt.Errorf("error creating chat completion: %w", err)
-	}
-
-	modelName := lastResponse.Model
-	if modelName == "" {
-		modelName = config.AI.Model
-	}
-
-	recordAnalyticsUsage(
-		query.Analytics,
-		"ollama",
-		modelName,
-		lastResponse.PromptEvalCount,
-		lastResponse.EvalCount,
-		lastResponse.PromptEvalCount+lastResponse.EvalCount,
-		config.AI.Vision.Enabled && hasVisionSource(file),
-	)
-
-	return normalizeSuggestedName(newName, file, config.Case)
-}
-
-func createOllamaMessages(file content.ScannedFile, vision bool, prompt string, context string) ([]api.Message, error) {
-	if !vision {
-		return []api.Message{
-			{Role: "system", Content: prompt},
-			{Role: "user", Content: context},
-		}, nil
-	}
-
-	imageData, err := deepseek.ImageToBase64(visionSourcePath(file))
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert image to base64: %w", err)
-	}
-
-	base64Str := strings.Split(imageData, ",")[1]
-	bytes, err := base64.StdEncoding.DecodeString(base64Str)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode image data: %w", err)
-	}
-
-	return []api.Message{
-		{Role: "system", Content: prompt},
-		{Role: "user", Images: []api.ImageData{bytes}, Content: context},
-	}, nil
-}
@@ -10,25 +10,46 @@ import (
 )
 
 func SendQueryWithOpenRouter(config configutils.Config, query content.Query) (content.Query, error) {
+	client, opts, err := newOpenRouterClient(config)
+	if err != nil {
+		return content.Query{}, err
+	}
+
+	reporterFor(query).Infof("You're using OpenRouter with model: %s", opts.Model)
+	if err := SendQueryToLLM(client, config, &query, opts); err != nil {
+		return content.Query{}, err
+	}
+	return query, nil
+}
+
+func newOpenRouterClient(config configutils.Config) (*deepseek.Client, QueryOpts, error) {
 	if config.AI.APIKey == "" {
-		return content.Query{}, fmt.Errorf("no API key provided for OpenRouter")
+		return nil, QueryOpts{}, fmt.Errorf("no API key provided for OpenRouter")
 	}
 	if config.AI.Model == "" {
-		return content.Query{}, fmt.Errorf("no model provided for OpenRouter")
+		return nil, QueryOpts{}, fmt.Errorf("no model provided for OpenRouter")
 	}
 
-	client := deepseek.NewClient(config.AI.APIKey, "https://openrouter.ai/api/v1/")
-	opts := QueryOpts{
+	httpClient, err := newHTTPClient(config.AI.HTTP)
+	if err != nil {
+		return nil, QueryOpts{}, err
+	}
+
+	baseURL := providerBaseURL(config, "https://openrouter.ai/api/v1/")
+	client := deepseek.NewClient(config.AI.APIKey, baseURL)
+	if client == nil {
+		return nil, QueryOpts{}, fmt.Errorf("invalid OpenRouter base URL: %s", baseURL)
+	}
+	client.HTTPClient = httpClient
+
+	return client, QueryOpts{
 		Provider:    "openrouter",
 		Model:       config.AI.Model,
 		Case:        config.Case,
 		MaxTokens:   config.AI.MaxTokens,
 		Temperature: config.AI.Temperature,
-	}
-
-	reporterFor(query).Infof("You're using OpenRouter with model: %s", config.AI.Model)
-	if err := SendQueryToLLM(client, config, query, opts); err != nil {
-		return content.Query{}, err
-	}
-	return query, nil
+		Language:    config.Naming.Language,
+		ASCII:       config.Naming.ASCII,
+		Confidence:  config.AI.MinConfidence > 0,
+	}, nil
 }
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"net"
+	"net/url"
+
+	content "nomnom/internal/content"
+	utils "nomnom/internal/utils"
+
+	"github.com/ollama/ollama/envconfig"
+)
+
+// redactForProvider scrubs file context before it is sent to a provider that
+// runs off this machine. With report set, every redacted file is listed.
+func redactForProvider(config utils.Config, provider string, query content.Query, report bool) (content.Query, error) {
+	if config.Redaction.Disabled || isLocalProvider(config, provider) {
+		return query, nil
+	}
+
+	redactor, err := content.NewRedactor(config.Redaction)
+	if err != nil {
+		return content.Query{}, err
+	}
+
+	files, redactions := redactor.RedactFiles(query.Scan.Files)
+	query.Scan.Files = files
+
+	if report {
+		reporter := reporterFor(query)
+		for _, redaction := range redactions {
+			reporter.Infof("Redacted %s in %s before sending it to %s", redaction.Summary(), redaction.File, provider)
+		}
+	}
+	return query, nil
+}
+
+// isLocalProvider reports whether file content stays on this machine.
+func isLocalProvider(config utils.Config, provider string) bool {
+	switch provider {
+	case "metadata":
+		return true
+	case "ollama":
+		host := envconfig.Host()
+		if config.AI.HTTP.BaseURL != "" {
+			parsed, err := url.Parse(config.AI.HTTP.BaseURL)
+			if err != nil {
+				return false
+			}
+			host = parsed
+		}
+		return isLoopbackHost(host.Hostname())
+	default:
+		return false
+	}
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
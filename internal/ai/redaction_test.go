@@ -0,0 +1,41 @@
+package ai
+
+import (
+	"testing"
+
+	content "nomnom/internal/content"
+	utils "nomnom/internal/utils"
+)
+
+func TestRedactForProvider(t *testing.T) {
+	query := content.Query{Scan: content.ScanResult{Files: []content.ScannedFile{{Context: "mail me at jane@example.com"}}}}
+
+	remote, err := redactForProvider(utils.Config{}, "openrouter", query, false)
+	if err != nil {
+		t.Fatalf("redactForProvider() error = %v", err)
+	}
+	if got := remote.Scan.Files[0].Context; got != "mail me at [REDACTED:email]" {
+		t.Fatalf("remote context = %q", got)
+	}
+	if query.Scan.Files[0].Context != "mail me at jane@example.com" {
+		t.Fatal("redactForProvider() modified the original scan")
+	}
+
+	local, err := redactForProvider(utils.Config{}, "ollama", query, false)
+	if err != nil {
+		t.Fatalf("redactForProvider() error = %v", err)
+	}
+	if local.Scan.Files[0].Context != query.Scan.Files[0].Context {
+		t.Fatalf("local context was redacted: %q", local.Scan.Files[0].Context)
+	}
+}
+
+func TestIsLocalProvider(t *testing.T) {
+	remoteOllama := utils.Config{AI: utils.AIConfig{HTTP: utils.HTTPConfig{BaseURL: "https://ollama.example.com"}}}
+	if isLocalProvider(remoteOllama, "ollama") {
+		t.Fatal("ollama behind a remote base URL should not count as local")
+	}
+	if !isLocalProvider(utils.Config{}, "metadata") || isLocalProvider(utils.Config{}, "deepseek") {
+		t.Fatal("unexpected locality for metadata or deepseek")
+	}
+}
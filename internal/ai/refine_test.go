@@ -0,0 +1,36 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	content "nomnom/internal/content"
+)
+
+func TestNamerRefineSendsConversation(t *testing.T) {
+	client := &fakeChat{replies: []string{"acme_invoice_march.pdf"}}
+	n := &namer{client: client, prompt: "prompt", opts: QueryOpts{Model: "test", Case: "snake"}}
+	entry := content.RenamePlanEntry{
+		File:          content.ScannedFile{OriginalName: "scan.pdf", Context: "Invoice from ACME"},
+		SuggestedName: "invoice.pdf",
+	}
+
+	refined, err := n.refine(entry, []content.Refinement{{Suggestion: "invoice.pdf", Feedback: "include the client name"}})
+	if err != nil {
+		t.Fatalf("refine() error = %v", err)
+	}
+	if refined.SuggestedName != "acme_invoice_march.pdf" {
+		t.Fatalf("refine() name = %q", refined.SuggestedName)
+	}
+
+	messages := client.requests[0]
+	if len(messages) != 4 {
+		t.Fatalf("refine() sent %d messages, want 4", len(messages))
+	}
+	if messages[2].Role != "assistant" || messages[2].Content != "invoice.pdf" {
+		t.Fatalf("previous suggestion message = %#v", messages[2])
+	}
+	if messages[3].Role != "user" || !strings.Contains(messages[3].Content, "include the client name") {
+		t.Fatalf("feedback message = %#v", messages[3])
+	}
+}
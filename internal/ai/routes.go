@@ -0,0 +1,210 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	content "nomnom/internal/content"
+	prompts "nomnom/internal/prompts"
+	utils "nomnom/internal/utils"
+)
+
+// route is one entry of ai.routes resolved into a full config. The default
+// route built from the top-level ai settings has no selectors and matches last.
+type route struct {
+	name     string
+	match    utils.RouteConfig
+	config   utils.Config
+	provider string
+	query    content.Query
+
+	nameFunc nameFunc
+	refine   content.RefineFunc
+}
+
+// sendQueryWithRoutes names every file with the provider, model and prompt of
+// the first route that matches it. Routes without files are never contacted.
+func sendQueryWithRoutes(config utils.Config, provider string, query content.Query) (content.Query, error) {
+	if len(query.Scan.Files) == 0 {
+		return content.Query{}, fmt.Errorf("no files to process")
+	}
+
+	routes, err := buildRoutes(config, provider, query)
+	if err != nil {
+		return content.Query{}, err
+	}
+
+	reporter := reporterFor(query)
+	assigned := make([][]int, len(routes))
+	for index, file := range query.Scan.Files {
+		matched := matchRoute(routes, file)
+		assigned[matched] = append(assigned[matched], index)
+	}
+
+	files := make([]content.ScannedFile, len(query.Scan.Files))
+	copy(files, query.Scan.Files)
+	routeOf := make(map[string]int, len(files))
+	siblings := siblingsFor(config)
+	for index := range routes {
+		if len(assigned[index]) == 0 {
+			continue
+		}
+		if err := routes[index].prepare(reporter, siblings); err != nil {
+			return content.Query{}, fmt.Errorf("route %s: %w", routes[index].name, err)
+		}
+
+		routed := routes[index].query
+		routed.Scan.Files = make([]content.ScannedFile, 0, len(assigned[index]))
+		for _, fileIndex := range assigned[index] {
+			routed.Scan.Files = append(routed.Scan.Files, files[fileIndex])
+		}
+		routed, err = redactForProvider(routes[index].config, routes[index].provider, routed, true)
+		if err != nil {
+			return content.Query{}, err
+		}
+		for position, fileIndex := range assigned[index] {
+			files[fileIndex] = routed.Scan.Files[position]
+			routeOf[files[fileIndex].SourcePath] = index
+		}
+
+		reporter.Infof("Route %s: %d files with %s", routes[index].name, len(assigned[index]), routeModel(routes[index]))
+	}
+
+	workers, retries, _, err := aiRuntime(config)
+	if err != nil {
+		return content.Query{}, err
+	}
+
+	query.Plan = buildRenamePlan(files, workers, retries, reporter, func(file content.ScannedFile, retryHint string) (suggestion, error) {
+		return routes[routeOf[file.SourcePath]].nameFunc(file, retryHint)
+	})
+	query.Refine = func(entry content.RenamePlanEntry, history []content.Refinement) (content.RenamePlanEntry, error) {
+		selected := routes[routeOf[entry.File.SourcePath]]
+		if selected.refine == nil {
+			return entry, fmt.Errorf("route %s cannot refine suggestions", selected.name)
+		}
+		return selected.refine(entry, history)
+	}
+	return query, nil
+}
+
+// buildRoutes resolves every configured route and appends the default route.
+func buildRoutes(config utils.Config, provider string, query content.Query) ([]route, error) {
+	library, err := prompts.ForConfig(query.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]route, 0, len(config.AI.Routes)+1)
+	for _, match := range config.AI.Routes {
+		if len(match.Categories) == 0 && len(match.Extensions) == 0 {
+			return nil, fmt.Errorf("route %q needs categories or extensions to match", match.Name)
+		}
+
+		routeConfig := config
+		routeConfig.AI.Routes = nil
+		if match.Vision != nil {
+			routeConfig.AI.Vision.Enabled = *match.Vision
+		}
+		if match.Provider != "" && match.Provider != provider {
+			routeConfig.AI.Provider = match.Provider
+			routeConfig.AI.Model = ""
+			routeConfig.AI.APIKey = ""
+		}
+		if match.Model != "" {
+			routeConfig.AI.Model = match.Model
+		}
+		if match.APIKey != "" {
+			routeConfig.AI.APIKey = match.APIKey
+		}
+
+		routeQuery := query
+		if match.Prompt != "" {
+			prompt, err := library.Resolve(match.Prompt, config)
+			if err != nil {
+				return nil, fmt.Errorf("resolve prompt for route %s: %w", routeName(match), err)
+			}
+			routeConfig.AI.Prompt = prompt
+			routeQuery.Prompt = prompt
+		}
+
+		routes = append(routes, route{name: routeName(match), match: match, config: routeConfig, query: routeQuery})
+	}
+
+	defaultConfig := config
+	defaultConfig.AI.Routes = nil
+	return append(routes, route{name: "default", config: defaultConfig, provider: provider, query: query}), nil
+}
+
+// prepare resolves the route provider and builds the function that names its files.
+func (r *route) prepare(reporter utils.Reporter, siblings *siblingRegistry) error {
+	config, provider, err := resolveProvider(r.config, reporter)
+	if err != nil {
+		return err
+	}
+	r.config = config
+	r.provider = provider
+
+	if provider == "metadata" {
+		opts := metadataOpts(config)
+		opts.Route = r.name
+		r.nameFunc = metadataNameFunc(config, r.query, opts)
+		return nil
+	}
+
+	client, opts, err := newChatClient(provider, config)
+	if err != nil {
+		return err
+	}
+	opts.Route = r.name
+
+	prompt := r.query.Prompt
+	if provider == "ollama" {
+		prompt = ollamaPrompt(config, r.query)
+	}
+
+	n := newNamer(client, config, r.query, prompt, opts, siblings)
+	r.nameFunc = n.name
+	r.refine = n.refine
+	return nil
+}
+
+// matchRoute returns the index of the first route matching file, falling back to the default route.
+func matchRoute(routes []route, file content.ScannedFile) int {
+	extension := strings.ToLower(file.Extension)
+	for index, candidate := range routes {
+		for _, category := range candidate.match.Categories {
+			if strings.EqualFold(category, file.Category) {
+				return index
+			}
+		}
+		for _, routeExtension := range candidate.match.Extensions {
+			if normalizeExtension(routeExtension) == extension {
+				return index
+			}
+		}
+	}
+	return len(routes) - 1
+}
+
+func normalizeExtension(extension string) string {
+	extension = strings.ToLower(strings.TrimSpace(extension))
+	if extension != "" && !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	return extension
+}
+
+func routeName(match utils.RouteConfig) string {
+	if match.Name != "" {
+		return match.Name
+	}
+	return strings.Join(append(append([]string{}, match.Categories...), match.Extensions...), ",")
+}
+
+func routeModel(r route) string {
+	if r.provider == "metadata" || r.config.AI.Model == "" {
+		return r.provider
+	}
+	return r.provider + "/" + r.config.AI.Model
+}
@@ -0,0 +1,101 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	content "nomnom/internal/content"
+	utils "nomnom/internal/utils"
+)
+
+func TestMatchRoute(t *testing.T) {
+	routes, err := buildRoutes(utils.Config{
+		AI: utils.AIConfig{Routes: []utils.RouteConfig{
+			{Name: "photos", Categories: []string{"images"}},
+			{Extensions: []string{"TXT"}, Provider: "ollama"},
+		}},
+	}, "deepseek", content.Query{})
+	assert.NoError(t, err)
+	assert.Len(t, routes, 3)
+
+	assert.Equal(t, 0, matchRoute(routes, content.ScannedFile{Category: "Images", Extension: ".png"}))
+	assert.Equal(t, 1, matchRoute(routes, content.ScannedFile{Category: "Documents", Extension: ".txt"}))
+	assert.Equal(t, 2, matchRoute(routes, content.ScannedFile{Category: "Documents", Extension: ".pdf"}))
+	assert.Equal(t, "TXT", routes[1].name)
+	assert.Equal(t, "default", routes[2].name)
+}
+
+func TestBuildRoutesSwitchesProvider(t *testing.T) {
+	config := utils.Config{AI: utils.AIConfig{
+		Provider: "deepseek",
+		Model:    "deepseek-chat",
+		APIKey:   "deepseek-key",
+		Vision:   utils.VisionConfig{Enabled: true},
+		Routes:   []utils.RouteConfig{{Categories: []string{"Audios"}, Provider: "metadata"}},
+	}}
+
+	routes, err := buildRoutes(config, "deepseek", content.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, "metadata", routes[0].config.AI.Provider)
+	assert.Empty(t, routes[0].config.AI.APIKey)
+	assert.Empty(t, routes[0].config.AI.Model)
+	assert.Equal(t, "deepseek-key", routes[1].config.AI.APIKey)
+
+	_, err = buildRoutes(utils.Config{AI: utils.AIConfig{Routes: []utils.RouteConfig{{Name: "empty"}}}}, "deepseek", content.Query{})
+	assert.Error(t, err)
+}
+
+func TestBuildRoutesVision(t *testing.T) {
+	disabled := false
+	config := utils.Config{AI: utils.AIConfig{
+		Provider: "openrouter",
+		Vision:   utils.VisionConfig{Enabled: true},
+		Routes: []utils.RouteConfig{
+			{Name: "photos", Categories: []string{"Images"}, Model: "google/gemini-2.0-flash-001"},
+			{Name: "scans", Extensions: []string{".pdf"}, Vision: &disabled},
+		},
+	}}
+
+	routes, err := buildRoutes(config, "openrouter", content.Query{})
+	assert.NoError(t, err)
+	assert.True(t, routes[0].config.AI.Vision.Enabled, "a route without vision keeps ai.vision.enabled")
+	assert.False(t, routes[1].config.AI.Vision.Enabled)
+	assert.True(t, routes[2].config.AI.Vision.Enabled)
+}
+
+func TestHandleAIRoutesFilesAndAttributesUsage(t *testing.T) {
+	baseDir := t.TempDir()
+	analytics := utils.NewAnalyticsStore(baseDir, false)
+	query := content.Query{
+		Analytics: analytics,
+		Scan: content.ScanResult{Files: []content.ScannedFile{{
+			SourcePath:   "/tmp/song.mp3",
+			OriginalName: "song.mp3",
+			Extension:    ".mp3",
+			Category:     "Audios",
+			Metadata:     map[string]string{"artist": "Nina Simone", "title": "Feeling Good"},
+		}}},
+	}
+	config := utils.Config{
+		Case: "snake",
+		AI: utils.AIConfig{
+			Provider: "deepseek",
+			APIKey:   "unused-key",
+			Routes:   []utils.RouteConfig{{Name: "music", Categories: []string{"Audios"}, Provider: "metadata"}},
+		},
+	}
+
+	result, err := HandleAI(config, query)
+	assert.NoError(t, err)
+	assert.Len(t, result.Plan, 1)
+	assert.Equal(t, "nina_simone_feeling_good.mp3", result.Plan[0].SuggestedName)
+
+	assert.NoError(t, analytics.Close())
+	sessions, err := utils.ListAnalyticsSessions(baseDir)
+	assert.NoError(t, err)
+	session, err := utils.LoadAnalyticsSession(sessions[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "music", session.Models["music|metadata:templates"].Route)
+	assert.Equal(t, 1, session.Models["music|metadata:templates"].Requests)
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// siblingRegistry tracks the names already chosen per source directory during
+// one run, so later files can follow the same pattern without colliding.
+type siblingRegistry struct {
+	mu    sync.Mutex
+	byDir map[string][]string
+}
+
+func newSiblingRegistry() *siblingRegistry {
+	return &siblingRegistry{byDir: make(map[string][]string)}
+}
+
+func (r *siblingRegistry) decided(dir string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.byDir[dir])
+}
+
+// reserve records name for dir, failing with a retryable validation error if
+// another file in the same directory already received it.
+func (r *siblingRegistry) reserve(dir, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.byDir[dir] {
+		if strings.EqualFold(existing, name) {
+			return fmt.Errorf("invalid response from AI: The name %s is already used by another file in this folder.", name)
+		}
+	}
+	r.byDir[dir] = append(r.byDir[dir], name)
+	return nil
+}
+
+func (r *siblingRegistry) release(dir, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byDir[dir] = slices.DeleteFunc(r.byDir[dir], func(existing string) bool {
+		return strings.EqualFold(existing, name)
+	})
+}
+
+func siblingContext(siblings, decided []string) string {
+	if len(siblings) == 0 && len(decided) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	if len(siblings) > 0 {
+		builder.WriteString("\nOther files in the same folder: ")
+		builder.WriteString(strings.Join(siblings, ", "))
+	}
+	if len(decided) > 0 {
+		builder.WriteString("\nNames already chosen for files in this folder: ")
+		builder.WriteString(strings.Join(decided, ", "))
+	}
+	builder.WriteString("\nKeep the naming pattern consistent with the other files in this folder and do not reuse a name that is already chosen.")
+	return builder.String()
+}
@@ -0,0 +1,54 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	content "nomnom/internal/content"
+)
+
+func TestSiblingRegistryRejectsDuplicates(t *testing.T) {
+	registry := newSiblingRegistry()
+
+	if err := registry.reserve("photos", "beach_day_01.jpg"); err != nil {
+		t.Fatalf("reserve() error = %v", err)
+	}
+	if err := registry.reserve("other", "beach_day_01.jpg"); err != nil {
+		t.Fatalf("reserve() in another dir error = %v", err)
+	}
+
+	err := registry.reserve("photos", "Beach_Day_01.jpg")
+	if err == nil || retryReason(err) == "" {
+		t.Fatalf("reserve() error = %v, want retryable duplicate error", err)
+	}
+}
+
+func TestNamerSharesSiblingNames(t *testing.T) {
+	client := &fakeChat{replies: []string{"beach_day_01.jpg", "beach_day_01.jpg", "beach_day_02.jpg"}}
+	n := &namer{client: client, opts: QueryOpts{Model: "test", Case: "snake"}, siblings: newSiblingRegistry()}
+
+	first := content.ScannedFile{RelativePath: "trip/IMG_1.jpg", OriginalName: "IMG_1.jpg", Siblings: []string{"IMG_2.jpg"}}
+	second := content.ScannedFile{RelativePath: "trip/IMG_2.jpg", OriginalName: "IMG_2.jpg", Siblings: []string{"IMG_1.jpg"}}
+
+	if _, err := n.name(first, ""); err != nil {
+		t.Fatalf("name(first) error = %v", err)
+	}
+	if _, err := n.name(second, ""); err == nil {
+		t.Fatal("name(second) error = nil, want duplicate error")
+	}
+
+	named, err := n.name(second, "duplicate")
+	if err != nil {
+		t.Fatalf("name(second) retry error = %v", err)
+	}
+	if named.Name != "beach_day_02.jpg" {
+		t.Fatalf("name(second) = %q, want beach_day_02.jpg", named.Name)
+	}
+
+	context := client.requests[1][1].Content
+	for _, want := range []string{"Other files in the same folder: IMG_1.jpg", "Names already chosen for files in this folder: beach_day_01.jpg"} {
+		if !strings.Contains(context, want) {
+			t.Fatalf("context %q does not contain %q", context, want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	content "nomnom/internal/content"
+	utils "nomnom/internal/utils"
+)
+
+const taxonomyPrompt = `You are a desktop organizer designing a folder structure for a set of files.
+You will be given every file in the scan with a short snippet of its content.
+Propose between 3 and 12 folders that group these files consistently by purpose or topic.
+Folder names must be short, human readable and must not contain slashes.
+Respond only with a JSON array in this form:
+[{"name": "Invoices", "description": "Bills and payment receipts from vendors"}]`
+
+const taxonomySnippetLength = 160
+
+// ProposeTaxonomy asks the configured provider for a folder taxonomy covering every scanned file.
+func ProposeTaxonomy(config utils.Config, query content.Query) ([]utils.TaxonomyFolder, error) {
+	reporter := reporterFor(query)
+	config, provider, err := resolveProvider(config, reporter)
+	if err != nil {
+		return nil, err
+	}
+	if len(query.Scan.Files) == 0 {
+		return nil, fmt.Errorf("no files to process")
+	}
+	if config.AI.APIKey == "dummy-key" {
+		return nil, nil
+	}
+
+	query, err = redactForProvider(config, provider, query, false)
+	if err != nil {
+		return nil, err
+	}
+
+	client, opts, err := newChatClient(provider, config)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []chatMessage{
+		{Role: "system", Content: taxonomyPrompt},
+		{Role: "user", Content: taxonomyContext(query.Scan.Files)},
+	}
+	started := time.Now()
+	reply, err := client.Chat(context.Background(), opts.Model, messages)
+	recordAudit(query.Audit, utils.AuditEntry{Kind: utils.AuditTaxonomy, Model: reply.Model, Response: reply.Content}, opts, messages, suggestion{}, err, started)
+	if err != nil {
+		return nil, err
+	}
+
+	model := reply.Model
+	if model == "" {
+		model = opts.Model
+	}
+	recordAnalyticsUsage(query.Analytics, opts, model, reply.PromptTokens, reply.CompletionTokens, reply.TotalTokens, false)
+
+	return parseTaxonomy(reply.Content)
+}
+
+func taxonomyContext(files []content.ScannedFile) string {
+	var builder strings.Builder
+	builder.WriteString("Files:\n")
+	for _, file := range files {
+		snippet := strings.Join(strings.Fields(file.Context), " ")
+		if len(snippet) > taxonomySnippetLength {
+			snippet = strings.ToValidUTF8(snippet[:taxonomySnippetLength], "") + "..."
+		}
+		fmt.Fprintf(&builder, "- %s: %s\n", file.RelativePath, snippet)
+	}
+	return builder.String()
+}
+
+func parseTaxonomy(raw string) ([]utils.TaxonomyFolder, error) {
+	start := strings.Index(raw, "[")
+	end := strings.LastIndex(raw, "]")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("taxonomy response did not contain a JSON array")
+	}
+
+	var proposed []utils.TaxonomyFolder
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &proposed); err != nil {
+		return nil, fmt.Errorf("failed to parse taxonomy response: %w", err)
+	}
+
+	folders := make([]utils.TaxonomyFolder, 0, len(proposed))
+	seen := make(map[string]struct{}, len(proposed))
+	for _, folder := range proposed {
+		folder.Name = strings.TrimSpace(folder.Name)
+		folder.Description = strings.TrimSpace(folder.Description)
+		if ValidateFolderName(folder.Name) != nil {
+			continue
+		}
+		key := strings.ToLower(folder.Name)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		folders = append(folders, folder)
+	}
+
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("taxonomy response did not contain any usable folders")
+	}
+	return folders, nil
+}
+
+// ValidateFolderName reports whether name can be used as a single taxonomy folder.
+func ValidateFolderName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("folder name cannot be empty")
+	}
+	if name == "." || name == ".." {
+		return fmt.Errorf("folder name cannot be %q", name)
+	}
+	if strings.ContainsAny(name, `<>:"/\|?*`) {
+		return fmt.Errorf("folder name cannot contain any of <>:\"/\\|?*")
+	}
+	return nil
+}
+
+func withTaxonomy(prompt string, taxonomy []utils.TaxonomyFolder) string {
+	if len(taxonomy) == 0 {
+		return prompt
+	}
+
+	var builder strings.Builder
+	builder.WriteString(prompt)
+	builder.WriteString("\n\nPlace the file in exactly one of the folders below. Respond with the folder name, a slash, and the new filename, for example: ")
+	builder.WriteString(taxonomy[0].Name)
+	builder.WriteString("/new_file_name.ext\n\nFolders:\n")
+	for _, folder := range taxonomy {
+		fmt.Fprintf(&builder, "- %s: %s\n", folder.Name, folder.Description)
+	}
+	return builder.String()
+}
+
+// splitFolderChoice separates the chosen taxonomy folder from the suggested filename.
+func splitFolderChoice(raw string, taxonomy []utils.TaxonomyFolder) (string, string, error) {
+	raw = strings.Trim(strings.TrimSpace(raw), "`")
+	index := strings.LastIndex(raw, "/")
+	if index == -1 {
+		return "", "", fmt.Errorf("invalid response from AI: The response must start with one of the approved folders followed by a slash.")
+	}
+
+	chosen := strings.TrimSpace(raw[:index])
+	for _, folder := range taxonomy {
+		if strings.EqualFold(folder.Name, chosen) {
+			return folder.Name, raw[index+1:], nil
+		}
+	}
+
+	names := make([]string, 0, len(taxonomy))
+	for _, folder := range taxonomy {
+		names = append(names, folder.Name)
+	}
+	return "", "", fmt.Errorf("invalid response from AI: The folder %q is not one of the approved folders: %s.", chosen, strings.Join(names, ", "))
+}
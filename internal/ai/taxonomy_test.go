@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	content "nomnom/internal/content"
+	utils "nomnom/internal/utils"
+)
+
+type fakeChat struct {
+	replies  []string
+	requests [][]chatMessage
+}
+
+func (f *fakeChat) Chat(_ context.Context, model string, messages []chatMessage) (chatReply, error) {
+	f.requests = append(f.requests, messages)
+	reply := f.replies[0]
+	if len(f.replies) > 1 {
+		f.replies = f.replies[1:]
+	}
+	return chatReply{Content: reply, Model: model}, nil
+}
+
+func TestParseTaxonomy(t *testing.T) {
+	raw := "Here you go:\n```json\n[{\"name\": \"Invoices\", \"description\": \"Bills\"}, {\"name\": \"invoices\", \"description\": \"dup\"}, {\"name\": \"a/b\", \"description\": \"bad\"}, {\"name\": \"Photos\", \"description\": \"Pictures\"}]\n```"
+
+	folders, err := parseTaxonomy(raw)
+	if err != nil {
+		t.Fatalf("parseTaxonomy() error = %v", err)
+	}
+
+	want := []string{"Invoices", "Photos"}
+	if len(folders) != len(want) {
+		t.Fatalf("parseTaxonomy() folders = %#v, want names %v", folders, want)
+	}
+	for index, name := range want {
+		if folders[index].Name != name {
+			t.Fatalf("parseTaxonomy()[%d] = %q, want %q", index, folders[index].Name, name)
+		}
+	}
+}
+
+func TestParseTaxonomyRejectsMissingArray(t *testing.T) {
+	if _, err := parseTaxonomy("no folders here"); err == nil {
+		t.Fatal("parseTaxonomy() error = nil, want error")
+	}
+}
+
+func TestSplitFolderChoice(t *testing.T) {
+	taxonomy := []utils.TaxonomyFolder{{Name: "Tax Returns"}, {Name: "Photos"}}
+
+	folder, name, err := splitFolderChoice("tax returns/2023_federal_return.pdf", taxonomy)
+	if err != nil {
+		t.Fatalf("splitFolderChoice() error = %v", err)
+	}
+	if folder != "Tax Returns" || name != "2023_federal_return.pdf" {
+		t.Fatalf("splitFolderChoice() = (%q, %q)", folder, name)
+	}
+
+	_, _, err = splitFolderChoice("Recipes/soup.pdf", taxonomy)
+	if err == nil || retryReason(err) == "" {
+		t.Fatalf("splitFolderChoice() error = %v, want retryable validation error", err)
+	}
+}
+
+func TestNamerUsesTaxonomy(t *testing.T) {
+	client := &fakeChat{replies: []string{"Invoices/acme_invoice_march.pdf"}}
+	query := content.Query{Taxonomy: []utils.TaxonomyFolder{{Name: "Invoices", Description: "Bills"}}}
+	file := content.ScannedFile{OriginalName: "scan001.pdf", Context: "Invoice from ACME"}
+
+	n := &namer{client: client, query: query, prompt: "prompt", opts: QueryOpts{Model: "test", Case: "snake"}}
+	named, err := n.name(file, "")
+	if err != nil {
+		t.Fatalf("name() error = %v", err)
+	}
+	if named.Folder != "Invoices" || named.Name != "acme_invoice_march.pdf" {
+		t.Fatalf("name() = %#v", named)
+	}
+	if system := client.requests[0][0].Content; !strings.Contains(system, "- Invoices: Bills") {
+		t.Fatalf("system prompt %q does not list taxonomy folders", system)
+	}
+}
+
+func TestNamerRecordsAudit(t *testing.T) {
+	audit, err := utils.NewAuditLog(t.TempDir(), utils.AuditConfig{Enabled: true})
+	if err != nil {
+		t.Fatalf("NewAuditLog() error = %v", err)
+	}
+
+	client := &fakeChat{replies: []string{"bad/name", "quarterly_report.pdf"}}
+	file := content.ScannedFile{RelativePath: "docs/scan.pdf", OriginalName: "scan.pdf", Context: "Q3 report"}
+	n := &namer{client: client, query: content.Query{Audit: audit}, prompt: "prompt", opts: QueryOpts{Provider: "fake", Model: "test", Case: "snake"}}
+
+	named := nameWithRetry(file, 1, utils.NopReporter{}, n.name)
+	if named.Name != "quarterly_report.pdf" {
+		t.Fatalf("nameWithRetry() = %#v", named)
+	}
+	if err := audit.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	entries, err := utils.ReadAuditLog(audit.Path())
+	if err != nil {
+		t.Fatalf("ReadAuditLog() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("audit entries = %d, want 2", len(entries))
+	}
+	if entries[0].Error == "" || entries[0].Response != "bad/name" || entries[0].File != "docs/scan.pdf" {
+		t.Fatalf("first entry = %#v", entries[0])
+	}
+	if entries[1].Attempt != 2 || entries[1].RetryHint == "" || entries[1].Name != "quarterly_report.pdf" || entries[1].Prompt != "prompt" {
+		t.Fatalf("second entry = %#v", entries[1])
+	}
+}
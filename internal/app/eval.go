@@ -0,0 +1,212 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	ai "nomnom/internal/ai"
+	content "nomnom/internal/content"
+	eval "nomnom/internal/eval"
+	prompts "nomnom/internal/prompts"
+	"nomnom/internal/utils"
+)
+
+type EvalOptions struct {
+	DatasetPath string
+	ConfigPath  string
+	// Prompts and Models replace the dataset variants with every combination of them.
+	Prompts    []string
+	Models     []string
+	OutputPath string
+}
+
+// Evaluate names every dataset case with each variant through the normal scan
+// and naming path, scores the names and saves the outputs for later replay.
+func (Service) Evaluate(opts EvalOptions, reporter utils.Reporter) (eval.Results, string, error) {
+	if reporter == nil {
+		reporter = utils.NopReporter{}
+	}
+
+	config, err := utils.LoadConfig(opts.ConfigPath, "")
+	if err != nil {
+		return eval.Results{}, "", err
+	}
+	dataset, err := eval.LoadDataset(opts.DatasetPath)
+	if err != nil {
+		return eval.Results{}, "", err
+	}
+	library, err := prompts.ForConfig(opts.ConfigPath)
+	if err != nil {
+		return eval.Results{}, "", fmt.Errorf("resolve prompt: %w", err)
+	}
+
+	scan, err := content.ScanDirectory(dataset.Root(), config, reporter)
+	if err != nil {
+		return eval.Results{}, "", fmt.Errorf("scan dataset directory: %w", err)
+	}
+	defer func() { _ = scan.Cleanup() }()
+
+	cases := scan
+	cases.Files = nil
+	for _, file := range scan.Files {
+		if _, ok := dataset.Case(file.RelativePath); ok {
+			cases.Files = append(cases.Files, file)
+		}
+	}
+	for _, item := range dataset.Cases {
+		if !scanned(cases.Files, item.File) {
+			reporter.Warnf("Case %s was not found in %s", item.File, cases.RootDir)
+		}
+	}
+	if len(cases.Files) == 0 {
+		return eval.Results{}, "", fmt.Errorf("none of the dataset cases were found in %s", cases.RootDir)
+	}
+
+	started := time.Now()
+	results := eval.Results{Dataset: opts.DatasetPath, CreatedAt: started}
+	for _, variant := range evalVariants(dataset, opts) {
+		run, err := evaluateVariant(config, library, variant, cases, opts.ConfigPath, reporter)
+		if err != nil {
+			return eval.Results{}, "", err
+		}
+		results.Runs = append(results.Runs, eval.Score(dataset, run))
+	}
+
+	outputPath := opts.OutputPath
+	if outputPath == "" {
+		outputPath = eval.ResultsPath(dataset, started)
+	}
+	if err := eval.Save(outputPath, results); err != nil {
+		return eval.Results{}, "", err
+	}
+	return results, outputPath, nil
+}
+
+// ReplayEval scores saved eval outputs against the current dataset without contacting any provider.
+func (Service) ReplayEval(datasetPath string, resultPaths []string) (eval.Results, error) {
+	dataset, err := eval.LoadDataset(datasetPath)
+	if err != nil {
+		return eval.Results{}, err
+	}
+
+	replayed := eval.Results{Dataset: datasetPath, CreatedAt: time.Now()}
+	for _, path := range resultPaths {
+		saved, err := eval.Load(path)
+		if err != nil {
+			return eval.Results{}, err
+		}
+		for _, run := range saved.Runs {
+			if run.Variant.Name == "" && len(resultPaths) > 1 {
+				run.Variant.Name = fmt.Sprintf("%s (%s)", run.Label(), filepath.Base(path))
+			}
+			replayed.Runs = append(replayed.Runs, eval.Score(dataset, run))
+		}
+	}
+	return replayed, nil
+}
+
+// evaluateVariant runs one provider, model and prompt combination over the cases.
+func evaluateVariant(config utils.Config, library prompts.Library, variant eval.Variant, scan content.ScanResult, configPath string, reporter utils.Reporter) (eval.Run, error) {
+	variantConfig := config
+	if variant.Provider != "" || variant.Model != "" || variant.Prompt != "" {
+		// Routes would override the variant for the files they match.
+		variantConfig.AI.Routes = nil
+	}
+	if variant.Provider != "" && variant.Provider != config.AI.Provider {
+		variantConfig.AI.Provider = variant.Provider
+		variantConfig.AI.Model = ""
+		variantConfig.AI.APIKey = ""
+	}
+	if variant.Model != "" {
+		variantConfig.AI.Model = variant.Model
+	}
+
+	prompt, err := library.Resolve(variant.Prompt, variantConfig)
+	if err != nil {
+		return eval.Run{}, fmt.Errorf("resolve prompt: %w", err)
+	}
+
+	provider := variantConfig.AI.Provider
+	if provider == "" {
+		provider = "deepseek"
+	}
+	run := eval.Run{
+		Variant:  variant,
+		Provider: provider,
+		Model:    variantConfig.AI.Model,
+		Prompt:   prompt,
+		Case:     variantConfig.Case,
+		ASCII:    variantConfig.Naming.ASCII,
+	}
+	reporter.Infof("Evaluating %s on %d cases", run.Label(), len(scan.Files))
+
+	query := content.NewQuery(content.QueryParams{
+		Prompt:      prompt,
+		Dir:         scan.RootDir,
+		ConfigPath:  configPath,
+		AutoApprove: true,
+		DryRun:      true,
+		Reporter:    reporter,
+		Scan:        scan,
+	})
+
+	started := time.Now()
+	result, err := ai.HandleAI(variantConfig, *query)
+	run.Seconds = time.Since(started).Seconds()
+	if err != nil {
+		reporter.Warnf("Variant %s failed: %v", run.Label(), err)
+		for _, file := range scan.Files {
+			run.Outputs = append(run.Outputs, eval.Output{File: file.RelativePath, Error: err.Error()})
+		}
+		return run, nil
+	}
+
+	for _, entry := range result.Plan {
+		run.Outputs = append(run.Outputs, eval.Output{
+			File:       entry.File.RelativePath,
+			Name:       entry.SuggestedName,
+			Folder:     entry.Folder,
+			Confidence: entry.Confidence,
+			Status:     string(entry.Status),
+		})
+	}
+	return run, nil
+}
+
+// evalVariants returns the variants to run: the command line combinations, the
+// dataset variants, or the config as is.
+func evalVariants(dataset eval.Dataset, opts EvalOptions) []eval.Variant {
+	if len(opts.Prompts) == 0 && len(opts.Models) == 0 {
+		if len(dataset.Variants) > 0 {
+			return dataset.Variants
+		}
+		return []eval.Variant{{}}
+	}
+
+	models := opts.Models
+	if len(models) == 0 {
+		models = []string{""}
+	}
+	promptList := opts.Prompts
+	if len(promptList) == 0 {
+		promptList = []string{""}
+	}
+
+	variants := make([]eval.Variant, 0, len(models)*len(promptList))
+	for _, model := range models {
+		for _, prompt := range promptList {
+			variants = append(variants, eval.Variant{Model: model, Prompt: prompt})
+		}
+	}
+	return variants
+}
+
+func scanned(files []content.ScannedFile, relativePath string) bool {
+	for _, file := range files {
+		if filepath.Clean(file.RelativePath) == relativePath {
+			return true
+		}
+	}
+	return false
+}
@@ -8,6 +8,7 @@ import (
 
 	ai "nomnom/internal/ai"
 	content "nomnom/internal/content"
+	prompts "nomnom/internal/prompts"
 	"nomnom/internal/utils"
 )
 
@@ -46,7 +47,11 @@ func (Service) PrepareRun(opts RunOptions, reporter utils.Reporter, approver uti
 		return nil, err
 	}
 
-	resolvedPrompt, err := content.ResolvePrompt(opts.Prompt, config)
+	library, err := prompts.ForConfig(opts.ConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve prompt: %w", err)
+	}
+	resolvedPrompt, err := library.Resolve(opts.Prompt, config)
 	if err != nil {
 		return nil, fmt.Errorf("resolve prompt: %w", err)
 	}
@@ -68,6 +73,12 @@ func (Service) PrepareRun(opts RunOptions, reporter utils.Reporter, approver uti
 	analytics := utils.NewAnalyticsStore(scan.RootDir, opts.DryRun)
 	analytics.RecordScan(len(scan.Files))
 
+	audit, err := newAuditLog(scan.RootDir, config)
+	if err != nil {
+		_ = scan.Cleanup()
+		return nil, fmt.Errorf("create audit log: %w", err)
+	}
+
 	query := content.NewQuery(content.QueryParams{
 		Prompt:      resolvedPrompt,
 		Dir:         scan.RootDir,
@@ -80,6 +91,7 @@ func (Service) PrepareRun(opts RunOptions, reporter utils.Reporter, approver uti
 		Reporter:    reporter,
 		Approver:    approver,
 		Analytics:   analytics,
+		Audit:       audit,
 		Scan:        scan,
 	})
 
@@ -98,6 +110,25 @@ func (Service) PrepareRun(opts RunOptions, reporter utils.Reporter, approver uti
 	}, nil
 }
 
+// newAuditLog opens the audit log when audit.enabled is set, scrubbing recorded context when audit.redact is set.
+func newAuditLog(baseDir string, config utils.Config) (*utils.AuditLog, error) {
+	audit, err := utils.NewAuditLog(baseDir, config.Audit)
+	if err != nil || audit == nil || !config.Audit.Redact {
+		return audit, err
+	}
+
+	redactor, err := content.NewRedactor(config.Redaction)
+	if err != nil {
+		_ = audit.Close()
+		return nil, err
+	}
+	audit.Scrub = func(text string) string {
+		redacted, _ := redactor.Redact(text)
+		return redacted
+	}
+	return audit, nil
+}
+
 func (Service) GeneratePlan(run *PreparedRun) error {
 	if run == nil || run.Query == nil {
 		return fmt.Errorf("prepared run is nil")
@@ -109,6 +140,7 @@ func (Service) GeneratePlan(run *PreparedRun) error {
 	}
 
 	run.Query.Plan = result.Plan
+	run.Query.Refine = result.Refine
 	if run.Query.Analytics != nil {
 		run.Query.Analytics.RecordRenamePlan(len(result.Plan))
 	}
@@ -116,6 +148,29 @@ func (Service) GeneratePlan(run *PreparedRun) error {
 	return nil
 }
 
+// ProposeTaxonomy asks the provider for a folder taxonomy for the whole scan and lets the reviewer
+// approve or edit it. The approved folders constrain every per-file request made by GeneratePlan.
+func (Service) ProposeTaxonomy(run *PreparedRun, reviewer utils.TaxonomyReviewer) error {
+	if run == nil || run.Query == nil {
+		return fmt.Errorf("prepared run is nil")
+	}
+
+	folders, err := ai.ProposeTaxonomy(run.Config, *run.Query)
+	if err != nil {
+		return err
+	}
+
+	if len(folders) > 0 && !run.Query.AutoApprove && reviewer != nil {
+		folders, err = reviewer.ReviewTaxonomy(folders)
+		if err != nil {
+			return fmt.Errorf("review taxonomy: %w", err)
+		}
+	}
+
+	run.Query.Taxonomy = folders
+	return nil
+}
+
 func (Service) ApplyPlan(run *PreparedRun) ([]content.ProcessResult, error) {
 	if run == nil || run.Query == nil {
 		return nil, fmt.Errorf("prepared run is nil")
@@ -173,6 +228,9 @@ func (run *PreparedRun) Close() error {
 		if run.Query.Analytics != nil {
 			closeErr = errors.Join(closeErr, run.Query.Analytics.Close())
 		}
+		if run.Query.Audit != nil {
+			closeErr = errors.Join(closeErr, run.Query.Audit.Close())
+		}
 	}
 
 	return closeErr
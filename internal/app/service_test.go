@@ -1,6 +1,8 @@
 package app
 
 import (
+	"bufio"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -69,3 +71,118 @@ func TestPrepareRunAndClose(t *testing.T) {
 		t.Fatalf("sessions len = %d, want 1", len(sessions))
 	}
 }
+
+func TestEvaluateWithMetadataProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	datasetPath := filepath.Join(tmpDir, "cases.json")
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("hello world"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(configPath, []byte(`{
+  "case": "snake",
+  "ai": {
+    "provider": "metadata",
+    "metadata": {"templates": {"Documents": "{category}_{original}"}}
+  }
+}`), 0o644); err != nil {
+		t.Fatalf("WriteFile(config) error = %v", err)
+	}
+	if err := os.WriteFile(datasetPath, []byte(`{
+  "cases": [
+    {"file": "notes.txt", "expected": ["documents_notes"]},
+    {"file": "missing.txt", "expected": ["missing"]}
+  ]
+}`), 0o644); err != nil {
+		t.Fatalf("WriteFile(dataset) error = %v", err)
+	}
+
+	service := NewService()
+	results, path, err := service.Evaluate(EvalOptions{DatasetPath: datasetPath, ConfigPath: configPath}, utils.NopReporter{})
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if len(results.Runs) != 1 {
+		t.Fatalf("Evaluate() runs = %d, want 1", len(results.Runs))
+	}
+	run := results.Runs[0]
+	if run.Summary.Cases != 1 || run.Summary.ExactMatch != 1 || run.Summary.Compliance != 1 {
+		t.Fatalf("Summary = %+v, outputs = %+v, want one exact and compliant case", run.Summary, run.Outputs)
+	}
+
+	replayed, err := service.ReplayEval(datasetPath, []string{path})
+	if err != nil {
+		t.Fatalf("ReplayEval() error = %v", err)
+	}
+	if len(replayed.Runs) != 1 || replayed.Runs[0].Summary != run.Summary {
+		t.Fatalf("ReplayEval() = %+v, want the saved scores", replayed.Runs)
+	}
+}
+
+func TestPreparedRunCloseFlushesAuditLog(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "input")
+	configPath := filepath.Join(tmpDir, "config.json")
+
+	if err := os.MkdirAll(inputDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(inputDir, "hello.txt"), []byte("hello world"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(configPath, []byte(`{
+  "ai": {
+    "provider": "deepseek",
+    "api_key": "dummy-key"
+  },
+  "audit": {
+    "enabled": true
+  }
+}`), 0o644); err != nil {
+		t.Fatalf("WriteFile(config) error = %v", err)
+	}
+
+	run, err := NewService().PrepareRun(RunOptions{
+		Dir:        inputDir,
+		ConfigPath: configPath,
+		DryRun:     true,
+	}, utils.NopReporter{}, nil)
+	if err != nil {
+		t.Fatalf("PrepareRun() error = %v", err)
+	}
+	audit := run.Query.Audit
+	if audit == nil {
+		t.Fatal("PrepareRun() audit log = nil, want an open log")
+	}
+	for _, name := range []string{"first.txt", "second.txt"} {
+		if err := audit.Record(utils.AuditEntry{Kind: utils.AuditRename, File: "hello.txt", Name: name}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	if err := run.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := audit.Record(utils.AuditEntry{Kind: utils.AuditRename, File: "hello.txt"}); err == nil {
+		t.Fatal("Record() after Close() error = nil, want the log closed")
+	}
+
+	file, err := os.Open(audit.Path())
+	if err != nil {
+		t.Fatalf("Open(audit) error = %v", err)
+	}
+	defer file.Close()
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry utils.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("audit line %q is not complete JSON: %v", scanner.Text(), err)
+		}
+		names = append(names, entry.Name)
+	}
+	if len(names) != 2 || names[0] != "first.txt" || names[1] != "second.txt" {
+		t.Fatalf("audit names = %v, want both entries", names)
+	}
+}
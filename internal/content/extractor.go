@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	fileutils "nomnom/internal/files"
 	utils "nomnom/internal/utils"
@@ -21,15 +22,23 @@ const (
 	GB = 1024 * MB
 )
 
+// maxSiblings caps how many neighboring names are shared with each file.
+const maxSiblings = 20
+
 type ScannedFile struct {
-	SourcePath   string `json:"source_path,omitempty"`
-	RelativePath string `json:"relative_path,omitempty"`
-	OriginalName string `json:"original_name,omitempty"`
-	Extension    string `json:"extension,omitempty"`
-	Context      string `json:"context,omitempty"`
-	VisualPath   string `json:"visual_path,omitempty"`
-	Size         int64  `json:"size,omitempty"`
-	Category     string `json:"category,omitempty"`
+	SourcePath   string            `json:"source_path,omitempty"`
+	RelativePath string            `json:"relative_path,omitempty"`
+	OriginalName string            `json:"original_name,omitempty"`
+	Extension    string            `json:"extension,omitempty"`
+	Context      string            `json:"context,omitempty"`
+	VisualPath   string            `json:"visual_path,omitempty"`
+	Size         int64             `json:"size,omitempty"`
+	Category     string            `json:"category,omitempty"`
+	Siblings     []string          `json:"siblings,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	ModifiedAt   time.Time         `json:"modified_at,omitempty"`
+	Sparse       bool              `json:"sparse,omitempty"`
+	MIME         string            `json:"mime,omitempty"` // Content type detected from the file's bytes
 }
 
 type ScanResult struct {
@@ -37,17 +46,6 @@ type ScanResult struct {
 	Files   []ScannedFile `json:"files,omitempty"`
 }
 
-func formatFileSize(size int64) string {
-	if size < KB {
-		return fmt.Sprintf("%dB", size)
-	} else if size < MB {
-		return fmt.Sprintf("%.2fKB", float64(size)/KB)
-	} else if size < GB {
-		return fmt.Sprintf("%.2fMB", float64(size)/MB)
-	}
-	return fmt.Sprintf("%.2fGB", float64(size)/GB)
-}
-
 func convertSize(size string) (int64, error) {
 	size = strings.ToLower(size)
 	switch {
@@ -115,6 +113,11 @@ func ScanDirectory(dir string, config utils.Config, reporter utils.Reporter) (Sc
 	if err != nil {
 		return ScanResult{}, err
 	}
+	extractOpts := fileutils.NewExtractOptions(config.ContentExtraction)
+	extractOpts.Commands, err = fileutils.NewCommandExtractors(config.ContentExtraction.Extractors)
+	if err != nil {
+		return ScanResult{}, fmt.Errorf("invalid content_extraction.extractors: %w", err)
+	}
 
 	result := ScanResult{
 		RootDir: rootDir,
@@ -122,8 +125,9 @@ func ScanDirectory(dir string, config utils.Config, reporter utils.Reporter) (Sc
 	}
 
 	type fileResult struct {
-		file ScannedFile
-		err  error
+		file    ScannedFile
+		warning string
+		err     error
 	}
 
 	sem := make(chan struct{}, workers)
@@ -138,8 +142,8 @@ func ScanDirectory(dir string, config utils.Config, reporter utils.Reporter) (Sc
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			file, err := scanFile(rootDir, path, maxSize)
-			results <- fileResult{file: file, err: err}
+			file, warning, err := scanFile(rootDir, path, maxSize, extractOpts)
+			results <- fileResult{file: file, warning: warning, err: err}
 		}()
 	}
 
@@ -149,6 +153,9 @@ func ScanDirectory(dir string, config utils.Config, reporter utils.Reporter) (Sc
 	}()
 
 	for item := range results {
+		if item.warning != "" {
+			reporter.Warnf("%s", item.warning)
+		}
 		if item.err != nil {
 			reporter.Warnf("%v", item.err)
 			continue
@@ -160,15 +167,48 @@ func ScanDirectory(dir string, config utils.Config, reporter utils.Reporter) (Sc
 		return strings.Compare(a.RelativePath, b.RelativePath)
 	})
 
+	if config.Naming.SiblingContext {
+		attachSiblings(result.Files)
+	}
+
 	reporter.Infof("Successfully processed directory: %s", rootDir)
 	return result, nil
 }
 
+// attachSiblings records the names of neighboring files in the same directory on each file.
+func attachSiblings(files []ScannedFile) {
+	byDir := make(map[string][]string)
+	for _, file := range files {
+		dir := filepath.Dir(file.RelativePath)
+		byDir[dir] = append(byDir[dir], file.OriginalName)
+	}
+
+	for index := range files {
+		dir := filepath.Dir(files[index].RelativePath)
+		siblings := make([]string, 0, min(len(byDir[dir]), maxSiblings))
+		for _, name := range byDir[dir] {
+			if name == files[index].OriginalName {
+				continue
+			}
+			if len(siblings) == maxSiblings {
+				break
+			}
+			siblings = append(siblings, name)
+		}
+		files[index].Siblings = siblings
+	}
+}
+
 func collectPaths(root string, entries []os.DirEntry, paths *[]string) error {
 	for _, entry := range entries {
 		fullPath := filepath.Join(root, entry.Name())
 
 		if entry.IsDir() {
+			// Hidden directories include .nomnom, whose audit logs and
+			// analytics hold the contexts and responses of earlier runs.
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
 			subEntries, err := os.ReadDir(fullPath)
 			if err != nil {
 				return fmt.Errorf("failed to read directory %s: %w", fullPath, err)
@@ -208,42 +248,57 @@ func maxFileSize(config utils.Config) (int64, error) {
 	return maxSize, nil
 }
 
-func scanFile(rootDir, path string, maxSize int64) (ScannedFile, error) {
+// scanFile extracts one file. The warning reports a configured extractor that
+// failed before the built-in one was used.
+func scanFile(rootDir, path string, maxSize int64, extractOpts fileutils.ExtractOptions) (ScannedFile, string, error) {
 	info, err := os.Stat(path)
 	if err != nil {
-		return ScannedFile{}, fmt.Errorf("failed to stat file %s: %w", path, err)
+		return ScannedFile{}, "", fmt.Errorf("failed to stat file %s: %w", path, err)
 	}
 
 	if maxSize > 0 && info.Size() > maxSize {
-		return ScannedFile{}, fmt.Errorf("file %s is too large to process", path)
+		return ScannedFile{}, "", fmt.Errorf("file %s is too large to process", path)
 	}
 
-	extracted, err := fileutils.ExtractFileContent(path)
+	extracted, err := fileutils.ExtractFileContent(path, extractOpts)
 	if err != nil {
-		return ScannedFile{}, fmt.Errorf("failed to read file %s: %w", path, err)
+		return ScannedFile{}, "", fmt.Errorf("failed to read file %s: %w", path, err)
 	}
 
 	relativePath, err := filepath.Rel(rootDir, path)
 	if err != nil {
-		return ScannedFile{}, fmt.Errorf("failed to compute relative path for %s: %w", path, err)
+		return ScannedFile{}, "", fmt.Errorf("failed to compute relative path for %s: %w", path, err)
 	}
 
 	name := filepath.Base(path)
+	context := fmt.Sprintf("Content: %s\nFile: %s\nExtension Type: %s\nContent Type: %s\nSize: %s",
+		extracted.Text,
+		name,
+		filepath.Ext(name),
+		extracted.MIME,
+		fileutils.FormatSize(info.Size()),
+	)
+	if extracted.Truncated {
+		context += fmt.Sprintf("\nNote: the content was truncated to its start and end; [...] marks where %s of %s were left out.",
+			fileutils.FormatSize(max(extracted.FullLength-int64(len(extracted.Text)), 0)),
+			fileutils.FormatSize(extracted.FullLength),
+		)
+	}
+
 	return ScannedFile{
 		SourcePath:   path,
 		RelativePath: relativePath,
 		OriginalName: name,
 		Extension:    filepath.Ext(name),
-		Context: fmt.Sprintf("Content: %s\nFile: %s\nExtension Type: %s\nSize: %s",
-			extracted.Text,
-			name,
-			filepath.Ext(name),
-			formatFileSize(info.Size()),
-		),
-		VisualPath: extracted.PreviewImagePath,
-		Size:       info.Size(),
-		Category:   categoryForFile(name),
-	}, nil
+		Context:      context,
+		VisualPath:   extracted.PreviewImagePath,
+		Size:         info.Size(),
+		Category:     extracted.Category,
+		Metadata:     extracted.Metadata,
+		ModifiedAt:   info.ModTime(),
+		Sparse:       extracted.Sparse,
+		MIME:         extracted.MIME,
+	}, extracted.Warning, nil
 }
 
 func (r ScanResult) Cleanup() error {
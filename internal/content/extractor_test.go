@@ -3,6 +3,7 @@ package content
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	utils "nomnom/internal/utils"
@@ -117,3 +118,80 @@ func TestScanResultCleanupRemovesGeneratedPreviews(t *testing.T) {
 		t.Fatalf("Cleanup() should keep source image, stat err = %v", err)
 	}
 }
+
+func TestAttachSiblings(t *testing.T) {
+	files := []ScannedFile{
+		{RelativePath: "trip/a.jpg", OriginalName: "a.jpg"},
+		{RelativePath: "trip/b.jpg", OriginalName: "b.jpg"},
+		{RelativePath: "notes.txt", OriginalName: "notes.txt"},
+	}
+
+	attachSiblings(files)
+
+	if len(files[0].Siblings) != 1 || files[0].Siblings[0] != "b.jpg" {
+		t.Fatalf("Siblings = %v, want [b.jpg]", files[0].Siblings)
+	}
+	if len(files[2].Siblings) != 0 {
+		t.Fatalf("Siblings = %v, want none", files[2].Siblings)
+	}
+}
+
+func TestScanDirectorySkipsAuditLogs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("Meeting notes"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	audit, err := utils.NewAuditLog(dir, utils.AuditConfig{Enabled: true})
+	if err != nil {
+		t.Fatalf("NewAuditLog() error = %v", err)
+	}
+	if err := audit.Record(utils.AuditEntry{Kind: utils.AuditRename, File: "notes.txt", Context: "Meeting notes", Response: "meeting-notes"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := audit.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	config := utils.Config{ContentExtraction: utils.ContentExtractionConfig{ExtractText: true}}
+	scan, err := ScanDirectory(dir, config, nil)
+	if err != nil {
+		t.Fatalf("ScanDirectory() error = %v", err)
+	}
+	if len(scan.Files) != 1 || scan.Files[0].RelativePath != "notes.txt" {
+		paths := make([]string, 0, len(scan.Files))
+		for _, file := range scan.Files {
+			paths = append(paths, file.RelativePath)
+		}
+		t.Fatalf("ScanDirectory() files = %v, want only notes.txt", paths)
+	}
+}
+
+func TestScanDirectoryNotesTruncatedContent(t *testing.T) {
+	dir := t.TempDir()
+	text := strings.Repeat("A long log line. ", 500)
+	if err := os.WriteFile(filepath.Join(dir, "app.log"), []byte(text), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	config := utils.Config{ContentExtraction: utils.ContentExtractionConfig{ExtractText: true, MaxContentLength: 200}}
+	scan, err := ScanDirectory(dir, config, nil)
+	if err != nil {
+		t.Fatalf("ScanDirectory() error = %v", err)
+	}
+	if len(scan.Files) != 1 {
+		t.Fatalf("ScanDirectory() files = %d, want 1", len(scan.Files))
+	}
+
+	if scan.Files[0].MIME != "text/plain" {
+		t.Fatalf("MIME = %q, want text/plain", scan.Files[0].MIME)
+	}
+
+	context := scan.Files[0].Context
+	if len(context) > 600 {
+		t.Fatalf("len(Context) = %d, want the content capped near 200 bytes", len(context))
+	}
+	if !strings.Contains(context, "[...]") || !strings.Contains(context, "Note: the content was truncated") {
+		t.Fatalf("Context = %q, want a truncation marker and note", context)
+	}
+}
@@ -5,15 +5,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
 	utils "nomnom/internal/utils"
-
-	"slices"
 )
 
-const defaultPrompt = "You are a desktop organizer that creates nice names for the files with their context. Please follow snake case naming convention. Only respond with the new name and the file extension. Do not change the file extension."
-
 type QueryParams struct {
 	Prompt      string
 	Dir         string
@@ -26,7 +21,9 @@ type QueryParams struct {
 	Reporter    utils.Reporter
 	Approver    utils.Approver
 	Analytics   *utils.AnalyticsStore
+	Audit       *utils.AuditLog
 	Scan        ScanResult
+	Taxonomy    []utils.TaxonomyFolder
 }
 
 type Query struct {
@@ -41,15 +38,42 @@ type Query struct {
 	Reporter    utils.Reporter
 	Approver    utils.Approver
 	Analytics   *utils.AnalyticsStore
+	Audit       *utils.AuditLog
 	Scan        ScanResult
+	Taxonomy    []utils.TaxonomyFolder
 	Plan        []RenamePlanEntry
+	Refine      RefineFunc
 }
 
 type RenamePlanEntry struct {
 	File          ScannedFile
 	SuggestedName string
+	Folder        string
+	// Confidence is how sure the provider is about the name, from 0 to 1.
+	Confidence float64
+	Status     PlanStatus
+}
+
+// PlanStatus says what applying the plan does with an entry.
+type PlanStatus string
+
+const (
+	PlanRename       PlanStatus = ""
+	PlanKeptOriginal PlanStatus = "kept_original"
+	PlanNeedsReview  PlanStatus = "needs_review"
+)
+
+// Refinement is one round of user feedback on a suggested name.
+type Refinement struct {
+	Suggestion string
+	Folder     string
+	Feedback   string
 }
 
+// RefineFunc asks the provider that produced entry for a new suggestion, given
+// every earlier suggestion and the feedback the user gave on it.
+type RefineFunc func(entry RenamePlanEntry, history []Refinement) (RenamePlanEntry, error)
+
 type ProcessResult struct {
 	OriginalPath     string
 	NewPath          string
@@ -57,6 +81,10 @@ type ProcessResult struct {
 	FullNewPath      string
 	Success          bool
 	Error            error
+	// Status is set when a low-confidence entry was left untouched.
+	Status        PlanStatus
+	SuggestedName string
+	Confidence    float64
 }
 
 type SafeProcessor struct {
@@ -64,30 +92,6 @@ type SafeProcessor struct {
 	output string
 }
 
-type FileTypeCategory struct {
-	Name       string
-	Extensions []string
-}
-
-type Prompts struct {
-	Name     string
-	Path     string
-	TestPath string
-}
-
-var defaultCategories = []FileTypeCategory{
-	{Name: "Images", Extensions: []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp"}},
-	{Name: "Documents", Extensions: []string{".pdf", ".doc", ".docx", ".txt", ".md", ".rtf"}},
-	{Name: "Audios", Extensions: []string{".mp3", ".wav", ".flac", ".m4a", ".aac"}},
-	{Name: "Videos", Extensions: []string{".mp4", ".mov", ".avi", ".mkv", ".wmv"}},
-	{Name: "Others", Extensions: []string{}},
-}
-
-var NomNomPrompts = []Prompts{
-	{Name: "research", Path: "data/prompts/research.txt", TestPath: "../../data/prompts/research.txt"},
-	{Name: "images", Path: "data/prompts/images.txt", TestPath: "../../data/prompts/images.txt"},
-}
-
 func NewQuery(params QueryParams) *Query {
 	reporter := params.Reporter
 	if reporter == nil {
@@ -111,7 +115,9 @@ func NewQuery(params QueryParams) *Query {
 		Reporter:    reporter,
 		Approver:    params.Approver,
 		Analytics:   params.Analytics,
+		Audit:       params.Audit,
 		Scan:        params.Scan,
+		Taxonomy:    params.Taxonomy,
 		Plan:        make([]RenamePlanEntry, 0, len(params.Scan.Files)),
 	}
 }
@@ -144,7 +150,7 @@ func (p *SafeProcessor) Process() ([]ProcessResult, error) {
 			reporter.Errorf("Failed to process %s: %v", entry.File.OriginalName, err)
 		}
 		results = append(results, result)
-		if p.query.Analytics != nil && !p.query.DryRun {
+		if p.query.Analytics != nil && !p.query.DryRun && result.Status == PlanRename {
 			p.query.Analytics.RecordRenameResult(result.Success)
 		}
 	}
@@ -171,11 +177,12 @@ func (p *SafeProcessor) processEntry(entry RenamePlanEntry) (ProcessResult, erro
 		}, err
 	}
 
-	targetPath := p.destinationPath(entry)
-	if _, err := os.Stat(targetPath); err == nil {
-		targetPath = utils.GenerateUniqueFilename(targetPath)
+	if entry.Status != PlanRename {
+		p.reporter().Warnf("Not renaming %s, confidence %.2f is too low", entry.File.OriginalName, entry.Confidence)
+		return unrenamedResult(entry, sourcePath), nil
 	}
 
+	targetPath := p.targetPath(entry)
 	targetAbs, err := filepath.Abs(targetPath)
 	if err != nil {
 		return ProcessResult{OriginalPath: entry.File.SourcePath, Success: false, Error: err}, err
@@ -191,10 +198,14 @@ func (p *SafeProcessor) processEntry(entry RenamePlanEntry) (ProcessResult, erro
 
 	if !p.query.DryRun {
 		if !p.query.AutoApprove {
-			decision, approveErr := p.promptForRenameApproval(entry.File.OriginalName, filepath.Base(targetPath))
+			approved, decision, approveErr := p.approveEntry(entry)
 			if approveErr != nil {
 				return result, approveErr
 			}
+			if approved.Status != PlanRename {
+				p.reporter().Warnf("Not renaming %s, refined confidence %.2f is too low", entry.File.OriginalName, approved.Confidence)
+				return unrenamedResult(approved, sourcePath), nil
+			}
 			if decision == utils.ApprovalNo {
 				result.Success = false
 				result.Error = fmt.Errorf("rename not approved")
@@ -203,6 +214,17 @@ func (p *SafeProcessor) processEntry(entry RenamePlanEntry) (ProcessResult, erro
 			if decision == utils.ApprovalAll {
 				p.query.AutoApprove = true
 			}
+			if approved.SuggestedName != entry.SuggestedName || approved.Folder != entry.Folder {
+				entry = approved
+				targetPath = p.targetPath(entry)
+				if targetAbs, err = filepath.Abs(targetPath); err != nil {
+					result.Success = false
+					result.Error = err
+					return result, err
+				}
+				result.NewPath = targetPath
+				result.FullNewPath = targetAbs
+			}
 		}
 
 		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
@@ -225,6 +247,64 @@ func (p *SafeProcessor) processEntry(entry RenamePlanEntry) (ProcessResult, erro
 	return result, nil
 }
 
+// unrenamedResult reports an entry left in place because of its status.
+func unrenamedResult(entry RenamePlanEntry, sourcePath string) ProcessResult {
+	return ProcessResult{
+		OriginalPath:     entry.File.SourcePath,
+		NewPath:          entry.File.SourcePath,
+		FullOriginalPath: sourcePath,
+		FullNewPath:      sourcePath,
+		Status:           entry.Status,
+		SuggestedName:    entry.SuggestedName,
+		Confidence:       entry.Confidence,
+	}
+}
+
+func (p *SafeProcessor) targetPath(entry RenamePlanEntry) string {
+	targetPath := p.destinationPath(entry)
+	if _, err := os.Stat(targetPath); err == nil {
+		targetPath = utils.GenerateUniqueFilename(targetPath)
+	}
+	return targetPath
+}
+
+// approveEntry asks for approval until the user accepts, rejects or approves
+// everything, refining the suggestion with their feedback in between. A
+// refined suggestion scoring below ai.min_confidence ends the loop with its
+// status, so the caller keeps the file or sends it to review.
+func (p *SafeProcessor) approveEntry(entry RenamePlanEntry) (RenamePlanEntry, utils.ApprovalDecision, error) {
+	var history []Refinement
+	for {
+		decision, err := p.promptForRenameApproval(entry.File.OriginalName, filepath.Base(p.targetPath(entry)))
+		if err != nil || decision != utils.ApprovalRefine {
+			return entry, decision, err
+		}
+
+		collector, ok := p.query.Approver.(utils.FeedbackCollector)
+		if !ok || p.query.Refine == nil {
+			p.reporter().Warnf("Refining is not available for this run")
+			continue
+		}
+
+		feedback, err := collector.Feedback(entry.File.OriginalName, entry.SuggestedName)
+		if err != nil {
+			return entry, utils.ApprovalNo, err
+		}
+
+		round := Refinement{Suggestion: entry.SuggestedName, Folder: entry.Folder, Feedback: feedback}
+		refined, err := p.query.Refine(entry, append(history, round))
+		if err != nil {
+			p.reporter().Warnf("Could not refine %s: %v", entry.File.OriginalName, err)
+			continue
+		}
+		history = append(history, round)
+		entry = refined
+		if entry.Status != PlanRename {
+			return entry, utils.ApprovalNo, nil
+		}
+	}
+}
+
 func (p *SafeProcessor) destinationPath(entry RenamePlanEntry) string {
 	relativeDir := filepath.Dir(entry.File.RelativePath)
 	if relativeDir == "." {
@@ -232,7 +312,11 @@ func (p *SafeProcessor) destinationPath(entry RenamePlanEntry) string {
 	}
 
 	if p.query.Organize {
-		return filepath.Join(p.output, entry.File.Category, relativeDir, entry.SuggestedName)
+		folder := entry.File.Category
+		if entry.Folder != "" {
+			folder = entry.Folder
+		}
+		return filepath.Join(p.output, folder, relativeDir, entry.SuggestedName)
 	}
 	return filepath.Join(p.output, relativeDir, entry.SuggestedName)
 }
@@ -270,46 +354,3 @@ func (p *SafeProcessor) reporter() utils.Reporter {
 	}
 	return utils.NopReporter{}
 }
-
-func categoryForFile(fileName string) string {
-	ext := filepath.Ext(fileName)
-	for _, category := range defaultCategories {
-		if slices.Contains(category.Extensions, ext) {
-			return category.Name
-		}
-	}
-	return "Others"
-}
-
-func ResolvePrompt(prompt string, config utils.Config) (string, error) {
-	trimmedPrompt := strings.TrimSpace(prompt)
-	if trimmedPrompt == "" {
-		if strings.TrimSpace(config.AI.Prompt) != "" {
-			return config.AI.Prompt, nil
-		}
-		return defaultPrompt, nil
-	}
-
-	switch strings.ToLower(trimmedPrompt) {
-	case "research":
-		return readPromptFile(NomNomPrompts[0], defaultPrompt)
-	case "images":
-		return readPromptFile(NomNomPrompts[1], defaultPrompt)
-	default:
-		return trimmedPrompt, nil
-	}
-}
-
-func readPromptFile(promptFile Prompts, fallback string) (string, error) {
-	content, err := os.ReadFile(promptFile.Path)
-	if err == nil {
-		return string(content), nil
-	}
-
-	content, testErr := os.ReadFile(promptFile.TestPath)
-	if testErr == nil {
-		return string(content), nil
-	}
-
-	return fallback, fmt.Errorf("failed to read prompt %q from both paths: %w", promptFile.Name, err)
-}
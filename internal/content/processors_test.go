@@ -178,38 +178,184 @@ func TestCopyFile(t *testing.T) {
 	}
 }
 
-func TestResolvePrompt(t *testing.T) {
-	imagePrompt, err := os.ReadFile(NomNomPrompts[1].TestPath)
+func TestSafeProcessorProcessTaxonomyFolder(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "output")
+	sourcePath := filepath.Join(tmpDir, "scan.pdf")
+	if err := os.WriteFile(sourcePath, []byte("invoice"), 0644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+
+	query := &Query{
+		Organize:    true,
+		AutoApprove: true,
+		Plan: []RenamePlanEntry{
+			{
+				File: ScannedFile{
+					SourcePath:   sourcePath,
+					RelativePath: "scan.pdf",
+					OriginalName: "scan.pdf",
+					Category:     "Documents",
+				},
+				SuggestedName: "acme_invoice.pdf",
+				Folder:        "Invoices",
+			},
+		},
+		Reporter: utils.NopReporter{},
+	}
+
+	results, err := NewSafeProcessor(query, outputDir).Process()
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "Invoices", "acme_invoice.pdf")); err != nil {
+		t.Fatalf("expected taxonomy output file to exist: %v", err)
+	}
+}
+
+type refiningApprover struct {
+	decisions []utils.ApprovalDecision
+	feedback  string
+	seen      []string
+}
+
+func (a *refiningApprover) Approve(_, _, newName string) (utils.ApprovalDecision, error) {
+	a.seen = append(a.seen, newName)
+	decision := a.decisions[0]
+	a.decisions = a.decisions[1:]
+	return decision, nil
+}
+
+func (a *refiningApprover) Feedback(string, string) (string, error) {
+	return a.feedback, nil
+}
+
+func TestSafeProcessorRefinesSuggestion(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "output")
+	sourcePath := filepath.Join(tmpDir, "scan.pdf")
+	if err := os.WriteFile(sourcePath, []byte("invoice"), 0644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+
+	approver := &refiningApprover{
+		decisions: []utils.ApprovalDecision{utils.ApprovalRefine, utils.ApprovalYes},
+		feedback:  "include the client name",
+	}
+	var history []Refinement
+	query := &Query{
+		Approver: approver,
+		Refine: func(entry RenamePlanEntry, rounds []Refinement) (RenamePlanEntry, error) {
+			history = rounds
+			entry.SuggestedName = "acme_invoice.pdf"
+			return entry, nil
+		},
+		Plan: []RenamePlanEntry{
+			{
+				File:          ScannedFile{SourcePath: sourcePath, RelativePath: "scan.pdf", OriginalName: "scan.pdf"},
+				SuggestedName: "invoice.pdf",
+			},
+		},
+		Reporter: utils.NopReporter{},
+	}
+
+	results, err := NewSafeProcessor(query, outputDir).Process()
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+
+	if len(history) != 1 || history[0].Suggestion != "invoice.pdf" || history[0].Feedback != "include the client name" {
+		t.Fatalf("refine history = %#v", history)
+	}
+	if approver.seen[1] != "acme_invoice.pdf" {
+		t.Fatalf("second approval shown %q, want refined name", approver.seen[1])
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "acme_invoice.pdf")); err != nil {
+		t.Fatalf("expected refined output file to exist: %v", err)
+	}
+}
+
+func TestSafeProcessorKeepsLowConfidenceRefinement(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "output")
+	sourcePath := filepath.Join(tmpDir, "scan.pdf")
+	if err := os.WriteFile(sourcePath, []byte("invoice"), 0644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+
+	approver := &refiningApprover{
+		decisions: []utils.ApprovalDecision{utils.ApprovalRefine, utils.ApprovalYes},
+		feedback:  "include the client name",
+	}
+	query := &Query{
+		Approver: approver,
+		Refine: func(entry RenamePlanEntry, _ []Refinement) (RenamePlanEntry, error) {
+			entry.SuggestedName = "acme_invoice.pdf"
+			entry.Confidence = 0.2
+			entry.Status = PlanNeedsReview
+			return entry, nil
+		},
+		Plan: []RenamePlanEntry{
+			{
+				File:          ScannedFile{SourcePath: sourcePath, RelativePath: "scan.pdf", OriginalName: "scan.pdf"},
+				SuggestedName: "invoice.pdf",
+				Confidence:    0.9,
+			},
+		},
+		Reporter: utils.NopReporter{},
+	}
+
+	results, err := NewSafeProcessor(query, outputDir).Process()
 	if err != nil {
-		t.Fatalf("failed to read image prompt: %v", err)
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Status != PlanNeedsReview || results[0].SuggestedName != "acme_invoice.pdf" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if len(approver.seen) != 1 {
+		t.Fatalf("approvals shown = %v, want no approval for the low-confidence refinement", approver.seen)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "acme_invoice.pdf")); !os.IsNotExist(err) {
+		t.Fatalf("refined output file exists or stat failed: %v", err)
+	}
+}
+
+func TestSafeProcessorSkipsLowConfidence(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "output")
+	sourcePath := filepath.Join(tmpDir, "scan.pdf")
+	if err := os.WriteFile(sourcePath, []byte("?"), 0644); err != nil {
+		t.Fatalf("failed to create source file: %v", err)
+	}
+
+	query := &Query{
+		AutoApprove: true,
+		Plan: []RenamePlanEntry{
+			{
+				File:          ScannedFile{SourcePath: sourcePath, RelativePath: "scan.pdf", OriginalName: "scan.pdf"},
+				SuggestedName: "mystery_report.pdf",
+				Confidence:    0.3,
+				Status:        PlanNeedsReview,
+			},
+		},
+		Reporter: utils.NopReporter{},
 	}
-	researchPrompt, err := os.ReadFile(NomNomPrompts[0].TestPath)
+
+	results, err := NewSafeProcessor(query, outputDir).Process()
 	if err != nil {
-		t.Fatalf("failed to read research prompt: %v", err)
-	}
-
-	tests := []struct {
-		name     string
-		prompt   string
-		config   utils.Config
-		expected string
-	}{
-		{name: "Default prompt", prompt: "", config: utils.Config{}, expected: defaultPrompt},
-		{name: "Images prompt", prompt: "images", config: utils.Config{}, expected: string(imagePrompt)},
-		{name: "Research prompt", prompt: "research", config: utils.Config{}, expected: string(researchPrompt)},
-		{name: "Config prompt", prompt: "", config: utils.Config{AI: utils.AIConfig{Prompt: "Custom prompt from config"}}, expected: "Custom prompt from config"},
-		{name: "Custom prompt", prompt: "Custom prompt", config: utils.Config{}, expected: "Custom prompt"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result, err := ResolvePrompt(tt.prompt, tt.config)
-			if err != nil {
-				t.Fatalf("ResolvePrompt() error = %v", err)
-			}
-			if result != tt.expected {
-				t.Fatalf("ResolvePrompt() = %q, want %q", result, tt.expected)
-			}
-		})
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Status != PlanNeedsReview || results[0].SuggestedName != "mystery_report.pdf" {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "mystery_report.pdf")); !os.IsNotExist(err) {
+		t.Fatalf("expected low-confidence file not to be copied, stat error = %v", err)
 	}
 }
@@ -0,0 +1,221 @@
+package content
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+
+	fileutils "nomnom/internal/files"
+	utils "nomnom/internal/utils"
+)
+
+// detector finds one kind of sensitive value. When group is set only that
+// submatch is replaced, so "api_key = abc" keeps its label.
+type detector struct {
+	label   string
+	pattern *regexp.Regexp
+	group   int
+	valid   func(string) bool
+	// notAfter skips matches whose preceding text it matches, such as a label
+	// naming the value as something else.
+	notAfter *regexp.Regexp
+}
+
+// builtinDetectors run in order, so specific formats claim their matches before the looser phone pattern.
+var builtinDetectors = []detector{
+	{
+		label:   "api_key",
+		pattern: regexp.MustCompile(`\b(?:sk-[A-Za-z0-9_-]{20,}|AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36,}|xox[abpr]-[A-Za-z0-9-]{10,}|AIza[0-9A-Za-z_-]{35})`),
+	},
+	{
+		label:   "api_key",
+		pattern: regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret|token|password|passwd)\b["']?\s*[:=]\s*["']?([^\s"',;]{8,})`),
+		group:   1,
+	},
+	{
+		label:   "email",
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	{
+		label:   "iban",
+		pattern: regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+		valid:   validIBAN,
+	},
+	{
+		label:   "credit_card",
+		pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		valid:   validLuhn,
+	},
+	{
+		// Numbers need a leading + or a North American grouping; bare digit
+		// runs are more often sizes, invoice numbers or identifiers.
+		label:    "phone",
+		pattern:  regexp.MustCompile(`\+\d[\d ().-]{7,}\d\b|\(\d{3}\) ?\d{3}[ .-]\d{4}\b|\b\d{3}-\d{3}-\d{4}\b|\b\d{3}\.\d{3}\.\d{4}\b`),
+		valid:    validPhone,
+		notAfter: regexp.MustCompile(`(?i)\b(?:isbn(?:-1[03])?|arxiv|doi|size)\s*[:=#]?\s*$`),
+	},
+}
+
+// Redactor scrubs sensitive values from file context before it leaves the machine.
+type Redactor struct {
+	detectors []detector
+}
+
+// Redaction counts the values scrubbed from a single file, keyed by detector label.
+type Redaction struct {
+	File   string
+	Counts map[string]int
+}
+
+// NewRedactor builds a redactor from the built-in detectors not listed in
+// config.Skip followed by the user's own patterns.
+func NewRedactor(config utils.RedactionConfig) (*Redactor, error) {
+	redactor := &Redactor{}
+	for _, builtin := range builtinDetectors {
+		if !slices.Contains(config.Skip, builtin.label) {
+			redactor.detectors = append(redactor.detectors, builtin)
+		}
+	}
+
+	labels := make([]string, 0, len(config.Patterns))
+	for label := range config.Patterns {
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+	for _, label := range labels {
+		pattern, err := regexp.Compile(config.Patterns[label])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", label, err)
+		}
+		redactor.detectors = append(redactor.detectors, detector{label: label, pattern: pattern})
+	}
+
+	return redactor, nil
+}
+
+// Redact replaces every detected value in text with a [REDACTED:label] marker.
+func (r *Redactor) Redact(text string) (string, map[string]int) {
+	counts := make(map[string]int)
+	for _, d := range r.detectors {
+		text = d.replace(text, counts)
+	}
+	return text, counts
+}
+
+// RedactFiles returns copies of files with their context redacted, and a report
+// entry for every file where something was found.
+func (r *Redactor) RedactFiles(files []ScannedFile) ([]ScannedFile, []Redaction) {
+	redacted := make([]ScannedFile, len(files))
+	var report []Redaction
+	for index, file := range files {
+		context, counts := r.Redact(file.Context)
+		file.Context = context
+		redacted[index] = file
+		if len(counts) > 0 {
+			report = append(report, Redaction{File: file.RelativePath, Counts: counts})
+		}
+	}
+	return redacted, report
+}
+
+// Summary lists the redacted labels with their counts, e.g. "2 email, 1 api_key".
+func (r Redaction) Summary() string {
+	labels := make([]string, 0, len(r.Counts))
+	for label := range r.Counts {
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		parts = append(parts, fmt.Sprintf("%d %s", r.Counts[label], label))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (d detector) replace(text string, counts map[string]int) string {
+	marker := "[REDACTED:" + d.label + "]"
+	matches := d.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[2*d.group], match[2*d.group+1]
+		if start < 0 || (d.valid != nil && !d.valid(text[start:end])) {
+			continue
+		}
+		if d.notAfter != nil && d.notAfter.MatchString(text[max(0, start-32):start]) {
+			continue
+		}
+		builder.WriteString(text[last:start])
+		builder.WriteString(marker)
+		last = end
+		counts[d.label]++
+	}
+	builder.WriteString(text[last:])
+	return builder.String()
+}
+
+func digitsOf(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+// validLuhn checks card numbers with the Luhn checksum.
+func validLuhn(value string) bool {
+	digits := digitsOf(value)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for index := len(digits) - 1; index >= 0; index-- {
+		digit := int(digits[index] - '0')
+		if (len(digits)-index)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// validIBAN checks the ISO 13616 mod-97 checksum.
+func validIBAN(value string) bool {
+	compact := strings.ReplaceAll(value, " ", "")
+	if len(compact) < 15 || len(compact) > 34 {
+		return false
+	}
+
+	var numeric strings.Builder
+	for _, r := range compact[4:] + compact[:4] {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&numeric, "%d", r-'A'+10)
+			continue
+		}
+		numeric.WriteRune(r)
+	}
+
+	number, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(number, big.NewInt(97)).Int64() == 1
+}
+
+var isoDate = regexp.MustCompile(`\d{4}[-.]\d{2}[-.]\d{2}`)
+
+// validPhone accepts 9 to 15 digits and leaves dates, short numbers and
+// ISBNs alone.
+func validPhone(value string) bool {
+	digits := len(digitsOf(value))
+	return digits >= 9 && digits <= 15 && !isoDate.MatchString(value) && !fileutils.IsISBN13(value)
+}
@@ -0,0 +1,97 @@
+package content
+
+import (
+	"strings"
+	"testing"
+
+	utils "nomnom/internal/utils"
+)
+
+func TestRedactorRedact(t *testing.T) {
+	redactor, err := NewRedactor(utils.RedactionConfig{})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	text := strings.Join([]string{
+		"Contact: jane.doe@example.com or +44 20 7946 0958",
+		`{"api_key": "abcd1234efgh5678"}`,
+		"IBAN: DE89 3704 0044 0532 0130 00",
+		"Card 4111 1111 1111 1111",
+		"Invoice date 2024-03-15, total 1200",
+	}, "\n")
+
+	redacted, counts := redactor.Redact(text)
+	for _, secret := range []string{"jane.doe@example.com", "7946", "abcd1234efgh5678", "DE89", "4111"} {
+		if strings.Contains(redacted, secret) {
+			t.Fatalf("redacted text still contains %q:\n%s", secret, redacted)
+		}
+	}
+	if !strings.Contains(redacted, `"api_key": "[REDACTED:api_key]"`) || !strings.Contains(redacted, "2024-03-15") {
+		t.Fatalf("unexpected redacted text:\n%s", redacted)
+	}
+
+	want := map[string]int{"email": 1, "phone": 1, "api_key": 1, "iban": 1, "credit_card": 1}
+	for label, count := range want {
+		if counts[label] != count {
+			t.Fatalf("counts[%q] = %d, want %d (all counts %v)", label, counts[label], count, counts)
+		}
+	}
+}
+
+func TestRedactorSkipAndPatterns(t *testing.T) {
+	redactor, err := NewRedactor(utils.RedactionConfig{
+		Skip:     []string{"email"},
+		Patterns: map[string]string{"customer_id": `CUST-\d{6}`},
+	})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	files, report := redactor.RedactFiles([]ScannedFile{
+		{RelativePath: "a.txt", Context: "CUST-123456 wrote from jane@example.com"},
+		{RelativePath: "b.txt", Context: "nothing to see"},
+	})
+	if files[0].Context != "[REDACTED:customer_id] wrote from jane@example.com" {
+		t.Fatalf("unexpected context %q", files[0].Context)
+	}
+	if len(report) != 1 || report[0].File != "a.txt" || report[0].Summary() != "1 customer_id" {
+		t.Fatalf("unexpected report %#v", report)
+	}
+
+	if _, err := NewRedactor(utils.RedactionConfig{Patterns: map[string]string{"bad": "("}}); err == nil {
+		t.Fatal("NewRedactor() error = nil, want invalid pattern error")
+	}
+}
+
+func TestRedactorPhoneNumbers(t *testing.T) {
+	redactor, err := NewRedactor(utils.RedactionConfig{})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	tests := []struct {
+		text   string
+		redact bool
+	}{
+		{text: "ISBN: 978-3-16-148410-0", redact: false},
+		{text: "arXiv: 2301.01234", redact: false},
+		{text: "DOI: 10.1000/182", redact: false},
+		{text: "Size: 123456789 bytes", redact: false},
+		{text: "Invoice number 20230415001", redact: false},
+		{text: "Total uncompressed size: 1234567890", redact: false},
+		{text: "Size: +1 555 123 4567", redact: false},
+		{text: "Book +978 3 16 148410 0", redact: false},
+		{text: "Call +44 20 7946 0958", redact: true},
+		{text: "Call +1 (555) 123-4567", redact: true},
+		{text: "Call (555) 123-4567", redact: true},
+		{text: "Call 555-123-4567", redact: true},
+		{text: "Call 555.123.4567", redact: true},
+	}
+	for _, tt := range tests {
+		redacted, counts := redactor.Redact(tt.text)
+		if got := counts["phone"] == 1; got != tt.redact || !tt.redact && redacted != tt.text {
+			t.Errorf("Redact(%q) = %q, want phone redacted %v", tt.text, redacted, tt.redact)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+// Package eval scores generated names against a dataset of files with known
+// good names, so prompt and model changes can be compared.
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	fileutils "nomnom/internal/files"
+	utils "nomnom/internal/utils"
+)
+
+// Dataset is the cases.json file passed to nomnom eval.
+type Dataset struct {
+	// Dir holds the case files, relative to the dataset file. Defaults to the dataset's directory.
+	Dir      string    `json:"dir,omitempty"`
+	Variants []Variant `json:"variants,omitempty"` // Provider, model and prompt combinations to compare
+	Policy   Policy    `json:"policy,omitempty"`   // Extra naming rules every name is checked against
+	Cases    []Case    `json:"cases"`
+
+	path string
+}
+
+// Case is one file and the names that count as correct for it.
+type Case struct {
+	File     string   `json:"file"`     // Path relative to the dataset directory
+	Expected []string `json:"expected"` // Acceptable names, with or without the extension
+}
+
+// Variant overrides the configured provider, model or prompt for one evaluation run.
+// Empty fields keep the config value.
+type Variant struct {
+	Name     string `json:"name,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Prompt   string `json:"prompt,omitempty"` // Prompt name or prompt text
+}
+
+// Policy adds dataset specific rules to the compliance check.
+type Policy struct {
+	Pattern   string `json:"pattern,omitempty"`    // Regular expression the name without extension must match
+	MaxLength int    `json:"max_length,omitempty"` // Maximum length of the full name
+}
+
+// LoadDataset reads and validates a dataset file.
+func LoadDataset(path string) (Dataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Dataset{}, fmt.Errorf("failed to read dataset: %w", err)
+	}
+
+	var dataset Dataset
+	if err := json.Unmarshal(data, &dataset); err != nil {
+		return Dataset{}, fmt.Errorf("failed to parse dataset: %w", err)
+	}
+	if len(dataset.Cases) == 0 {
+		return Dataset{}, fmt.Errorf("dataset %s has no cases", path)
+	}
+
+	seen := make(map[string]bool, len(dataset.Cases))
+	for index, item := range dataset.Cases {
+		if item.File == "" {
+			return Dataset{}, fmt.Errorf("case %d has no file", index+1)
+		}
+		if len(item.Expected) == 0 {
+			return Dataset{}, fmt.Errorf("case %s has no expected names", item.File)
+		}
+		file := filepath.Clean(item.File)
+		if seen[file] {
+			return Dataset{}, fmt.Errorf("case %s is listed twice", item.File)
+		}
+		seen[file] = true
+		dataset.Cases[index].File = file
+	}
+	if dataset.Policy.Pattern != "" {
+		if _, err := regexp.Compile(dataset.Policy.Pattern); err != nil {
+			return Dataset{}, fmt.Errorf("invalid policy pattern: %w", err)
+		}
+	}
+
+	dataset.path = path
+	return dataset, nil
+}
+
+// Root returns the directory the case files are relative to.
+func (d Dataset) Root() string {
+	base := filepath.Dir(d.path)
+	if d.Dir == "" {
+		return base
+	}
+	if filepath.IsAbs(d.Dir) {
+		return d.Dir
+	}
+	return filepath.Join(base, d.Dir)
+}
+
+// Case returns the case for a file relative to Root.
+func (d Dataset) Case(file string) (Case, bool) {
+	file = filepath.Clean(file)
+	for _, item := range d.Cases {
+		if item.File == file {
+			return item, true
+		}
+	}
+	return Case{}, false
+}
+
+// Output is what one variant produced for one case. It is saved so runs can be
+// scored again later without contacting the provider.
+type Output struct {
+	File       string  `json:"file"`
+	Name       string  `json:"name,omitempty"`
+	Folder     string  `json:"folder,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+	Status     string  `json:"status,omitempty"`
+	Error      string  `json:"error,omitempty"`
+
+	Exact      bool     `json:"exact"`
+	Overlap    float64  `json:"overlap"`
+	Violations []string `json:"violations,omitempty"`
+}
+
+// Run is every output of one variant.
+type Run struct {
+	Variant  Variant `json:"variant"`
+	Provider string  `json:"provider"` // Resolved provider
+	Model    string  `json:"model"`    // Resolved model
+	Prompt   string  `json:"prompt"`   // Resolved prompt text
+	Case     string  `json:"case"`     // Naming case the outputs were checked against
+	ASCII    bool    `json:"ascii"`
+	Seconds  float64 `json:"seconds"`
+
+	Outputs []Output `json:"outputs"`
+	Summary Summary  `json:"summary"`
+}
+
+// Summary aggregates the scores of a run. Rates are between 0 and 1.
+type Summary struct {
+	Cases      int     `json:"cases"`
+	Named      int     `json:"named"`
+	ExactMatch float64 `json:"exact_match"`
+	Overlap    float64 `json:"token_overlap"`
+	Compliance float64 `json:"policy_compliance"`
+}
+
+// Results is the file written by nomnom eval.
+type Results struct {
+	Dataset   string    `json:"dataset"`
+	CreatedAt time.Time `json:"created_at"`
+	Runs      []Run     `json:"runs"`
+}
+
+// Label names a run in reports.
+func (r Run) Label() string {
+	if r.Variant.Name != "" {
+		return r.Variant.Name
+	}
+	label := r.Provider
+	if r.Model != "" {
+		label += "/" + r.Model
+	}
+	if r.Variant.Prompt != "" {
+		label += " " + firstWords(r.Variant.Prompt, 6)
+	}
+	return label
+}
+
+// Score fills the per-output scores and the summary of run against dataset.
+// Outputs for files that are no longer in the dataset are dropped.
+func Score(dataset Dataset, run Run) Run {
+	var pattern *regexp.Regexp
+	if dataset.Policy.Pattern != "" {
+		pattern = regexp.MustCompile(dataset.Policy.Pattern)
+	}
+
+	scored := make([]Output, 0, len(run.Outputs))
+	summary := Summary{}
+	for _, output := range run.Outputs {
+		item, ok := dataset.Case(output.File)
+		if !ok {
+			continue
+		}
+
+		output.Exact, output.Overlap, output.Violations = false, 0, nil
+		if output.Name != "" {
+			summary.Named++
+			output.Exact = ExactMatch(output.Name, item)
+			output.Overlap = TokenOverlap(output.Name, item)
+			output.Violations = Violations(output.Name, item.File, run.Case, run.ASCII, dataset.Policy, pattern)
+		} else {
+			output.Violations = []string{"no name was produced"}
+		}
+
+		summary.Cases++
+		if output.Exact {
+			summary.ExactMatch++
+		}
+		summary.Overlap += output.Overlap
+		if len(output.Violations) == 0 {
+			summary.Compliance++
+		}
+		scored = append(scored, output)
+	}
+
+	if summary.Cases > 0 {
+		cases := float64(summary.Cases)
+		summary.ExactMatch /= cases
+		summary.Overlap /= cases
+		summary.Compliance /= cases
+	}
+
+	run.Outputs = scored
+	run.Summary = summary
+	return run
+}
+
+// ExactMatch reports whether name equals one of the expected names, ignoring
+// letter case. Expected names without an extension get the case file's one.
+func ExactMatch(name string, item Case) bool {
+	for _, expected := range item.Expected {
+		if strings.EqualFold(name, withExtension(expected, item.File)) {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenOverlap is the best F1 score between the words of name and the words of
+// an expected name. The extension is not counted.
+func TokenOverlap(name string, item Case) float64 {
+	got := tokens(stem(name))
+	best := 0.0
+	for _, expected := range item.Expected {
+		want := tokens(stem(withExtension(expected, item.File)))
+		best = max(best, f1(got, want))
+	}
+	return best
+}
+
+// Violations lists the naming rules name breaks: a valid filename with the
+// original extension, the configured case, ASCII when naming.ascii is set and
+// the dataset policy.
+func Violations(name, original, namingCase string, ascii bool, policy Policy, pattern *regexp.Regexp) []string {
+	var violations []string
+	if valid, reason := fileutils.IsAValidFileName(name); !valid {
+		violations = append(violations, reason)
+	}
+	if !strings.EqualFold(filepath.Ext(name), filepath.Ext(original)) {
+		violations = append(violations, fmt.Sprintf("extension changed from %q to %q", filepath.Ext(original), filepath.Ext(name)))
+	}
+
+	base := stem(name)
+	if namingCase == "" {
+		namingCase = "snake"
+	}
+	if !inCase(base, namingCase) {
+		violations = append(violations, fmt.Sprintf("not in %s case", namingCase))
+	}
+	if ascii && !utils.IsASCII(name) {
+		violations = append(violations, "contains non-ASCII characters")
+	}
+	if policy.MaxLength > 0 && len(name) > policy.MaxLength {
+		violations = append(violations, fmt.Sprintf("longer than %d characters", policy.MaxLength))
+	}
+	if pattern != nil && !pattern.MatchString(base) {
+		violations = append(violations, fmt.Sprintf("does not match %s", pattern))
+	}
+	return violations
+}
+
+// Save writes results as indented JSON, creating the parent directory.
+func Save(path string, results Results) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create results directory: %w", err)
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
+
+// Load reads results written by Save.
+func Load(path string) (Results, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Results{}, fmt.Errorf("failed to read results: %w", err)
+	}
+	var results Results
+	if err := json.Unmarshal(data, &results); err != nil {
+		return Results{}, fmt.Errorf("failed to parse results %s: %w", path, err)
+	}
+	return results, nil
+}
+
+// ResultsPath returns where a run started at now is saved by default.
+func ResultsPath(dataset Dataset, now time.Time) string {
+	return filepath.Join(filepath.Dir(dataset.path), ".nomnom", "eval", fmt.Sprintf("%d.json", now.UnixNano()))
+}
+
+// inCase reports whether converting base to namingCase leaves it unchanged.
+func inCase(base, namingCase string) bool {
+	switch namingCase {
+	case "snake", "kebab", "pascal", "camel":
+	default:
+		return true
+	}
+	if base == "" {
+		return false
+	}
+	return utils.ConvertCase(base, namingCase, namingCase) == base
+}
+
+func withExtension(name, reference string) string {
+	if filepath.Ext(name) == "" {
+		return name + filepath.Ext(reference)
+	}
+	return name
+}
+
+func stem(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// tokens splits a name into lowercase words on separators, camel case humps
+// and letter to digit changes.
+func tokens(name string) []string {
+	var words []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, strings.ToLower(current.String()))
+			current.Reset()
+		}
+	}
+
+	var previous rune
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && unicode.IsLower(previous),
+			unicode.IsDigit(r) != unicode.IsDigit(previous) && current.Len() > 0:
+			flush()
+			current.WriteRune(r)
+		default:
+			current.WriteRune(r)
+		}
+		previous = r
+	}
+	flush()
+	return words
+}
+
+// f1 scores two token lists by the harmonic mean of precision and recall, counting repeats.
+func f1(got, want []string) float64 {
+	if len(got) == 0 || len(want) == 0 {
+		return 0
+	}
+
+	remaining := make(map[string]int, len(want))
+	for _, word := range want {
+		remaining[word]++
+	}
+	common := 0
+	for _, word := range got {
+		if remaining[word] > 0 {
+			remaining[word]--
+			common++
+		}
+	}
+	if common == 0 {
+		return 0
+	}
+
+	precision := float64(common) / float64(len(got))
+	recall := float64(common) / float64(len(want))
+	return 2 * precision * recall / (precision + recall)
+}
+
+func firstWords(text string, count int) string {
+	words := strings.Fields(text)
+	if len(words) <= count {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:count], " ") + "..."
+}
@@ -0,0 +1,91 @@
+package eval
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScoreFunctions(t *testing.T) {
+	item := Case{File: "scans/invoice.pdf", Expected: []string{"acme_invoice_2024_03", "acme_bill_march_2024.pdf"}}
+
+	if !ExactMatch("Acme_Invoice_2024_03.pdf", item) {
+		t.Fatal("ExactMatch() = false, want match ignoring letter case and missing extension")
+	}
+	if ExactMatch("acme_invoice_2024_03.txt", item) {
+		t.Fatal("ExactMatch() = true for a different extension")
+	}
+
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{name: "acme_invoice_2024_03.pdf", want: 1},
+		{name: "acmeInvoice2024.pdf", want: 2 * 0.75 * 1 / 1.75},
+		{name: "receipt.pdf", want: 0},
+	}
+	for _, tt := range tests {
+		if got := TokenOverlap(tt.name, item); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("TokenOverlap(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestViolations(t *testing.T) {
+	if violations := Violations("acme_invoice.pdf", "scan.pdf", "snake", true, Policy{}, nil); len(violations) != 0 {
+		t.Fatalf("Violations() = %v, want none", violations)
+	}
+
+	violations := Violations("Acme-Invoicé.txt", "scan.pdf", "snake", true, Policy{MaxLength: 10}, nil)
+	if len(violations) != 4 {
+		t.Fatalf("Violations() = %v, want extension, case, ASCII and length violations", violations)
+	}
+}
+
+func TestScoreAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cases.json")
+	if err := os.WriteFile(path, []byte(`{
+  "dir": "files",
+  "policy": {"pattern": "^[a-z]+_"},
+  "cases": [
+    {"file": "a.txt", "expected": ["alpha_notes"]},
+    {"file": "b.txt", "expected": ["beta_report"]}
+  ]
+}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	dataset, err := LoadDataset(path)
+	if err != nil {
+		t.Fatalf("LoadDataset() error = %v", err)
+	}
+	if dataset.Root() != filepath.Join(dir, "files") {
+		t.Fatalf("Root() = %q", dataset.Root())
+	}
+
+	run := Score(dataset, Run{Provider: "metadata", Case: "snake", Outputs: []Output{
+		{File: "a.txt", Name: "alpha_notes.txt"},
+		{File: "b.txt"},
+		{File: "removed.txt", Name: "gone.txt"},
+	}})
+	if run.Summary.Cases != 2 || run.Summary.Named != 1 {
+		t.Fatalf("Summary = %+v, want 2 cases with 1 named", run.Summary)
+	}
+	if run.Summary.ExactMatch != 0.5 || run.Summary.Overlap != 0.5 || run.Summary.Compliance != 0.5 {
+		t.Fatalf("Summary = %+v, want every rate at 0.5", run.Summary)
+	}
+
+	resultsPath := filepath.Join(dir, "results.json")
+	if err := Save(resultsPath, Results{Dataset: path, Runs: []Run{run}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(resultsPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Runs) != 1 || len(loaded.Runs[0].Outputs) != 2 {
+		t.Fatalf("Load() = %+v, want the saved run", loaded)
+	}
+}
@@ -0,0 +1,324 @@
+package files
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"cmp"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// maxArchiveEntries caps the entries read from an archive.
+	maxArchiveEntries = 20000
+	// maxListedEntries is how many entry paths are listed.
+	maxListedEntries = 30
+	// maxArchiveGroups caps the top-level names and extensions listed.
+	maxArchiveGroups = 15
+	// maxReadmeSize is how much of a README inside an archive is read.
+	maxReadmeSize = 4 << 10
+	// maxCompressedRead caps the compressed bytes read from a tarball, so
+	// listing a huge backup stays quick.
+	maxCompressedRead = 512 << 20
+	// maxSingleFileRead caps the bytes decompressed to size a gzip or bzip2
+	// file holding a single file; larger files are reported as at least this.
+	maxSingleFileRead = 16 << 20
+)
+
+// archiveListing is what reading through an archive found.
+type archiveListing struct {
+	kind       string
+	files      int
+	folders    int
+	total      int64
+	paths      []string
+	topLevel   map[string]int // Entries under each first path component, folders ending in "/"
+	extensions map[string]int
+	first      time.Time
+	last       time.Time
+	readme     string // Path of the README whose text was read
+	readmeText string
+	partial    string // Why the listing stopped early
+	atLeast    bool   // Sizes are lower bounds
+}
+
+func newArchiveListing(kind string) *archiveListing {
+	return &archiveListing{kind: kind, topLevel: make(map[string]int), extensions: make(map[string]int)}
+}
+
+func extractArchive(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error) {
+	if !fileType.Binary {
+		// Named like an archive but holding text.
+		return extractGeneric(path, fileType, opts)
+	}
+
+	var listing *archiveListing
+	var err error
+	switch fileType.MIME {
+	case "application/zip", "application/java-archive":
+		listing, err = listZip(path, fileType.Description, opts.ExtractText)
+	case "application/x-tar", "application/gzip", "application/x-bzip2":
+		listing, err = listTarball(path, fileType, opts.ExtractText)
+	default:
+		content := binaryContent(fileType)
+		content.Text += "\nThe entries of this archive cannot be listed; only zip, tar, gzip and bzip2 archives are read."
+		return content, nil
+	}
+	if err != nil {
+		content := binaryContent(fileType)
+		content.Text += fmt.Sprintf("\nThe archive could not be read: %v", err)
+		return content, nil
+	}
+
+	return ExtractedContent{
+		Text:   strings.Join(listing.describe(), "\n"),
+		Sparse: listing.files == 0 && listing.readmeText == "",
+	}, nil
+}
+
+func listZip(path, description string, withReadme bool) (*archiveListing, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	listing := newArchiveListing(description)
+	var readme *zip.File
+	for index, file := range archive.File {
+		if index == maxArchiveEntries {
+			listing.partial = fmt.Sprintf("only the first %d entries were read", maxArchiveEntries)
+			break
+		}
+		info := file.FileInfo()
+		listing.add(file.Name, info.IsDir(), int64(file.UncompressedSize64), file.Modified)
+		if withReadme && !info.IsDir() && isReadme(file.Name) && (readme == nil || depth(file.Name) < depth(readme.Name)) {
+			readme = file
+		}
+	}
+
+	if readme != nil {
+		if reader, err := readme.Open(); err == nil {
+			listing.setReadme(readme.Name, reader)
+			reader.Close()
+		}
+	}
+	return listing, nil
+}
+
+// listTarball reads a tar file, compressed or not. A gzip or bzip2 file that
+// holds a single file rather than a tar is described by that file.
+func listTarball(path string, fileType FileType, withReadme bool) (*archiveListing, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	limited := &io.LimitedReader{R: file, N: maxCompressedRead}
+	var stream io.Reader = bufio.NewReader(limited)
+	kind := "tar archive"
+	var gzipName string
+	var gzipTime time.Time
+	switch fileType.MIME {
+	case "application/gzip":
+		reader, err := gzip.NewReader(stream)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		stream, kind = reader, "gzip-compressed tar archive"
+		gzipName, gzipTime = reader.Name, reader.ModTime
+	case "application/x-bzip2":
+		stream, kind = bzip2.NewReader(stream), "bzip2-compressed tar archive"
+	}
+
+	buffered := bufio.NewReaderSize(stream, 1024)
+	if header, _ := buffered.Peek(262); len(header) < 262 || string(header[257:262]) != "ustar" {
+		if fileType.MIME == "application/x-tar" {
+			return nil, errors.New("no tar header found")
+		}
+		return singleCompressedFile(path, fileType, gzipName, gzipTime, buffered), nil
+	}
+
+	listing := newArchiveListing(kind)
+	reader := tar.NewReader(buffered)
+	var readmeDepth int
+	for entries := 0; ; entries++ {
+		if entries == maxArchiveEntries {
+			listing.partial = fmt.Sprintf("only the first %d entries were read", maxArchiveEntries)
+			break
+		}
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			listing.partial = fmt.Sprintf("reading stopped at a damaged entry (%v)", err)
+			if limited.N == 0 {
+				listing.partial = fmt.Sprintf("only the first %d MB were read", maxCompressedRead>>20)
+			}
+			break
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeDir:
+		default:
+			// Links, devices and extended headers are not contents.
+			continue
+		}
+		isDir := header.Typeflag == tar.TypeDir
+		listing.add(header.Name, isDir, header.Size, header.ModTime)
+		if withReadme && !isDir && isReadme(header.Name) && (listing.readme == "" || depth(header.Name) < readmeDepth) {
+			listing.setReadme(header.Name, reader)
+			readmeDepth = depth(header.Name)
+		}
+	}
+	return listing, nil
+}
+
+// singleCompressedFile describes a gzip or bzip2 file that is not a tarball
+// by the name stored in its header, or its own name without the suffix.
+func singleCompressedFile(path string, fileType FileType, storedName string, modified time.Time, stream io.Reader) *archiveListing {
+	name := cmp.Or(storedName, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	listing := newArchiveListing(fileType.Description + " of a single file")
+	// One byte past the cap tells a file of exactly the cap from a larger one.
+	size, err := io.Copy(io.Discard, io.LimitReader(stream, maxSingleFileRead+1))
+	if err != nil || size > maxSingleFileRead {
+		listing.atLeast = true
+		size = min(size, maxSingleFileRead)
+	}
+	listing.add(name, false, size, modified)
+	return listing
+}
+
+func (l *archiveListing) add(name string, isDir bool, size int64, modified time.Time) {
+	name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	// macOS adds resource forks under __MACOSX when zipping.
+	if name == "" || name == "__MACOSX" || strings.HasPrefix(name, "__MACOSX/") {
+		return
+	}
+	if isDir {
+		l.folders++
+	} else {
+		l.files++
+		l.total += max(size, 0)
+		if extension := strings.ToLower(path.Ext(name)); extension != "" {
+			l.extensions[extension]++
+		}
+		if len(l.paths) < maxListedEntries {
+			l.paths = append(l.paths, fmt.Sprintf("%s (%s)", name, l.size(size)))
+		}
+	}
+
+	// Top-level names count the files under them; empty folders are kept at zero.
+	top, _, nested := strings.Cut(name, "/")
+	if nested || isDir {
+		top += "/"
+	}
+	if isDir {
+		l.topLevel[top] += 0
+	} else {
+		l.topLevel[top]++
+	}
+
+	// Zip tools store a 1980 date when they have none.
+	if !modified.IsZero() && modified.Year() > 1980 {
+		if l.first.IsZero() || modified.Before(l.first) {
+			l.first = modified
+		}
+		if modified.After(l.last) {
+			l.last = modified
+		}
+	}
+}
+
+// size writes a byte count, marked as a lower bound when reading stopped early.
+func (l *archiveListing) size(size int64) string {
+	if l.atLeast {
+		return "at least " + FormatSize(size)
+	}
+	return FormatSize(size)
+}
+
+func (l *archiveListing) setReadme(name string, reader io.Reader) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxReadmeSize))
+	if err != nil || len(data) == 0 {
+		return
+	}
+	l.readme = name
+	l.readmeText = strings.TrimSpace(decodeText(data))
+}
+
+func isReadme(name string) bool {
+	base := strings.ToLower(path.Base(filepath.ToSlash(name)))
+	base = strings.TrimSuffix(base, path.Ext(base))
+	return base == "readme" || base == "read_me" || base == "read me"
+}
+
+func depth(name string) int {
+	return strings.Count(strings.Trim(filepath.ToSlash(name), "/"), "/")
+}
+
+func (l *archiveListing) describe() []string {
+	lines := []string{
+		"Archive: " + l.kind,
+		fmt.Sprintf("Entries: %d files, %d folders", l.files, l.folders),
+		"Total size: " + l.size(l.total) + " uncompressed",
+	}
+	if !l.first.IsZero() {
+		lines = append(lines, "Modified: "+l.first.Format("2006-01-02")+" to "+l.last.Format("2006-01-02"))
+	}
+	if top := countedNames(l.topLevel); top != "" {
+		lines = append(lines, "Top level: "+top)
+	}
+	if extensions := countedNames(l.extensions); extensions != "" {
+		lines = append(lines, "File types: "+extensions)
+	}
+	if len(l.paths) > 0 {
+		lines = append(lines, "Files:")
+		for _, entry := range l.paths {
+			lines = append(lines, "- "+entry)
+		}
+		if more := l.files - len(l.paths); more > 0 {
+			lines = append(lines, fmt.Sprintf("- … %d more", more))
+		}
+	}
+	if l.partial != "" {
+		lines = append(lines, "Note: "+l.partial+".")
+	}
+	if l.readmeText != "" {
+		lines = append(lines, "README ("+l.readme+"):", l.readmeText)
+	}
+	return lines
+}
+
+// countedNames lists names by descending count, then by name.
+func countedNames(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(counts[b]-counts[a], strings.Compare(a, b))
+	})
+
+	parts := make([]string, 0, min(len(names), maxArchiveGroups))
+	for _, name := range names[:min(len(names), maxArchiveGroups)] {
+		parts = append(parts, fmt.Sprintf("%s (%d)", name, counts[name]))
+	}
+	if more := len(names) - len(parts); more > 0 {
+		parts = append(parts, fmt.Sprintf("%d more", more))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,160 @@
+package files
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractFileContentZipArchive(t *testing.T) {
+	path := writeZip(t, "download.zip",
+		"invoice-tool/", "",
+		"invoice-tool/README.md", "# Invoice tool\nGenerates monthly invoices from timesheets.",
+		"invoice-tool/src/main.py", "print('hi')",
+		"invoice-tool/src/render.py", "pass",
+		"invoice-tool/docs/README.txt", "Nested docs readme",
+		"__MACOSX/invoice-tool/._README.md", "resource fork",
+		"LICENSE", "MIT",
+	)
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	for _, want := range []string{
+		"Archive: ZIP archive",
+		"Entries: 5 files, 1 folders",
+		"Top level: invoice-tool/ (4), LICENSE (1)",
+		"File types: .py (2), .md (1), .txt (1)",
+		"- invoice-tool/src/main.py (11B)",
+		"README (invoice-tool/README.md):\n# Invoice tool\nGenerates monthly invoices from timesheets.",
+	} {
+		if !strings.Contains(content.Text, want) {
+			t.Fatalf("Text = %q, want %q", content.Text, want)
+		}
+	}
+	if strings.Contains(content.Text, "__MACOSX") || strings.Contains(content.Text, "Nested docs") {
+		t.Fatalf("Text = %q, want resource forks and nested READMEs left out", content.Text)
+	}
+}
+
+func TestExtractFileContentTarball(t *testing.T) {
+	var tarball bytes.Buffer
+	compressed := gzip.NewWriter(&tarball)
+	writer := tar.NewWriter(compressed)
+	modified := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, entry := range []struct {
+		name string
+		body string
+		dir  bool
+	}{
+		{name: "photos-2023/", dir: true},
+		{name: "photos-2023/README", body: "Summer trip to Lisbon"},
+		{name: "photos-2023/beach.jpg", body: strings.Repeat("x", 2048)},
+	} {
+		header := &tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.body)), ModTime: modified, Typeflag: tar.TypeReg}
+		if entry.dir {
+			header.Typeflag, header.Mode = tar.TypeDir, 0o755
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("WriteHeader() error = %v", err)
+		}
+		if _, err := writer.Write([]byte(entry.body)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		modified = modified.AddDate(0, 1, 0)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := compressed.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.tar.gz")
+	if err := os.WriteFile(path, tarball.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	for _, want := range []string{
+		"Archive: gzip-compressed tar archive",
+		"Entries: 2 files, 1 folders",
+		"Total size: 2.02KB uncompressed",
+		"Modified: 2023-06-01 to 2023-08-01",
+		"Top level: photos-2023/ (2)",
+		"README (photos-2023/README):\nSummer trip to Lisbon",
+	} {
+		if !strings.Contains(content.Text, want) {
+			t.Fatalf("Text = %q, want %q", content.Text, want)
+		}
+	}
+}
+
+func TestExtractFileContentCompressedFile(t *testing.T) {
+	var data bytes.Buffer
+	compressed := gzip.NewWriter(&data)
+	compressed.Name = "server-2024-03-01.log"
+	if _, err := compressed.Write([]byte(strings.Repeat("GET /index.html 200\n", 100))); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := compressed.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "log.gz")
+	if err := os.WriteFile(path, data.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if !strings.Contains(content.Text, "of a single file") || !strings.Contains(content.Text, "- server-2024-03-01.log (1.95KB)") {
+		t.Fatalf("Text = %q, want the stored name and size", content.Text)
+	}
+}
+
+func TestExtractFileContentLargeCompressedFile(t *testing.T) {
+	tests := []struct {
+		size int
+		want []string
+	}{
+		{size: maxSingleFileRead, want: []string{"- disk.img (16.00MB)", "Total size: 16.00MB uncompressed"}},
+		{size: maxSingleFileRead + 1<<20, want: []string{"- disk.img (at least 16.00MB)", "Total size: at least 16.00MB uncompressed"}},
+	}
+
+	for _, tt := range tests {
+		var data bytes.Buffer
+		compressed := gzip.NewWriter(&data)
+		compressed.Name = "disk.img"
+		if _, err := compressed.Write(make([]byte, tt.size)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := compressed.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "disk.img.gz")
+		if err := os.WriteFile(path, data.Bytes(), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+
+		content, err := ExtractFileContent(path, DefaultExtractOptions())
+		if err != nil {
+			t.Fatalf("ExtractFileContent() error = %v", err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(content.Text, want) {
+				t.Errorf("size %d: Text = %q, want %q", tt.size, content.Text, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,668 @@
+package files
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxCodeNames caps the imports, functions and types listed per file.
+	maxCodeNames = 30
+	// maxDocComment clips the leading doc comment.
+	maxDocComment = 400
+)
+
+// codeLanguage tells the lexical scanner how a language writes comments and
+// strings and where its declarations are. Patterns are matched per line after
+// comments and multi-line strings are blanked, so "^" without leading
+// whitespace means top level.
+type codeLanguage struct {
+	name         string
+	extensions   []string
+	mimeTypes    []string
+	interpreters []string // Shebang interpreters, without version suffixes
+	lineComments []string
+	blockComment [2]string
+	// multilineStrings are delimiters of strings that may span lines.
+	multilineStrings []string
+	// docstrings is set when a leading multi-line string documents the file.
+	docstrings  bool
+	module      *regexp.Regexp
+	moduleLabel string
+	imports     *regexp.Regexp
+	functions   *regexp.Regexp
+	types       *regexp.Regexp
+	typesLabel  string
+}
+
+var codeLanguages = []codeLanguage{
+	{
+		name:             "Python",
+		extensions:       []string{".py", ".pyw"},
+		mimeTypes:        []string{"text/x-python", "text/x-script.python"},
+		interpreters:     []string{"python"},
+		lineComments:     []string{"#"},
+		multilineStrings: []string{`"""`, `'''`},
+		docstrings:       true,
+		imports:          regexp.MustCompile(`^(?:from\s+([\w.]+)\s+import|import\s+([\w.]+))`),
+		functions:        regexp.MustCompile(`^(?:async\s+)?def\s+(\w+)`),
+		types:            regexp.MustCompile(`^class\s+(\w+)`),
+		typesLabel:       "Classes",
+	},
+	{
+		name:             "JavaScript",
+		extensions:       []string{".js", ".mjs", ".cjs", ".jsx"},
+		mimeTypes:        []string{"text/javascript", "application/javascript"},
+		interpreters:     []string{"node", "deno", "bun"},
+		lineComments:     []string{"//"},
+		blockComment:     [2]string{"/*", "*/"},
+		multilineStrings: []string{"`"},
+		imports:          regexp.MustCompile(`(?:^import\s[^'"]*|\brequire\()\s*['"]([^'"]+)['"]`),
+		functions:        regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?(?:function\s*\*?\s*(\w+)|(?:const|let|var)\s+(\w+)\s*=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*=>|\w+\s*=>))`),
+		types:            regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?class\s+(\w+)`),
+		typesLabel:       "Classes",
+	},
+	{
+		name:             "TypeScript",
+		extensions:       []string{".ts", ".tsx", ".mts", ".cts"},
+		mimeTypes:        []string{"text/x-typescript", "application/typescript"},
+		lineComments:     []string{"//"},
+		blockComment:     [2]string{"/*", "*/"},
+		multilineStrings: []string{"`"},
+		imports:          regexp.MustCompile(`(?:^import\s[^'"]*|\brequire\()\s*['"]([^'"]+)['"]`),
+		functions:        regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:async\s+)?(?:function\s*\*?\s*(\w+)|(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|\w+\s*=>))`),
+		types:            regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:class|interface|type|enum)\s+(\w+)`),
+		typesLabel:       "Types",
+	},
+	{
+		name:         "Rust",
+		extensions:   []string{".rs"},
+		mimeTypes:    []string{"text/x-rust"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		imports:      regexp.MustCompile(`^(?:pub\s+)?use\s+([\w:]+)`),
+		functions:    regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+\S+\s+)?fn\s+(\w+)`),
+		types:        regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:struct|enum|trait|union|type)\s+(\w+)`),
+		typesLabel:   "Types",
+	},
+	{
+		name:         "Java",
+		extensions:   []string{".java"},
+		mimeTypes:    []string{"text/x-java", "text/x-java-source"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		module:       regexp.MustCompile(`^package\s+([\w.]+)`),
+		moduleLabel:  "Package",
+		imports:      regexp.MustCompile(`^import\s+(?:static\s+)?([\w.*]+)`),
+		types:        regexp.MustCompile(`^\s*(?:(?:public|protected|private|static|final|abstract|sealed|non-sealed|strictfp)\s+)*(?:class|interface|enum|record|@interface)\s+(\w+)`),
+		typesLabel:   "Types",
+	},
+	{
+		name:         "Kotlin",
+		extensions:   []string{".kt", ".kts"},
+		mimeTypes:    []string{"text/x-kotlin"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		module:       regexp.MustCompile(`^package\s+([\w.]+)`),
+		moduleLabel:  "Package",
+		imports:      regexp.MustCompile(`^import\s+([\w.*]+)`),
+		functions:    regexp.MustCompile(`^(?:(?:public|private|internal|inline|suspend|operator|infix|tailrec)\s+)*fun\s+(?:<[^>]*>\s*)?(?:[\w.]+\.)?(\w+)`),
+		types:        regexp.MustCompile(`^\s*(?:(?:public|private|internal|protected|abstract|open|sealed|data|enum|inner|value|annotation)\s+)*(?:class|interface|object)\s+(\w+)`),
+		typesLabel:   "Types",
+	},
+	{
+		name:             "Scala",
+		extensions:       []string{".scala", ".sc"},
+		mimeTypes:        []string{"text/x-scala"},
+		interpreters:     []string{"scala"},
+		lineComments:     []string{"//"},
+		blockComment:     [2]string{"/*", "*/"},
+		multilineStrings: []string{`"""`},
+		module:           regexp.MustCompile(`^package\s+([\w.]+)`),
+		moduleLabel:      "Package",
+		imports:          regexp.MustCompile(`^import\s+([\w.]+)`),
+		functions:        regexp.MustCompile(`^(?:(?:private|protected)(?:\[\w+\])?\s+|(?:final|implicit|inline|transparent)\s+)*def\s+(\w+)`),
+		types:            regexp.MustCompile(`^\s*(?:(?:private|protected)(?:\[\w+\])?\s+|(?:final|sealed|abstract|implicit|case|open)\s+)*(?:class|trait|object|enum)\s+(\w+)`),
+		typesLabel:       "Types",
+	},
+	{
+		name:         "C#",
+		extensions:   []string{".cs"},
+		mimeTypes:    []string{"text/x-csharp"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		module:       regexp.MustCompile(`^namespace\s+([\w.]+)`),
+		moduleLabel:  "Namespace",
+		imports:      regexp.MustCompile(`^using\s+(?:static\s+)?([\w.]+)\s*;`),
+		types:        regexp.MustCompile(`^\s*(?:(?:public|protected|private|internal|static|sealed|abstract|partial|readonly|file)\s+)*(?:class|interface|enum|struct|record)\s+(\w+)`),
+		typesLabel:   "Types",
+	},
+	{
+		name:         "C",
+		extensions:   []string{".c", ".h"},
+		mimeTypes:    []string{"text/x-c", "text/x-csrc", "text/x-chdr"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		imports:      regexp.MustCompile(`^#\s*include\s*[<"]([^>"]+)`),
+		functions:    regexp.MustCompile(`^(?:static\s+|inline\s+|extern\s+)*[A-Za-z_][\w\s*]*?[\s*]\**(\w+)\s*\([^;]*$`),
+		types:        regexp.MustCompile(`^(?:typedef\s+)?(?:struct|enum|union)\s+(\w+)`),
+		typesLabel:   "Types",
+	},
+	{
+		name:         "C++",
+		extensions:   []string{".cpp", ".cc", ".cxx", ".hpp", ".hh", ".hxx"},
+		mimeTypes:    []string{"text/x-c++", "text/x-c++src", "text/x-c++hdr"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		module:       regexp.MustCompile(`^namespace\s+([\w:]+)`),
+		moduleLabel:  "Namespace",
+		imports:      regexp.MustCompile(`^#\s*include\s*[<"]([^>"]+)`),
+		functions:    regexp.MustCompile(`^(?:static\s+|inline\s+|extern\s+|constexpr\s+|virtual\s+)*[A-Za-z_][\w\s*&:<>,]*?[\s*&]\**([\w:~]+)\s*\([^;]*$`),
+		types:        regexp.MustCompile(`^\s*(?:template\s*<[^>]*>\s*)?(?:class|struct|enum(?:\s+class)?|union)\s+(\w+)\s*[^;]*$`),
+		typesLabel:   "Types",
+	},
+	{
+		name:         "Ruby",
+		extensions:   []string{".rb", ".rake"},
+		mimeTypes:    []string{"text/x-ruby"},
+		interpreters: []string{"ruby"},
+		lineComments: []string{"#"},
+		imports:      regexp.MustCompile(`^require(?:_relative)?\s*\(?\s*['"]([^'"]+)['"]`),
+		functions:    regexp.MustCompile(`^\s*def\s+((?:self\.)?\w+[?!=]?)`),
+		types:        regexp.MustCompile(`^\s*(?:class|module)\s+([\w:]+)`),
+		typesLabel:   "Classes",
+	},
+	{
+		name:         "PHP",
+		extensions:   []string{".php"},
+		mimeTypes:    []string{"text/x-php", "application/x-httpd-php"},
+		interpreters: []string{"php"},
+		lineComments: []string{"//", "#"},
+		blockComment: [2]string{"/*", "*/"},
+		module:       regexp.MustCompile(`^namespace\s+([\w\\]+)`),
+		moduleLabel:  "Namespace",
+		imports:      regexp.MustCompile(`^use\s+([\w\\]+)`),
+		functions:    regexp.MustCompile(`^\s*(?:(?:public|protected|private|static|abstract|final)\s+)*function\s+&?(\w+)`),
+		types:        regexp.MustCompile(`^\s*(?:(?:abstract|final|readonly)\s+)*(?:class|interface|trait|enum)\s+(\w+)`),
+		typesLabel:   "Classes",
+	},
+	{
+		name:         "Shell",
+		extensions:   []string{".sh", ".bash", ".zsh", ".ksh"},
+		mimeTypes:    []string{"text/x-shellscript", "application/x-sh"},
+		interpreters: []string{"sh", "bash", "zsh", "ksh", "dash"},
+		lineComments: []string{"#"},
+		functions:    regexp.MustCompile(`^(?:function\s+([\w:-]+)|([\w:-]+)\s*\(\s*\))`),
+	},
+	{
+		name:         "PowerShell",
+		extensions:   []string{".ps1", ".psm1"},
+		mimeTypes:    []string{"text/x-powershell"},
+		interpreters: []string{"pwsh"},
+		lineComments: []string{"#"},
+		blockComment: [2]string{"<#", "#>"},
+		imports:      regexp.MustCompile(`(?i)^(?:Import-Module|using\s+module)\s+([\w.\\/-]+)`),
+		functions:    regexp.MustCompile(`(?i)^function\s+([\w-]+)`),
+	},
+	{
+		name:         "Swift",
+		extensions:   []string{".swift"},
+		mimeTypes:    []string{"text/x-swift"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		imports:      regexp.MustCompile(`^import\s+(\w+)`),
+		functions:    regexp.MustCompile(`^(?:(?:public|private|internal|fileprivate|open|static)\s+)*func\s+(\w+)`),
+		types:        regexp.MustCompile(`^\s*(?:(?:public|private|internal|fileprivate|open|final)\s+)*(?:class|struct|enum|protocol|actor|extension)\s+(\w+)`),
+		typesLabel:   "Types",
+	},
+	{
+		name:             "Dart",
+		extensions:       []string{".dart"},
+		mimeTypes:        []string{"application/dart", "text/x-dart"},
+		interpreters:     []string{"dart"},
+		lineComments:     []string{"//", "///"},
+		blockComment:     [2]string{"/*", "*/"},
+		multilineStrings: []string{`"""`, `'''`},
+		module:           regexp.MustCompile(`^library\s+([\w.]+)`),
+		moduleLabel:      "Library",
+		imports:          regexp.MustCompile(`^(?:import|export)\s+['"]([^'"]+)['"]`),
+		// A return type, then the name and parameters: "Future<void> main(List<String> args) async {".
+		functions:  regexp.MustCompile(`^(?:external\s+)?[A-Za-z_][\w<>?,\[\] ]*?\s(\w+)\s*(?:<[^>]*>)?\(`),
+		types:      regexp.MustCompile(`^(?:(?:abstract|base|final|interface|sealed|mixin)\s+)*(?:class|mixin|enum|typedef)\s+(\w+)`),
+		typesLabel: "Types",
+	},
+	{
+		name:         "Lua",
+		extensions:   []string{".lua"},
+		mimeTypes:    []string{"text/x-lua"},
+		interpreters: []string{"lua", "luajit"},
+		lineComments: []string{"--"},
+		blockComment: [2]string{"--[[", "]]"},
+		imports:      regexp.MustCompile(`\brequire\s*\(?\s*['"]([^'"]+)['"]`),
+		functions:    regexp.MustCompile(`^(?:local\s+)?function\s+([\w.:]+)`),
+	},
+	{
+		name:         "Perl",
+		extensions:   []string{".pl", ".pm"},
+		mimeTypes:    []string{"text/x-perl"},
+		interpreters: []string{"perl"},
+		lineComments: []string{"#"},
+		module:       regexp.MustCompile(`^package\s+([\w:]+)`),
+		moduleLabel:  "Package",
+		imports:      regexp.MustCompile(`^use\s+([A-Z][\w:]+)`),
+		functions:    regexp.MustCompile(`^sub\s+(\w+)`),
+	},
+}
+
+// goLanguage is parsed with go/parser rather than scanned.
+var goLanguage = codeLanguage{name: "Go", extensions: []string{".go"}, mimeTypes: []string{"text/x-go"}, lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, moduleLabel: "Package"}
+
+func codeExtensions() []string {
+	extensions := slices.Clone(goLanguage.extensions)
+	for _, language := range codeLanguages {
+		extensions = append(extensions, language.extensions...)
+	}
+	return extensions
+}
+
+func codeMIMETypes() []string {
+	mimeTypes := slices.Clone(goLanguage.mimeTypes)
+	for _, language := range codeLanguages {
+		mimeTypes = append(mimeTypes, language.mimeTypes...)
+	}
+	return mimeTypes
+}
+
+// codeSummary is what is listed about a source file.
+type codeSummary struct {
+	language    string
+	interpreter string
+	module      string
+	moduleLabel string
+	doc         string
+	imports     []string
+	functions   []string
+	methods     []string
+	types       []string
+	typesLabel  string
+	lines       int
+}
+
+func extractCode(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error) {
+	if fileType.Binary {
+		return binaryContent(fileType), nil
+	}
+	data, err := readPrefix(path, maxMarkupSize)
+	if err != nil {
+		return ExtractedContent{}, err
+	}
+	source := decodeText(data)
+
+	language, ok := codeLanguageFor(strings.ToLower(filepath.Ext(path)), fileType.MIME, source)
+	if !ok {
+		return extractGeneric(path, fileType, opts)
+	}
+
+	var summary codeSummary
+	if language.name == goLanguage.name {
+		summary = summarizeGo(path, source)
+	} else {
+		summary = summarizeCode(language, source)
+	}
+	summary.interpreter = shebangLine(source)
+	summary.lines = strings.Count(strings.TrimSuffix(source, "\n"), "\n") + 1
+
+	return ExtractedContent{
+		Text:   strings.Join(summary.describe(), "\n"),
+		Sparse: summary.doc == "" && len(summary.functions)+len(summary.methods)+len(summary.types) == 0,
+	}, nil
+}
+
+// codeLanguageFor picks the language by extension, then by MIME type, then by
+// the shebang interpreter.
+func codeLanguageFor(extension, mime, source string) (codeLanguage, bool) {
+	languages := append([]codeLanguage{goLanguage}, codeLanguages...)
+	for _, language := range languages {
+		if slices.Contains(language.extensions, extension) {
+			return language, true
+		}
+	}
+	for _, language := range languages {
+		if slices.Contains(language.mimeTypes, mime) {
+			return language, true
+		}
+	}
+	if interpreter := shebangInterpreter(source); interpreter != "" {
+		for _, language := range languages {
+			if slices.Contains(language.interpreters, interpreter) {
+				return language, true
+			}
+		}
+	}
+	return codeLanguage{}, false
+}
+
+// shebangLine returns the "#!" line of a script without the marker.
+func shebangLine(source string) string {
+	if !strings.HasPrefix(source, "#!") {
+		return ""
+	}
+	line, _, _ := strings.Cut(source[2:], "\n")
+	return strings.TrimSpace(line)
+}
+
+// shebangInterpreter names the program a script runs with, skipping env and
+// dropping version suffixes: "#!/usr/bin/env python3.12" gives "python".
+func shebangInterpreter(source string) string {
+	fields := strings.Fields(shebangLine(source))
+	for len(fields) > 0 {
+		name := filepath.Base(fields[0])
+		if name == "env" || strings.HasPrefix(name, "-") {
+			fields = fields[1:]
+			continue
+		}
+		return strings.TrimRight(name, "0123456789.")
+	}
+	return ""
+}
+
+// summarizeGo reads the package clause, imports and top-level declarations
+// with go/parser, keeping what parsed before any syntax error.
+func summarizeGo(path, source string) codeSummary {
+	summary := codeSummary{language: "Go", moduleLabel: "Package", typesLabel: "Types"}
+	file, err := parser.ParseFile(token.NewFileSet(), path, source, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil && len(file.Decls) == 0 {
+		// Nothing parsed past a broken package clause: list the leading
+		// comment and line count rather than an empty package name.
+		return summarizeCode(goLanguage, source)
+	}
+
+	summary.module = file.Name.Name
+	if file.Doc != nil {
+		summary.doc = firstParagraph(file.Doc.Text())
+	} else {
+		summary.doc = leadingComment(goLanguage, source)
+	}
+	for _, spec := range file.Imports {
+		if value, err := strconv.Unquote(spec.Path.Value); err == nil {
+			summary.imports = append(summary.imports, value)
+		}
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				summary.functions = append(summary.functions, decl.Name.Name)
+				continue
+			}
+			summary.methods = append(summary.methods, receiverName(decl.Recv.List[0].Type)+"."+decl.Name.Name)
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				summary.types = append(summary.types, spec.(*ast.TypeSpec).Name.Name)
+			}
+		}
+	}
+	return summary
+}
+
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return "?"
+}
+
+// summarizeCode scans a file line by line with the language's patterns.
+func summarizeCode(language codeLanguage, source string) codeSummary {
+	summary := codeSummary{
+		language:    language.name,
+		moduleLabel: cmp.Or(language.moduleLabel, "Module"),
+		typesLabel:  cmp.Or(language.typesLabel, "Types"),
+		doc:         leadingComment(language, source),
+	}
+	for line := range strings.SplitSeq(blankComments(language, source), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if summary.module == "" {
+			summary.module = firstGroup(language.module, line)
+		}
+		summary.imports = appendName(summary.imports, firstGroup(language.imports, line))
+		summary.functions = appendName(summary.functions, firstGroup(language.functions, line))
+		summary.types = appendName(summary.types, firstGroup(language.types, line))
+	}
+	return summary
+}
+
+// firstGroup returns the first non-empty capture of pattern in line.
+func firstGroup(pattern *regexp.Regexp, line string) string {
+	if pattern == nil {
+		return ""
+	}
+	match := pattern.FindStringSubmatch(line)
+	for _, group := range match[min(len(match), 1):] {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+// codeKeywords are never declaration names, which the C-like function pattern
+// would otherwise pick up from control statements.
+var codeKeywords = []string{"if", "for", "while", "switch", "return", "else", "do", "sizeof", "catch"}
+
+func appendName(names []string, name string) []string {
+	if name == "" || slices.Contains(codeKeywords, name) || slices.Contains(names, name) {
+		return names
+	}
+	return append(names, name)
+}
+
+// blankComments replaces comments and the contents of multi-line strings
+// with spaces, keeping line breaks, so declarations in them are not matched.
+// Single-line strings are kept for import paths but skipped over, so comment
+// markers in them are left alone; they end at a newline.
+func blankComments(language codeLanguage, source string) string {
+	out := []byte(source)
+	blank := func(from, to int) {
+		for index := from; index < to && index < len(out); index++ {
+			if out[index] != '\n' {
+				out[index] = ' '
+			}
+		}
+	}
+
+	for pos := 0; pos < len(source); {
+		rest := source[pos:]
+		if open := language.blockComment[0]; open != "" && strings.HasPrefix(rest, open) {
+			end := strings.Index(rest[len(open):], language.blockComment[1])
+			if end < 0 {
+				blank(pos, len(source))
+				break
+			}
+			length := len(open) + end + len(language.blockComment[1])
+			blank(pos, pos+length)
+			pos += length
+			continue
+		}
+		if slices.ContainsFunc(language.lineComments, func(prefix string) bool { return strings.HasPrefix(rest, prefix) }) && !(pos == 0 && strings.HasPrefix(rest, "#!")) {
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			blank(pos, pos+end)
+			pos += end
+			continue
+		}
+		if delimiter := multilineDelimiter(language, rest); delimiter != "" {
+			end := strings.Index(rest[len(delimiter):], delimiter)
+			if end < 0 {
+				blank(pos+len(delimiter), len(source))
+				break
+			}
+			blank(pos+len(delimiter), pos+len(delimiter)+end)
+			pos += len(delimiter) + end + len(delimiter)
+			continue
+		}
+		if quote := rest[0]; quote == '"' || quote == '\'' {
+			end := 1
+			for end < len(rest) && rest[end] != quote && rest[end] != '\n' {
+				if rest[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			pos += min(end+1, len(rest))
+			continue
+		}
+		pos++
+	}
+	return string(out)
+}
+
+func multilineDelimiter(language codeLanguage, rest string) string {
+	for _, delimiter := range language.multilineStrings {
+		if strings.HasPrefix(rest, delimiter) {
+			return delimiter
+		}
+	}
+	return ""
+}
+
+// leadingComment returns the first paragraph of the comment or docstring that
+// opens a file, after the shebang and any license header.
+func leadingComment(language codeLanguage, source string) string {
+	lines := strings.Split(source, "\n")
+	lines = lines[:min(len(lines), 200)]
+	for index := 0; index < len(lines); {
+		line := strings.TrimSpace(lines[index])
+		switch {
+		case line == "" || index == 0 && strings.HasPrefix(line, "#!") || strings.Contains(line, "-*- coding") || strings.HasPrefix(line, "<?php") || strings.HasPrefix(line, "//go:build"):
+			index++
+			continue
+		}
+
+		var block []string
+		block, index = commentBlock(language, lines, index)
+		if block == nil {
+			return ""
+		}
+		text := firstParagraph(strings.Join(block, "\n"))
+		lower := strings.ToLower(text)
+		if text != "" && !strings.Contains(lower, "copyright") && !strings.Contains(lower, "license") && !strings.HasPrefix(lower, "spdx-") {
+			return text
+		}
+	}
+	return ""
+}
+
+// commentBlock reads the comment, or docstring, starting at lines[index] and
+// returns its text lines and the index after it; nil when the line is code.
+func commentBlock(language codeLanguage, lines []string, index int) ([]string, int) {
+	line := strings.TrimSpace(lines[index])
+	if open := language.blockComment[0]; open != "" && strings.HasPrefix(line, open) {
+		return delimitedBlock(lines, index, open, language.blockComment[1])
+	}
+	if delimiter := multilineDelimiter(language, line); delimiter != "" && language.docstrings {
+		return delimitedBlock(lines, index, delimiter, delimiter)
+	}
+
+	var block []string
+	for ; index < len(lines); index++ {
+		line := strings.TrimSpace(lines[index])
+		prefix := ""
+		for _, candidate := range language.lineComments {
+			if strings.HasPrefix(line, candidate) {
+				prefix = candidate
+			}
+		}
+		if prefix == "" {
+			break
+		}
+		// Doc markers such as "///", "//!" and "##" are part of the prefix.
+		block = append(block, strings.TrimSpace(strings.TrimLeft(line[len(prefix):], prefix[:1]+"!")))
+	}
+	if block == nil {
+		return nil, index
+	}
+	return block, index
+}
+
+// delimitedBlock reads a block comment or docstring that may span lines,
+// dropping the leading "*" of each line.
+func delimitedBlock(lines []string, index int, open, closing string) ([]string, int) {
+	text := strings.TrimSpace(lines[index])[len(open):]
+	var block []string
+	for {
+		body, closed := strings.CutSuffix(strings.TrimSpace(text), closing)
+		if !closed {
+			if before, _, found := strings.Cut(text, closing); found {
+				body, closed = before, true
+			}
+		}
+		body = strings.TrimSpace(body)
+		body = strings.TrimSpace(strings.TrimLeft(body, "*!"))
+		block = append(block, body)
+		index++
+		if closed || index >= len(lines) {
+			return block, index
+		}
+		text = lines[index]
+	}
+}
+
+// firstParagraph collapses the text up to the first blank line and clips it.
+func firstParagraph(text string) string {
+	text = strings.TrimSpace(text)
+	if paragraph, _, found := strings.Cut(text, "\n\n"); found {
+		text = paragraph
+	}
+	return clip(collapseSpace(text), maxDocComment)
+}
+
+func (s codeSummary) describe() []string {
+	lines := []string{"Source code: " + s.language}
+	if s.interpreter != "" {
+		lines = append(lines, "Interpreter: "+s.interpreter)
+	}
+	if s.module != "" {
+		lines = append(lines, s.moduleLabel+": "+s.module)
+	}
+	if s.doc != "" {
+		lines = append(lines, "Doc: "+s.doc)
+	}
+	for _, list := range []struct {
+		label string
+		names []string
+	}{{"Imports", s.imports}, {s.typesLabel, s.types}, {"Functions", s.functions}, {"Methods", s.methods}} {
+		if len(list.names) == 0 {
+			continue
+		}
+		line := fmt.Sprintf("%s: %s", list.label, strings.Join(list.names[:min(len(list.names), maxCodeNames)], ", "))
+		if extra := len(list.names) - maxCodeNames; extra > 0 {
+			line += fmt.Sprintf(" (%d more)", extra)
+		}
+		lines = append(lines, line)
+	}
+	return append(lines, fmt.Sprintf("Lines: %d", s.lines))
+}
@@ -0,0 +1,214 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractFileContentCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		source string
+		want   []string
+		absent []string
+	}{
+		{
+			name: "Go",
+			file: "server.go",
+			source: `// Copyright 2024 Example Inc. All rights reserved.
+
+// Package relay forwards webhooks to internal services.
+//
+// It retries failed deliveries.
+package relay
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type Config struct{ URL string }
+
+type Server[T any] struct{}
+
+func NewServer(config Config) *Server[int] { return nil }
+
+func (s *Server[T]) Start() error { return fmt.Errorf("todo: %v", http.StatusOK) }
+`,
+			want:   []string{"Source code: Go", "Package: relay", "Doc: Package relay forwards webhooks to internal services.", "Imports: fmt, net/http", "Types: Config, Server", "Functions: NewServer", "Methods: Server.Start", "Lines: 19"},
+			absent: []string{"Copyright", "retries"},
+		},
+		{
+			name: "Go with a broken package clause",
+			file: "draft.go",
+			source: `// Draft helpers for notes.
+packge draft
+
+func Clean(text string) string { return text }
+`,
+			want:   []string{"Source code: Go", "Doc: Draft helpers for notes.", "Lines: 4"},
+			absent: []string{"Package:", "Functions:"},
+		},
+		{
+			name: "Python script without extension",
+			file: "backup",
+			source: `#!/usr/bin/env python3
+# -*- coding: utf-8 -*-
+"""Back up the photo library to an external drive.
+
+Usage: backup [--dry-run]
+"""
+import os
+from pathlib import Path
+
+HELP = """
+def not_a_function():
+"""
+
+class Backup:
+    def run(self):
+        pass
+
+async def main():
+    pass
+`,
+			want:   []string{"Source code: Python", "Interpreter: /usr/bin/env python3", "Doc: Back up the photo library to an external drive.", "Imports: os, pathlib", "Classes: Backup", "Functions: main"},
+			absent: []string{"not_a_function", "run", "Usage"},
+		},
+		{
+			name: "TypeScript",
+			file: "client.ts",
+			source: `/**
+ * API client for the billing service.
+ */
+import { fetch } from "undici";
+const helpers = require('./helpers');
+
+// export function commented() {}
+export interface Invoice { id: string }
+export class BillingClient {}
+export const listInvoices = async (id: string): Promise<Invoice[]> => [];
+export default function createClient() {}
+const url = "http://example.com/function fake() {}";
+`,
+			want:   []string{"Source code: TypeScript", "Doc: API client for the billing service.", "Imports: undici, ./helpers", "Types: Invoice, BillingClient", "Functions: listInvoices, createClient"},
+			absent: []string{"commented", "fake"},
+		},
+		{
+			name: "Shell",
+			file: "deploy.sh",
+			source: `#!/bin/bash
+# Deploy the static site to the staging bucket.
+set -euo pipefail
+
+build() {
+  npm run build
+}
+
+function upload {
+  aws s3 sync dist/ "s3://$BUCKET"
+}
+`,
+			want: []string{"Source code: Shell", "Interpreter: /bin/bash", "Doc: Deploy the static site to the staging bucket.", "Functions: build, upload"},
+		},
+		{
+			name: "C",
+			file: "crc.c",
+			source: `/* SPDX-License-Identifier: MIT */
+#include <stdint.h>
+#include "crc.h"
+
+struct table { uint32_t entries[256]; };
+
+static uint32_t crc32_update(uint32_t crc, const uint8_t *data, size_t length)
+{
+	if (length == 0) {
+		return crc;
+	}
+	return crc;
+}
+
+int main(void) {
+	return 0;
+}
+`,
+			want:   []string{"Source code: C", "Imports: stdint.h, crc.h", "Types: table", "Functions: crc32_update, main"},
+			absent: []string{"Doc:"},
+		},
+		{
+			name: "Scala",
+			file: "Ledger.scala",
+			source: `/** Double-entry ledger for the accounting service. */
+package com.example.ledger
+
+import scala.collection.mutable
+import java.time.LocalDate
+
+sealed trait Entry
+final case class Debit(amount: BigDecimal) extends Entry
+
+object Ledger {
+  def balance(entries: Seq[Entry]): BigDecimal = 0
+}
+
+val query = """
+def notAFunction() = 1
+"""
+
+private[ledger] def audit(entry: Entry): Unit = ()
+`,
+			want:   []string{"Source code: Scala", "Package: com.example.ledger", "Doc: Double-entry ledger for the accounting service.", "Imports: scala.collection.mutable, java.time.LocalDate", "Types: Entry, Debit, Ledger", "Functions: audit"},
+			absent: []string{"notAFunction", "balance"},
+		},
+		{
+			name: "Dart",
+			file: "main.dart",
+			source: `/// Command-line entry point for the weather app.
+library weather.cli;
+
+import 'dart:io';
+import 'package:http/http.dart' as http;
+
+abstract class Forecast {}
+enum Unit { celsius, fahrenheit }
+
+final client = http.Client();
+
+Future<void> main(List<String> args) async {
+  print(formatTemperature(21.5, Unit.celsius));
+}
+
+String formatTemperature(double value, Unit unit) => '$value';
+`,
+			want:   []string{"Source code: Dart", "Library: weather.cli", "Doc: Command-line entry point for the weather app.", "Imports: dart:io, package:http/http.dart", "Types: Forecast, Unit", "Functions: main, formatTemperature"},
+			absent: []string{"print", "client"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.source), 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			content, err := ExtractFileContent(path, DefaultExtractOptions())
+			if err != nil {
+				t.Fatalf("ExtractFileContent() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(content.Text, want) {
+					t.Fatalf("Text = %q, want %q", content.Text, want)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(content.Text, absent) {
+					t.Fatalf("Text = %q, should not include %q", content.Text, absent)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	utils "nomnom/internal/utils"
+)
+
+const (
+	defaultCommandTimeout = 30 * time.Second
+	// maxCommandOutput caps the stdout kept from a command; it is truncated to
+	// MaxContentLength afterwards like any other text.
+	maxCommandOutput = 16 << 20
+)
+
+// CommandExtractor runs a local program, such as pdftotext or tesseract, and
+// uses its standard output as the text of the file.
+type CommandExtractor struct {
+	Name       string
+	Extensions []string
+	MIMETypes  []string
+	Command    []string
+	Timeout    time.Duration
+	// PreviewExtension is the extension of the image the command writes to
+	// {preview}, empty when it writes none.
+	PreviewExtension string
+	Category         string
+}
+
+// NewCommandExtractors validates the content_extraction.extractors config.
+func NewCommandExtractors(configs []utils.ExternalExtractorConfig) ([]CommandExtractor, error) {
+	extractors := make([]CommandExtractor, 0, len(configs))
+	for index, config := range configs {
+		label := fmt.Sprintf("extractor %d", index+1)
+		if config.Name != "" {
+			label = fmt.Sprintf("extractor %q", config.Name)
+		}
+
+		if len(config.Command) == 0 || strings.TrimSpace(config.Command[0]) == "" {
+			return nil, fmt.Errorf("%s: command is required", label)
+		}
+		if len(config.Extensions) == 0 && len(config.MIMETypes) == 0 {
+			return nil, fmt.Errorf("%s: set extensions or mime_types", label)
+		}
+
+		timeout := defaultCommandTimeout
+		if config.Timeout != "" {
+			parsed, err := time.ParseDuration(config.Timeout)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("%s: invalid timeout %q", label, config.Timeout)
+			}
+			timeout = parsed
+		}
+
+		var previewExtension string
+		if slices.ContainsFunc(config.Command, func(arg string) bool { return strings.Contains(arg, "{preview}") }) {
+			switch strings.ToLower(strings.TrimPrefix(config.Preview, ".")) {
+			case "", "png":
+				previewExtension = ".png"
+			case "jpg", "jpeg":
+				previewExtension = ".jpg"
+			case "webp":
+				previewExtension = ".webp"
+			default:
+				return nil, fmt.Errorf("%s: preview must be png, jpg or webp, got %q", label, config.Preview)
+			}
+		}
+
+		name := config.Name
+		if name == "" {
+			name = filepath.Base(config.Command[0])
+		}
+		extensions := make([]string, 0, len(config.Extensions))
+		for _, extension := range config.Extensions {
+			extension = strings.ToLower(strings.TrimSpace(extension))
+			if extension != "" && !strings.HasPrefix(extension, ".") {
+				extension = "." + extension
+			}
+			extensions = append(extensions, extension)
+		}
+
+		extractors = append(extractors, CommandExtractor{
+			Name:             name,
+			Extensions:       extensions,
+			MIMETypes:        config.MIMETypes,
+			Command:          config.Command,
+			Timeout:          timeout,
+			PreviewExtension: previewExtension,
+			Category:         config.Category,
+		})
+	}
+	return extractors, nil
+}
+
+// Matches reports whether the file's extension or detected type is handled by the command.
+func (c CommandExtractor) Matches(fileType FileType, extension string) bool {
+	if slices.Contains(c.Extensions, strings.ToLower(extension)) {
+		return true
+	}
+	family, _, _ := strings.Cut(fileType.MIME, "/")
+	return slices.Contains(c.MIMETypes, fileType.MIME) || (family != "" && slices.Contains(c.MIMETypes, family+"/*"))
+}
+
+// Extract runs the command within its timeout. A failed command, or one that
+// printed nothing and wrote no preview, is an error.
+func (c CommandExtractor) Extract(path string, _ FileType, _ ExtractOptions) (ExtractedContent, error) {
+	var previewPath string
+	if c.PreviewExtension != "" {
+		preview, err := os.CreateTemp("", "nomnom-preview-*"+c.PreviewExtension)
+		if err != nil {
+			return ExtractedContent{}, fmt.Errorf("creating temp preview for %s: %w", path, err)
+		}
+		previewPath = preview.Name()
+		_ = preview.Close()
+	}
+	removePreview := func() {
+		if previewPath != "" {
+			_ = os.Remove(previewPath)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	args := make([]string, 0, len(c.Command))
+	hasPath := false
+	for _, arg := range c.Command[1:] {
+		hasPath = hasPath || strings.Contains(arg, "{path}")
+		arg = strings.ReplaceAll(arg, "{path}", path)
+		args = append(args, strings.ReplaceAll(arg, "{preview}", previewPath))
+	}
+	if !hasPath {
+		args = append(args, path)
+	}
+
+	stdout := &limitedBuffer{limit: maxCommandOutput}
+	stderr := &limitedBuffer{limit: 4096}
+	cmd := exec.CommandContext(ctx, c.Command[0], args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Children that outlive a killed command must not keep the pipes open.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		removePreview()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ExtractedContent{}, fmt.Errorf("%s timed out after %s", c.Name, c.Timeout)
+		}
+		if message := lastLine(stderr.String()); message != "" {
+			return ExtractedContent{}, fmt.Errorf("%s: %w: %s", c.Name, err, message)
+		}
+		return ExtractedContent{}, fmt.Errorf("%s: %w", c.Name, err)
+	}
+
+	content := ExtractedContent{Text: strings.TrimSpace(strings.ToValidUTF8(stdout.String(), ""))}
+	if previewPath != "" {
+		if info, err := os.Stat(previewPath); err == nil && info.Size() > 0 {
+			content.PreviewImagePath = previewPath
+		} else {
+			removePreview()
+		}
+	}
+	if content.Text == "" && content.PreviewImagePath == "" {
+		return ExtractedContent{}, fmt.Errorf("%s produced no output", c.Name)
+	}
+	content.Sparse = content.Text == ""
+	return content, nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest, so a chatty command cannot exhaust memory.
+type limitedBuffer struct {
+	buffer bytes.Buffer
+	limit  int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	room := max(b.limit-b.buffer.Len(), 0)
+	b.buffer.Write(p[:min(room, len(p))])
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buffer.String()
+}
+
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// extractWithCommand runs the first command extractor matching the file and
+// returns it, or nil when none matches.
+func extractWithCommand(path string, fileType FileType, extension string, opts ExtractOptions) (ExtractedContent, *CommandExtractor, error) {
+	if !opts.ExtractText {
+		return ExtractedContent{}, nil, nil
+	}
+	for index := range opts.Commands {
+		command := &opts.Commands[index]
+		if !command.Matches(fileType, extension) {
+			continue
+		}
+		content, err := command.Extract(path, fileType, opts)
+		return content, command, err
+	}
+	return ExtractedContent{}, nil, nil
+}
@@ -0,0 +1,124 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	utils "nomnom/internal/utils"
+)
+
+func TestNewCommandExtractors(t *testing.T) {
+	extractors, err := NewCommandExtractors([]utils.ExternalExtractorConfig{
+		{Extensions: []string{"PDF"}, Command: []string{"/usr/bin/pdftotext", "{path}", "-"}},
+		{Name: "ocr", MIMETypes: []string{"image/*"}, Command: []string{"tesseract", "{path}", "stdout"}, Timeout: "2m", Preview: "jpg"},
+	})
+	if err != nil {
+		t.Fatalf("NewCommandExtractors() error = %v", err)
+	}
+	if extractors[0].Name != "pdftotext" || extractors[0].Extensions[0] != ".pdf" || extractors[0].Timeout != defaultCommandTimeout {
+		t.Fatalf("extractors[0] = %+v, want defaults filled in", extractors[0])
+	}
+	if extractors[1].Timeout != 2*time.Minute || extractors[1].PreviewExtension != "" {
+		t.Fatalf("extractors[1] = %+v, want no preview without {preview}", extractors[1])
+	}
+	if !extractors[1].Matches(FileType{MIME: "image/png"}, ".bin") || extractors[1].Matches(FileType{MIME: "text/plain"}, ".png") {
+		t.Fatal("Matches() should use the MIME family for ocr")
+	}
+
+	invalid := []utils.ExternalExtractorConfig{
+		{Extensions: []string{".pdf"}},
+		{Command: []string{"pdftotext"}},
+		{Extensions: []string{".pdf"}, Command: []string{"pdftotext"}, Timeout: "soon"},
+		{Extensions: []string{".pdf"}, Command: []string{"render", "{preview}"}, Preview: "gif"},
+	}
+	for _, config := range invalid {
+		if _, err := NewCommandExtractors([]utils.ExternalExtractorConfig{config}); err == nil {
+			t.Fatalf("NewCommandExtractors(%+v) error = nil, want a validation error", config)
+		}
+	}
+}
+
+func TestExtractFileContentRunsCommandExtractor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("quarterly report"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	opts := DefaultExtractOptions()
+	opts.Commands = mustCommandExtractors(t, utils.ExternalExtractorConfig{
+		Extensions: []string{".txt"},
+		Command:    []string{"sh", "-c", `tr a-z A-Z < "$1"; printf image > "$2"`, "sh", "{path}", "{preview}"},
+	})
+
+	content, err := ExtractFileContent(path, opts)
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	defer os.Remove(content.PreviewImagePath)
+
+	if content.Text != "QUARTERLY REPORT" || content.Category != CategoryDocuments || content.Warning != "" {
+		t.Fatalf("ExtractFileContent() = %+v, want the command output in Documents", content)
+	}
+	if filepath.Ext(content.PreviewImagePath) != ".png" {
+		t.Fatalf("PreviewImagePath = %q, want the PNG written by the command", content.PreviewImagePath)
+	}
+}
+
+func TestExtractFileContentFallsBackWhenCommandFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("quarterly report"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  utils.ExternalExtractorConfig
+		warning string
+	}{
+		{
+			name:    "exit status",
+			config:  utils.ExternalExtractorConfig{Extensions: []string{".txt"}, Command: []string{"sh", "-c", "echo cannot parse >&2; exit 3"}},
+			warning: "cannot parse",
+		},
+		{
+			name:    "timeout",
+			config:  utils.ExternalExtractorConfig{Extensions: []string{".txt"}, Command: []string{"sh", "-c", "exec sleep 5"}, Timeout: "50ms"},
+			warning: "timed out",
+		},
+		{
+			name:    "no output",
+			config:  utils.ExternalExtractorConfig{Extensions: []string{".txt"}, Command: []string{"true"}},
+			warning: "produced no output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultExtractOptions()
+			opts.Commands = mustCommandExtractors(t, tt.config)
+
+			content, err := ExtractFileContent(path, opts)
+			if err != nil {
+				t.Fatalf("ExtractFileContent() error = %v", err)
+			}
+			if content.Text != "quarterly report" {
+				t.Fatalf("Text = %q, want the built-in extraction", content.Text)
+			}
+			if !strings.Contains(content.Warning, tt.warning) {
+				t.Fatalf("Warning = %q, want %q", content.Warning, tt.warning)
+			}
+		})
+	}
+}
+
+func mustCommandExtractors(t *testing.T, configs ...utils.ExternalExtractorConfig) []CommandExtractor {
+	t.Helper()
+	extractors, err := NewCommandExtractors(configs)
+	if err != nil {
+		t.Fatalf("NewCommandExtractors() error = %v", err)
+	}
+	return extractors
+}
@@ -0,0 +1,134 @@
+package files
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/transform"
+)
+
+const (
+	// maxTableSize caps how much of a delimited file is read to count its rows.
+	maxTableSize = 256 << 20
+	// delimiterSample is how much of a file is used to guess its delimiter.
+	delimiterSample = 64 << 10
+)
+
+// delimiters are the separators tried, with the names used in the context.
+var delimiters = []struct {
+	char rune
+	name string
+}{{',', "comma"}, {';', "semicolon"}, {'\t', "tab"}, {'|', "pipe"}}
+
+func extractTable(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error) {
+	if fileType.Binary {
+		return binaryContent(fileType), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ExtractedContent{}, err
+	}
+	defer file.Close()
+
+	sample := make([]byte, delimiterSample)
+	count, err := io.ReadFull(file, sample)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return ExtractedContent{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	sample = sample[:count]
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return ExtractedContent{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	enc, encodingName := textEncoding(sample)
+	decodedSample, _, _ := transform.Bytes(enc.NewDecoder(), sample)
+	delimiter, delimiterName := guessDelimiter(decodedSample, strings.ToLower(filepath.Ext(path)))
+
+	limited := &io.LimitedReader{R: file, N: maxTableSize}
+	reader := csv.NewReader(transform.NewReader(limited, enc.NewDecoder()))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	var table tableSummary
+	var parseErr error
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			parseErr = err
+			break
+		}
+		if !table.addRow(record) {
+			break
+		}
+	}
+
+	lines := []string{
+		"Delimited table",
+		"Delimiter: " + delimiterName,
+		"Encoding: " + encodingName,
+	}
+	lines = append(lines, table.describe(opts.ExtractText)...)
+	switch {
+	case parseErr != nil:
+		lines = append(lines, fmt.Sprintf("Note: reading stopped early (%v).", parseErr))
+	case table.partial:
+		lines = append(lines, fmt.Sprintf("Note: only the first %d rows were read, so there are more rows.", maxTableRows))
+	case limited.N == 0:
+		lines = append(lines, fmt.Sprintf("Note: only the first %d MB were read, so there are more rows.", maxTableSize>>20))
+	}
+
+	return ExtractedContent{
+		Text:   strings.Join(lines, "\n"),
+		Sparse: table.rows == 0,
+	}, nil
+}
+
+// guessDelimiter picks the separator that splits the sample's lines into the
+// most consistent number of fields. Tab-separated extensions default to tabs.
+func guessDelimiter(sample []byte, extension string) (rune, string) {
+	// Drop the last line, which the sample may have cut.
+	if cut := bytes.LastIndexByte(sample, '\n'); cut > 0 && len(sample) == delimiterSample {
+		sample = sample[:cut]
+	}
+
+	best, bestScore, bestFields := 0, 0, 0
+	if extension == ".tsv" || extension == ".tab" {
+		best = 2
+	}
+	for index, candidate := range delimiters {
+		reader := csv.NewReader(bytes.NewReader(sample))
+		reader.Comma = candidate.char
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+
+		score, fields := 0, 0
+		for records := 0; records < 20; records++ {
+			record, err := reader.Read()
+			if err != nil {
+				break
+			}
+			if records == 0 {
+				fields = len(record)
+			}
+			if len(record) == fields && fields > 1 {
+				score++
+			}
+		}
+		if score > bestScore || score == bestScore && score > 0 && fields > bestFields {
+			best, bestScore, bestFields = index, score, fields
+		}
+	}
+	return delimiters[best].char, delimiters[best].name
+}
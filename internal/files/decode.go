@@ -0,0 +1,102 @@
+package files
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// maxMarkupSize caps what structured extractors read before summarizing; the
+// summary is truncated to MaxContentLength afterwards.
+const maxMarkupSize = 8 << 20
+
+// readPrefix reads at most limit bytes from the start of path.
+func readPrefix(path string, limit int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, limit))
+}
+
+// textEncoding guesses the encoding of data: UTF-16 with a byte order mark,
+// UTF-8 when valid, otherwise Windows-1252, the usual legacy encoding of
+// western text.
+func textEncoding(data []byte) (encoding.Encoding, string) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), "UTF-16LE"
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), "UTF-16BE"
+	case validUTF8Prefix(data):
+		return unicode.UTF8BOM, "UTF-8"
+	}
+	return charmap.Windows1252, "Windows-1252"
+}
+
+// validUTF8Prefix is utf8.Valid, tolerating a sequence cut at the end of data.
+func validUTF8Prefix(data []byte) bool {
+	if utf8.Valid(data) {
+		return true
+	}
+	for cut := 1; cut < utf8.UTFMax && cut < len(data); cut++ {
+		if utf8.Valid(data[:len(data)-cut]) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeText converts data to UTF-8 using the encoding textEncoding detects.
+func decodeText(data []byte) string {
+	enc, _ := textEncoding(data)
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return strings.ToValidUTF8(string(data), "")
+	}
+	return strings.ToValidUTF8(string(decoded), "")
+}
+
+// parseLooseDate reads the dates found in web pages, feeds and data files.
+func parseLooseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339, time.RFC1123Z, time.RFC1123, time.RFC850, time.ANSIC, "2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02", "2006/01/02", "2006.01.02", "02.01.2006", "2006-01"} {
+		if len(value) < len(layout) {
+			continue
+		}
+		if parsed, err := time.Parse(layout, value[:len(layout)]); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// collapseSpace joins the words of text with single spaces.
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// clip shortens text to at most limit bytes on a rune boundary.
+func clip(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return strings.TrimSpace(text[:cut]) + "…"
+}
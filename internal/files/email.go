@@ -0,0 +1,381 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+const (
+	// maxEmailBody is how much of the message body is sent.
+	maxEmailBody = 8 << 10
+	// maxEmailParts caps the MIME parts read from one message.
+	maxEmailParts = 100
+	// maxEmailDepth caps the nesting of multipart bodies.
+	maxEmailDepth = 5
+	// maxRecipients is how many addresses are listed per header.
+	maxRecipients = 10
+	// maxMboxSize caps how much of a mailbox is read.
+	maxMboxSize = 1 << 30
+	// maxMboxSubjects is how many subjects of a mailbox are listed.
+	maxMboxSubjects = 15
+)
+
+// mailDecoder decodes RFC 2047 encoded words in any charset the web knows.
+var mailDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// charsetReader decodes text in a charset named by its label, such as
+// "iso-8859-1" or "windows-1252".
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	encoding, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, err
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
+
+// emailMessage is what is sent about one message.
+type emailMessage struct {
+	subject     string
+	from        []*mail.Address
+	to          []*mail.Address
+	cc          []*mail.Address
+	date        time.Time
+	body        string
+	htmlBody    string
+	attachments []string
+	parts       int
+}
+
+func extractEmail(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error) {
+	if fileType.Binary {
+		return binaryContent(fileType), nil
+	}
+	if fileType.MIME == "application/mbox" || strings.EqualFold(filepath.Ext(path), ".mbox") {
+		return extractMailbox(path)
+	}
+
+	data, err := readPrefix(path, maxMarkupSize)
+	if err != nil {
+		return ExtractedContent{}, err
+	}
+	if bytes.HasPrefix(data, []byte("From ")) {
+		// A single message saved with its mbox separator line.
+		if _, rest, found := bytes.Cut(data, []byte("\n")); found {
+			data = rest
+		}
+	}
+
+	message, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return extractGeneric(path, fileType, opts)
+	}
+	email := readEmail(message)
+
+	lines := []string{"Email message"}
+	metadata := make(map[string]string)
+	if email.subject != "" {
+		lines = append(lines, "Subject: "+email.subject)
+		metadata["title"] = email.subject
+		metadata["subject"] = email.subject
+	}
+	for _, header := range []struct {
+		label     string
+		addresses []*mail.Address
+	}{{"From", email.from}, {"To", email.to}, {"Cc", email.cc}} {
+		if len(header.addresses) > 0 {
+			lines = append(lines, header.label+": "+formatAddresses(header.addresses))
+		}
+	}
+	if len(email.from) > 0 {
+		metadata["author"] = cmp.Or(email.from[0].Name, email.from[0].Address)
+	}
+	if !email.date.IsZero() {
+		lines = append(lines, "Date: "+email.date.Format("2006-01-02 15:04 -0700"))
+		metadata["created"] = email.date.Format("2006-01-02")
+		metadata["year"] = email.date.Format("2006")
+	}
+	if len(email.attachments) > 0 {
+		lines = append(lines, fmt.Sprintf("Attachments (%d): %s", len(email.attachments), strings.Join(email.attachments, ", ")))
+	}
+
+	body := email.body
+	if body == "" && email.htmlBody != "" {
+		body = parseHTML(email.htmlBody).text
+	}
+	if opts.ExtractText && body != "" {
+		lines = append(lines, "Body:", clip(body, maxEmailBody))
+	}
+
+	if !opts.ExtractMetadata {
+		metadata = nil
+	}
+	return ExtractedContent{
+		Text:     strings.Join(lines, "\n"),
+		Metadata: metadata,
+		Sparse:   email.subject == "" && body == "",
+	}, nil
+}
+
+// readEmail decodes the headers of a message and walks its MIME parts for the
+// first text body and the attachment names.
+func readEmail(message *mail.Message) emailMessage {
+	parser := mail.AddressParser{WordDecoder: mailDecoder}
+	email := emailMessage{subject: decodeHeader(message.Header.Get("Subject"))}
+	email.from = parseAddresses(parser, message.Header.Get("From"))
+	email.to = parseAddresses(parser, message.Header.Get("To"))
+	email.cc = parseAddresses(parser, message.Header.Get("Cc"))
+	if date, err := message.Header.Date(); err == nil {
+		email.date = date
+	}
+
+	email.readPart(mailHeader(message.Header), message.Body, 0)
+	return email
+}
+
+// mailHeader is the header of a message or of one of its parts.
+type mailHeader interface {
+	Get(key string) string
+}
+
+func (e *emailMessage) readPart(header mailHeader, body io.Reader, depth int) {
+	e.parts++
+	if e.parts > maxEmailParts {
+		return
+	}
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxEmailDepth || params["boundary"] == "" {
+			return
+		}
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err != nil {
+				return
+			}
+			e.readPart(part.Header, part, depth+1)
+		}
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	name := decodeHeader(cmp.Or(dispositionParams["filename"], params["name"]))
+	content := transferDecoder(header.Get("Content-Transfer-Encoding"), body)
+
+	switch {
+	case disposition == "attachment" || name != "" && !strings.HasPrefix(mediaType, "text/"):
+		size, _ := io.Copy(io.Discard, content)
+		e.attachments = append(e.attachments, fmt.Sprintf("%s (%s)", cmp.Or(name, mediaType), FormatSize(size)))
+	case mediaType == "text/plain" && e.body == "":
+		e.body = readBodyText(content, params["charset"])
+	case mediaType == "text/html" && e.htmlBody == "":
+		e.htmlBody = readBodyText(content, params["charset"])
+	case mediaType == "message/rfc822" && depth < maxEmailDepth:
+		if attached, err := mail.ReadMessage(content); err == nil {
+			subject := decodeHeader(attached.Header.Get("Subject"))
+			e.attachments = append(e.attachments, "forwarded message: "+cmp.Or(subject, "(no subject)"))
+		}
+	}
+}
+
+// transferDecoder undoes the Content-Transfer-Encoding of a part.
+func transferDecoder(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{reader: body})
+	}
+	return body
+}
+
+// base64Cleaner drops the characters that may separate base64 lines, which
+// the standard decoder accepts only as CR and LF.
+type base64Cleaner struct {
+	reader io.Reader
+}
+
+func (c *base64Cleaner) Read(buffer []byte) (int, error) {
+	n, err := c.reader.Read(buffer)
+	kept := 0
+	for _, char := range buffer[:n] {
+		if char != ' ' && char != '\t' {
+			buffer[kept] = char
+			kept++
+		}
+	}
+	return kept, err
+}
+
+// readBodyText reads a text part in its charset, dropping quoted replies.
+func readBodyText(body io.Reader, charset string) string {
+	if charset != "" && !strings.EqualFold(charset, "utf-8") && !strings.EqualFold(charset, "us-ascii") {
+		if decoded, err := charsetReader(charset, body); err == nil {
+			body = decoded
+		}
+	}
+	data, _ := io.ReadAll(io.LimitReader(body, 4*maxEmailBody))
+	text := strings.ToValidUTF8(strings.ReplaceAll(string(data), "\r\n", "\n"), "")
+
+	var kept []string
+	for line := range strings.SplitSeq(text, "\n") {
+		if strings.HasPrefix(line, ">") {
+			continue
+		}
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+func decodeHeader(value string) string {
+	if decoded, err := mailDecoder.DecodeHeader(value); err == nil {
+		value = decoded
+	}
+	return collapseSpace(value)
+}
+
+// parseAddresses reads an address list, keeping the raw text as the address
+// when it does not parse.
+func parseAddresses(parser mail.AddressParser, value string) []*mail.Address {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	addresses, err := parser.ParseList(value)
+	if err != nil {
+		return []*mail.Address{{Address: decodeHeader(value)}}
+	}
+	return addresses
+}
+
+func formatAddresses(addresses []*mail.Address) string {
+	parts := make([]string, 0, min(len(addresses), maxRecipients))
+	for _, address := range addresses[:min(len(addresses), maxRecipients)] {
+		switch {
+		case address.Name == "":
+			parts = append(parts, address.Address)
+		case address.Address == "":
+			parts = append(parts, address.Name)
+		default:
+			parts = append(parts, address.Name+" <"+address.Address+">")
+		}
+	}
+	if more := len(addresses) - len(parts); more > 0 {
+		parts = append(parts, fmt.Sprintf("%d more", more))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// extractMailbox summarizes an mbox file from the headers of its messages:
+// the count, the date range, the most frequent senders and the first subjects.
+func extractMailbox(path string) (ExtractedContent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ExtractedContent{}, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(io.LimitReader(file, maxMboxSize))
+	var messages int
+	var first, last time.Time
+	senders := make(map[string]int)
+	var subjects []string
+	var header bytes.Buffer
+	inHeader, previousBlank := false, true
+
+	finishHeader := func() {
+		inHeader = false
+		message, err := mail.ReadMessage(io.MultiReader(&header, strings.NewReader("\n")))
+		header.Reset()
+		if err != nil {
+			return
+		}
+		if date, err := message.Header.Date(); err == nil {
+			if first.IsZero() || date.Before(first) {
+				first = date
+			}
+			if date.After(last) {
+				last = date
+			}
+		}
+		if from := parseAddresses(mail.AddressParser{WordDecoder: mailDecoder}, message.Header.Get("From")); len(from) > 0 {
+			senders[cmp.Or(from[0].Address, from[0].Name)]++
+		}
+		if subject := decodeHeader(message.Header.Get("Subject")); subject != "" && len(subjects) < maxMboxSubjects {
+			subjects = append(subjects, clip(subject, 200))
+		}
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			trimmed := strings.TrimRight(line, "\r\n")
+			switch {
+			case previousBlank && strings.HasPrefix(line, "From "):
+				if inHeader {
+					finishHeader()
+				}
+				messages++
+				inHeader = true
+			case inHeader && trimmed == "":
+				finishHeader()
+			case inHeader:
+				header.WriteString(line)
+			}
+			previousBlank = trimmed == ""
+		}
+		if err != nil {
+			if inHeader {
+				finishHeader()
+			}
+			if !errors.Is(err, io.EOF) {
+				return ExtractedContent{}, fmt.Errorf("reading mailbox: %w", err)
+			}
+			break
+		}
+	}
+
+	lines := []string{"Mailbox (mbox)", fmt.Sprintf("Messages: %d", messages)}
+	if !first.IsZero() {
+		lines = append(lines, "Dates: "+first.Format("2006-01-02")+" to "+last.Format("2006-01-02"))
+	}
+	if len(senders) > 0 {
+		names := make([]string, 0, len(senders))
+		for sender := range senders {
+			names = append(names, sender)
+		}
+		slices.SortFunc(names, func(a, b string) int {
+			return cmp.Or(senders[b]-senders[a], strings.Compare(a, b))
+		})
+		parts := make([]string, 0, min(len(names), maxRecipients))
+		for _, name := range names[:min(len(names), maxRecipients)] {
+			parts = append(parts, fmt.Sprintf("%s (%d)", name, senders[name]))
+		}
+		lines = append(lines, "Senders: "+strings.Join(parts, ", "))
+	}
+	if len(subjects) > 0 {
+		lines = append(lines, "Subjects:")
+		for _, subject := range subjects {
+			lines = append(lines, "- "+subject)
+		}
+	}
+	return ExtractedContent{Text: strings.Join(lines, "\n"), Sparse: messages == 0}, nil
+}
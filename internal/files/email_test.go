@@ -0,0 +1,114 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleEmail = "Return-Path: <billing@example.com>\r\n" +
+	"Message-ID: <CAF1234@mail.example.com>\r\n" +
+	"From: =?UTF-8?Q?Fran=C3=A7oise_Martin?= <billing@example.com>\r\n" +
+	"To: Alex <alex@example.org>, team@example.org\r\n" +
+	"Subject: =?ISO-8859-1?Q?Facture_de_f=E9vrier?=\r\n" +
+	"Date: Thu, 29 Feb 2024 16:05:00 +0100\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Bonjour Alex,=0D\r\n" +
+	"voici la facture de f=E9vrier pour l'h=E9bergement.\r\n" +
+	"> earlier quoted reply\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html\r\n" +
+	"\r\n" +
+	"<p>HTML version</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=\"facture-2024-02.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"facture-2024-02.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0xLjQKJcfs\r\n" +
+	"j6IKMSAwIG9iago=\r\n" +
+	"--outer--\r\n"
+
+func TestExtractFileContentEmail(t *testing.T) {
+	// Saved without an extension, the message is recognized by its headers.
+	path := filepath.Join(t.TempDir(), "CAF1234")
+	if err := os.WriteFile(path, []byte(sampleEmail), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if content.Category != CategoryDocuments {
+		t.Fatalf("Category = %q, want Documents", content.Category)
+	}
+	for _, want := range []string{
+		"Subject: Facture de février",
+		"From: Françoise Martin <billing@example.com>",
+		"To: Alex <alex@example.org>, team@example.org",
+		"Date: 2024-02-29 16:05 +0100",
+		"Attachments (1): facture-2024-02.pdf (23B)",
+		"Body:\nBonjour Alex,\r\nvoici la facture de février pour l'hébergement.",
+	} {
+		if !strings.Contains(content.Text, want) {
+			t.Fatalf("Text = %q, want %q", content.Text, want)
+		}
+	}
+	if strings.Contains(content.Text, "quoted reply") || strings.Contains(content.Text, "HTML version") {
+		t.Fatalf("Text = %q, want quoted lines and the HTML part left out", content.Text)
+	}
+	if content.Metadata["title"] != "Facture de février" || content.Metadata["author"] != "Françoise Martin" || content.Metadata["year"] != "2024" {
+		t.Fatalf("Metadata = %v, want subject, sender and year", content.Metadata)
+	}
+}
+
+func TestExtractFileContentEmailHTMLOnly(t *testing.T) {
+	message := "From: shop@example.com\nSubject: Your order\nContent-Type: text/html; charset=utf-8\n\n<html><body><h1>Order 1042</h1><p>Ships tomorrow.</p></body></html>\n"
+	path := filepath.Join(t.TempDir(), "order.eml")
+	if err := os.WriteFile(path, []byte(message), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if !strings.Contains(content.Text, "Body:\nOrder 1042\nShips tomorrow.") {
+		t.Fatalf("Text = %q, want the text of the HTML body", content.Text)
+	}
+}
+
+func TestExtractFileContentMailbox(t *testing.T) {
+	mbox := "From alice@example.com Mon Jan  8 09:00:00 2024\n" +
+		"From: Alice <alice@example.com>\nSubject: Kickoff\nDate: Mon, 8 Jan 2024 09:00:00 +0000\n\nAgenda attached.\n\n" +
+		"From bob@example.com Tue Mar 12 10:00:00 2024\n" +
+		"From: bob@example.com\nSubject: Re: Kickoff\nDate: Tue, 12 Mar 2024 10:00:00 +0000\n\n>From the notes: fine.\n\n" +
+		"From alice@example.com Fri Feb  2 11:00:00 2024\n" +
+		"From: Alice <alice@example.com>\nSubject: Budget\nDate: Fri, 2 Feb 2024 11:00:00 +0000\n\nNumbers inside.\n"
+	path := filepath.Join(t.TempDir(), "Project.mbox")
+	if err := os.WriteFile(path, []byte(mbox), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	for _, want := range []string{"Messages: 3", "Dates: 2024-01-08 to 2024-03-12", "Senders: alice@example.com (2), bob@example.com (1)", "- Kickoff\n- Re: Kickoff\n- Budget"} {
+		if !strings.Contains(content.Text, want) {
+			t.Fatalf("Text = %q, want %q", content.Text, want)
+		}
+	}
+}
@@ -0,0 +1,473 @@
+package files
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxEXIFSize caps the EXIF block read from PNG and WebP chunks.
+const maxEXIFSize = 4 << 20
+
+var errNoImageHeader = errors.New("unrecognized image header")
+
+// imageDetails is what the headers of an image say about it.
+type imageDetails struct {
+	Width  int
+	Height int
+	EXIF   exifData
+}
+
+// exifData holds the EXIF tags used for naming.
+type exifData struct {
+	Make        string
+	Model       string
+	Orientation int
+	Taken       time.Time
+	HasGPS      bool
+	Latitude    float64
+	Longitude   float64
+	// PixelWidth and PixelHeight come from the EXIF IFD, for headers without dimensions.
+	PixelWidth  int
+	PixelHeight int
+}
+
+// readImageDetails reads the dimensions and EXIF tags of a JPEG, PNG or WebP file.
+func readImageDetails(path string) (imageDetails, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return imageDetails{}, err
+	}
+	defer file.Close()
+
+	magic := make([]byte, 12)
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return imageDetails{}, errNoImageHeader
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return imageDetails{}, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("\xff\xd8")):
+		return readJPEGDetails(file)
+	case bytes.HasPrefix(magic, []byte("\x89PNG\r\n\x1a\n")):
+		return readPNGDetails(file)
+	case bytes.HasPrefix(magic, []byte("RIFF")) && string(magic[8:12]) == "WEBP":
+		return readWebPDetails(file)
+	}
+	return imageDetails{}, errNoImageHeader
+}
+
+// readJPEGDetails walks the JPEG segments up to the start of the scan, reading
+// the frame size from the SOF segment and EXIF from APP1.
+func readJPEGDetails(r io.ReadSeeker) (imageDetails, error) {
+	var details imageDetails
+	if _, err := r.Seek(2, io.SeekStart); err != nil {
+		return details, err
+	}
+
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header[:2]); err != nil {
+			return details, nil
+		}
+		if header[0] != 0xff {
+			return details, nil
+		}
+		marker := header[1]
+		if marker == 0xff {
+			// Fill byte before a marker.
+			if _, err := r.Seek(-1, io.SeekCurrent); err != nil {
+				return details, err
+			}
+			continue
+		}
+		if marker == 0xd8 || marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7) {
+			continue
+		}
+		if marker == 0xd9 || marker == 0xda {
+			return details, nil
+		}
+
+		if _, err := io.ReadFull(r, header[2:4]); err != nil {
+			return details, nil
+		}
+		length := int(binary.BigEndian.Uint16(header[2:4])) - 2
+		if length < 0 {
+			return details, nil
+		}
+
+		isFrame := marker >= 0xc0 && marker <= 0xcf && marker != 0xc4 && marker != 0xc8 && marker != 0xcc
+		if !isFrame && marker != 0xe1 {
+			if _, err := r.Seek(int64(length), io.SeekCurrent); err != nil {
+				return details, err
+			}
+			continue
+		}
+
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(r, segment); err != nil {
+			return details, nil
+		}
+		switch {
+		case isFrame && len(segment) >= 5:
+			details.Height = int(binary.BigEndian.Uint16(segment[1:3]))
+			details.Width = int(binary.BigEndian.Uint16(segment[3:5]))
+		case marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")):
+			if exif, err := parseEXIF(segment[6:]); err == nil {
+				details.EXIF = exif
+			}
+		}
+	}
+}
+
+// readPNGDetails reads the size from IHDR and EXIF from the eXIf chunk.
+func readPNGDetails(r io.ReadSeeker) (imageDetails, error) {
+	var details imageDetails
+	if _, err := r.Seek(8, io.SeekStart); err != nil {
+		return details, err
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return details, nil
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		kind := string(header[4:8])
+
+		switch {
+		case kind == "IHDR" && length >= 8:
+			size := make([]byte, 8)
+			if _, err := io.ReadFull(r, size); err != nil {
+				return details, nil
+			}
+			details.Width = int(binary.BigEndian.Uint32(size[:4]))
+			details.Height = int(binary.BigEndian.Uint32(size[4:]))
+			length -= 8
+		case kind == "eXIf" && length <= maxEXIFSize:
+			data := make([]byte, length)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return details, nil
+			}
+			if exif, err := parseEXIF(data); err == nil {
+				details.EXIF = exif
+			}
+			length = 0
+		case kind == "IEND":
+			return details, nil
+		}
+
+		// Skip the rest of the chunk and its CRC.
+		if _, err := r.Seek(length+4, io.SeekCurrent); err != nil {
+			return details, nil
+		}
+	}
+}
+
+// readWebPDetails reads the canvas size from the VP8X, VP8 or VP8L chunk and
+// EXIF from the EXIF chunk, which extended files store after the image data.
+func readWebPDetails(r io.ReadSeeker) (imageDetails, error) {
+	var details imageDetails
+	if _, err := r.Seek(12, io.SeekStart); err != nil {
+		return details, err
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return details, nil
+		}
+		kind := string(header[:4])
+		length := int64(binary.LittleEndian.Uint32(header[4:]))
+		padded := length + length%2
+
+		var data []byte
+		switch kind {
+		case "VP8X", "VP8 ", "VP8L":
+			data = make([]byte, min(length, 30))
+		case "EXIF":
+			if length <= maxEXIFSize {
+				data = make([]byte, length)
+			}
+		}
+		if _, err := io.ReadFull(r, data); err != nil {
+			return details, nil
+		}
+		if _, err := r.Seek(padded-int64(len(data)), io.SeekCurrent); err != nil {
+			return details, nil
+		}
+
+		switch {
+		case kind == "VP8X" && len(data) >= 10:
+			details.Width = int(uint24(data[4:7])) + 1
+			details.Height = int(uint24(data[7:10])) + 1
+		case kind == "VP8 " && len(data) >= 10 && details.Width == 0:
+			details.Width = int(binary.LittleEndian.Uint16(data[6:8]) & 0x3fff)
+			details.Height = int(binary.LittleEndian.Uint16(data[8:10]) & 0x3fff)
+		case kind == "VP8L" && len(data) >= 5 && details.Width == 0:
+			bits := binary.LittleEndian.Uint32(data[1:5])
+			details.Width = int(bits&0x3fff) + 1
+			details.Height = int((bits>>14)&0x3fff) + 1
+		case kind == "EXIF" && data != nil:
+			if exif, err := parseEXIF(bytes.TrimPrefix(data, []byte("Exif\x00\x00"))); err == nil {
+				details.EXIF = exif
+			}
+		}
+	}
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
+
+// EXIF tags read by parseEXIF.
+const (
+	tagMake             = 0x010f
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagPixelXDimension  = 0xa002
+	tagPixelYDimension  = 0xa003
+	tagGPSLatitudeRef   = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongitudeRef  = 0x0003
+	tagGPSLongitude     = 0x0004
+)
+
+// tiffReader reads entries from a TIFF structure, the container EXIF uses.
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// tiffEntry is one IFD entry with its value bytes resolved.
+type tiffEntry struct {
+	kind  uint16
+	count uint32
+	value []byte
+}
+
+// parseEXIF reads the tags used for naming from a TIFF header and its IFDs.
+func parseEXIF(data []byte) (exifData, error) {
+	if len(data) < 8 {
+		return exifData{}, fmt.Errorf("EXIF block too short")
+	}
+
+	reader := tiffReader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		reader.order = binary.LittleEndian
+	case "MM":
+		reader.order = binary.BigEndian
+	default:
+		return exifData{}, fmt.Errorf("invalid TIFF byte order")
+	}
+	if reader.order.Uint16(data[2:4]) != 42 {
+		return exifData{}, fmt.Errorf("invalid TIFF header")
+	}
+
+	var exif exifData
+	var dateTime string
+	ifd0 := reader.readIFD(reader.order.Uint32(data[4:8]))
+	exif.Make = reader.text(ifd0[tagMake])
+	exif.Model = reader.text(ifd0[tagModel])
+	exif.Orientation = int(reader.uint(ifd0[tagOrientation]))
+	dateTime = reader.text(ifd0[tagDateTime])
+
+	if pointer, ok := ifd0[tagExifIFD]; ok {
+		exifIFD := reader.readIFD(reader.uint(pointer))
+		if original := reader.text(exifIFD[tagDateTimeOriginal]); original != "" {
+			dateTime = original
+		}
+		exif.PixelWidth = int(reader.uint(exifIFD[tagPixelXDimension]))
+		exif.PixelHeight = int(reader.uint(exifIFD[tagPixelYDimension]))
+	}
+	if taken, err := time.Parse("2006:01:02 15:04:05", strings.TrimSpace(dateTime)); err == nil && taken.Year() > 1900 {
+		exif.Taken = taken
+	}
+
+	if pointer, ok := ifd0[tagGPSIFD]; ok {
+		gps := reader.readIFD(reader.uint(pointer))
+		latitude, latOK := reader.degrees(gps[tagGPSLatitude])
+		longitude, lonOK := reader.degrees(gps[tagGPSLongitude])
+		if latOK && lonOK && !(latitude == 0 && longitude == 0) {
+			if strings.EqualFold(reader.text(gps[tagGPSLatitudeRef]), "S") {
+				latitude = -latitude
+			}
+			if strings.EqualFold(reader.text(gps[tagGPSLongitudeRef]), "W") {
+				longitude = -longitude
+			}
+			exif.HasGPS = true
+			exif.Latitude = latitude
+			exif.Longitude = longitude
+		}
+	}
+
+	return exif, nil
+}
+
+// readIFD returns the entries of the IFD at offset keyed by tag. Malformed
+// entries are skipped so one bad tag does not hide the others.
+func (t tiffReader) readIFD(offset uint32) map[uint16]tiffEntry {
+	entries := make(map[uint16]tiffEntry)
+	if offset == 0 || int(offset)+2 > len(t.data) {
+		return entries
+	}
+
+	count := int(t.order.Uint16(t.data[offset:]))
+	for index := 0; index < count; index++ {
+		start := int(offset) + 2 + index*12
+		if start+12 > len(t.data) {
+			break
+		}
+		entry := t.data[start : start+12]
+		tag := t.order.Uint16(entry[0:2])
+		kind := t.order.Uint16(entry[2:4])
+		valueCount := t.order.Uint32(entry[4:8])
+
+		size := tiffTypeSize(kind) * uint64(valueCount)
+		if size == 0 || size > uint64(len(t.data)) {
+			continue
+		}
+		value := entry[8:12]
+		if size > 4 {
+			valueOffset := uint64(t.order.Uint32(entry[8:12]))
+			if valueOffset+size > uint64(len(t.data)) {
+				continue
+			}
+			value = t.data[valueOffset : valueOffset+size]
+		}
+		entries[tag] = tiffEntry{kind: kind, count: valueCount, value: value[:size]}
+	}
+	return entries
+}
+
+func tiffTypeSize(kind uint16) uint64 {
+	switch kind {
+	case 1, 2, 6, 7:
+		return 1
+	case 3, 8:
+		return 2
+	case 4, 9, 11:
+		return 4
+	case 5, 10, 12:
+		return 8
+	}
+	return 0
+}
+
+func (t tiffReader) text(entry tiffEntry) string {
+	if entry.kind != 2 {
+		return ""
+	}
+	value, _, _ := bytes.Cut(entry.value, []byte{0})
+	return strings.TrimSpace(strings.ToValidUTF8(string(value), ""))
+}
+
+func (t tiffReader) uint(entry tiffEntry) uint32 {
+	switch {
+	case entry.kind == 3 && len(entry.value) >= 2:
+		return uint32(t.order.Uint16(entry.value))
+	case entry.kind == 4 && len(entry.value) >= 4:
+		return t.order.Uint32(entry.value)
+	}
+	return 0
+}
+
+// degrees converts a GPS degrees, minutes, seconds rational triple.
+func (t tiffReader) degrees(entry tiffEntry) (float64, bool) {
+	if entry.kind != 5 || entry.count < 3 {
+		return 0, false
+	}
+
+	var parts [3]float64
+	for index := range parts {
+		numerator := t.order.Uint32(entry.value[index*8:])
+		denominator := t.order.Uint32(entry.value[index*8+4:])
+		if denominator == 0 {
+			if numerator != 0 {
+				return 0, false
+			}
+			continue
+		}
+		parts[index] = float64(numerator) / float64(denominator)
+	}
+
+	value := parts[0] + parts[1]/60 + parts[2]/3600
+	if math.IsNaN(value) || value > 180 {
+		return 0, false
+	}
+	return value, true
+}
+
+// orientationNames describes the EXIF orientation values.
+var orientationNames = map[int]string{
+	1: "normal",
+	2: "mirrored horizontally",
+	3: "rotated 180°",
+	4: "mirrored vertically",
+	5: "mirrored and rotated 90° counterclockwise",
+	6: "rotated 90° clockwise",
+	7: "mirrored and rotated 90° clockwise",
+	8: "rotated 90° counterclockwise",
+}
+
+// describe lists the details as context lines and metadata fields.
+func (d imageDetails) describe() ([]string, map[string]string) {
+	var lines []string
+	metadata := make(map[string]string)
+
+	width, height := d.Width, d.Height
+	if width == 0 || height == 0 {
+		width, height = d.EXIF.PixelWidth, d.EXIF.PixelHeight
+	}
+	if width > 0 && height > 0 {
+		lines = append(lines, fmt.Sprintf("Dimensions: %dx%d", width, height))
+		metadata["width"] = strconv.Itoa(width)
+		metadata["height"] = strconv.Itoa(height)
+	}
+
+	exif := d.EXIF
+	if !exif.Taken.IsZero() {
+		lines = append(lines, "Taken: "+exif.Taken.Format("2006-01-02 15:04:05"))
+		metadata["date_taken"] = exif.Taken.Format("2006-01-02")
+		metadata["year"] = exif.Taken.Format("2006")
+	}
+
+	camera := exif.Model
+	if exif.Make != "" && !strings.HasPrefix(strings.ToLower(exif.Model), strings.ToLower(exif.Make)) {
+		camera = strings.TrimSpace(exif.Make + " " + exif.Model)
+	}
+	if camera != "" {
+		lines = append(lines, "Camera: "+camera)
+		metadata["camera"] = camera
+	}
+	if exif.Make != "" {
+		metadata["camera_make"] = exif.Make
+	}
+	if exif.Model != "" {
+		metadata["camera_model"] = exif.Model
+	}
+
+	if name, ok := orientationNames[exif.Orientation]; ok && exif.Orientation != 1 {
+		lines = append(lines, "Orientation: "+name)
+	}
+	if exif.HasGPS {
+		lines = append(lines, fmt.Sprintf("GPS: %.6f, %.6f", exif.Latitude, exif.Longitude))
+		metadata["gps_latitude"] = strconv.FormatFloat(exif.Latitude, 'f', 6, 64)
+		metadata["gps_longitude"] = strconv.FormatFloat(exif.Longitude, 'f', 6, 64)
+	}
+	return lines, metadata
+}
@@ -0,0 +1,215 @@
+package files
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type tiffTestOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+type tiffTestEntry struct {
+	tag   uint16
+	kind  uint16
+	count uint32
+	data  []byte
+}
+
+// buildTIFF lays out IFD0 followed by the EXIF and GPS IFDs it points to,
+// with values that do not fit an entry stored after the IFDs.
+func buildTIFF(order tiffTestOrder, ifd0, exif, gps []tiffTestEntry) []byte {
+	ifdSize := func(entries []tiffTestEntry) int { return 2 + 12*len(entries) + 4 }
+	ifd0 = append(ifd0, tiffTestEntry{tag: tagExifIFD, kind: 4, count: 1}, tiffTestEntry{tag: tagGPSIFD, kind: 4, count: 1})
+	exifOffset := 8 + ifdSize(ifd0)
+	gpsOffset := exifOffset + ifdSize(exif)
+	dataOffset := gpsOffset + ifdSize(gps)
+	ifd0[len(ifd0)-2].data = order.AppendUint32(nil, uint32(exifOffset))
+	ifd0[len(ifd0)-1].data = order.AppendUint32(nil, uint32(gpsOffset))
+
+	var out, extra []byte
+	if order == tiffTestOrder(binary.LittleEndian) {
+		out = append(out, "II"...)
+	} else {
+		out = append(out, "MM"...)
+	}
+	out = order.AppendUint16(out, 42)
+	out = order.AppendUint32(out, 8)
+	for _, entries := range [][]tiffTestEntry{ifd0, exif, gps} {
+		out = order.AppendUint16(out, uint16(len(entries)))
+		for _, entry := range entries {
+			out = order.AppendUint16(out, entry.tag)
+			out = order.AppendUint16(out, entry.kind)
+			out = order.AppendUint32(out, entry.count)
+			if len(entry.data) <= 4 {
+				out = append(out, entry.data...)
+				out = append(out, make([]byte, 4-len(entry.data))...)
+				continue
+			}
+			out = order.AppendUint32(out, uint32(dataOffset+len(extra)))
+			extra = append(extra, entry.data...)
+		}
+		out = order.AppendUint32(out, 0)
+	}
+	return append(out, extra...)
+}
+
+func sampleTIFF(order tiffTestOrder) []byte {
+	ascii := func(tag uint16, value string) tiffTestEntry {
+		return tiffTestEntry{tag: tag, kind: 2, count: uint32(len(value) + 1), data: append([]byte(value), 0)}
+	}
+	rationals := func(tag uint16, values ...uint32) tiffTestEntry {
+		var data []byte
+		for _, value := range values {
+			data = order.AppendUint32(data, value)
+		}
+		return tiffTestEntry{tag: tag, kind: 5, count: uint32(len(values) / 2), data: data}
+	}
+
+	return buildTIFF(order,
+		[]tiffTestEntry{
+			ascii(tagMake, "Apple"),
+			ascii(tagModel, "iPhone 12"),
+			{tag: tagOrientation, kind: 3, count: 1, data: order.AppendUint16(nil, 6)},
+			ascii(tagDateTime, "2024:01:01 00:00:00"),
+		},
+		[]tiffTestEntry{ascii(tagDateTimeOriginal, "2023:07:14 18:22:05")},
+		[]tiffTestEntry{
+			ascii(tagGPSLatitudeRef, "N"),
+			rationals(tagGPSLatitude, 48, 1, 51, 1, 3012, 100),
+			ascii(tagGPSLongitudeRef, "W"),
+			rationals(tagGPSLongitude, 2, 1, 17, 1, 4020, 100),
+		},
+	)
+}
+
+func TestParseEXIF(t *testing.T) {
+	for _, order := range []tiffTestOrder{binary.BigEndian, binary.LittleEndian} {
+		exif, err := parseEXIF(sampleTIFF(order))
+		if err != nil {
+			t.Fatalf("parseEXIF(%s) error = %v", order, err)
+		}
+		if exif.Make != "Apple" || exif.Model != "iPhone 12" || exif.Orientation != 6 {
+			t.Fatalf("parseEXIF(%s) = %+v, want the camera and orientation", order, exif)
+		}
+		if got := exif.Taken.Format("2006-01-02 15:04:05"); got != "2023-07-14 18:22:05" {
+			t.Fatalf("Taken = %s, want DateTimeOriginal over DateTime", got)
+		}
+		if !exif.HasGPS || exif.Latitude < 48.858 || exif.Latitude > 48.859 || exif.Longitude > -2.294 || exif.Longitude < -2.295 {
+			t.Fatalf("GPS = %v, %v, want 48.8584, -2.2945", exif.Latitude, exif.Longitude)
+		}
+	}
+
+	if _, err := parseEXIF([]byte("not a tiff header")); err == nil {
+		t.Fatal("parseEXIF() error = nil, want an invalid header error")
+	}
+	if _, err := parseEXIF(append([]byte("MM\x00\x2a\xff\xff\xff\xf0"), make([]byte, 8)...)); err != nil {
+		t.Fatalf("parseEXIF() with an out-of-range IFD error = %v, want empty data", err)
+	}
+}
+
+func TestReadImageDetails(t *testing.T) {
+	exif := sampleTIFF(binary.BigEndian)
+
+	var jpeg bytes.Buffer
+	jpeg.WriteString("\xff\xd8\xff\xe1")
+	binary.Write(&jpeg, binary.BigEndian, uint16(len(exif)+8))
+	jpeg.WriteString("Exif\x00\x00")
+	jpeg.Write(exif)
+	jpeg.WriteString("\xff\xc0\x00\x11\x08\x0b\xd0\x0f\xc0\x03\x01\x22\x00\x02\x11\x01\x03\x11\x01")
+	jpeg.WriteString("\xff\xda\x00\x02\xff\xd9")
+
+	var png bytes.Buffer
+	png.WriteString("\x89PNG\r\n\x1a\n")
+	writePNGChunk := func(kind string, data []byte) {
+		binary.Write(&png, binary.BigEndian, uint32(len(data)))
+		png.WriteString(kind)
+		png.Write(data)
+		png.Write([]byte{0, 0, 0, 0})
+	}
+	writePNGChunk("IHDR", []byte{0, 0, 0x0f, 0xc0, 0, 0, 0x0b, 0xd0, 8, 2, 0, 0, 0})
+	writePNGChunk("IDAT", []byte{1, 2, 3})
+	writePNGChunk("eXIf", exif)
+	writePNGChunk("IEND", nil)
+
+	var chunks bytes.Buffer
+	writeWebPChunk := func(kind string, data []byte) {
+		chunks.WriteString(kind)
+		binary.Write(&chunks, binary.LittleEndian, uint32(len(data)))
+		chunks.Write(data)
+		if len(data)%2 == 1 {
+			chunks.WriteByte(0)
+		}
+	}
+	writeWebPChunk("VP8X", []byte{0x08, 0, 0, 0, 0xbf, 0x0f, 0, 0xcf, 0x0b, 0})
+	writeWebPChunk("VP8 ", []byte{1, 2, 3})
+	writeWebPChunk("EXIF", append([]byte("Exif\x00\x00"), exif...))
+	webp := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(chunks.Len()+4))...)
+	webp = append(append(webp, "WEBP"...), chunks.Bytes()...)
+
+	for name, data := range map[string][]byte{"photo.jpg": jpeg.Bytes(), "photo.png": png.Bytes(), "photo.webp": webp} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			details, err := readImageDetails(path)
+			if err != nil {
+				t.Fatalf("readImageDetails() error = %v", err)
+			}
+			if details.Width != 4032 || details.Height != 3024 {
+				t.Fatalf("size = %dx%d, want 4032x3024", details.Width, details.Height)
+			}
+			if details.EXIF.Model != "iPhone 12" || details.EXIF.Taken.IsZero() {
+				t.Fatalf("EXIF = %+v, want the sample tags", details.EXIF)
+			}
+		})
+	}
+}
+
+func TestExtractFileContentImageIncludesEXIF(t *testing.T) {
+	exif := sampleTIFF(binary.LittleEndian)
+	var jpeg bytes.Buffer
+	jpeg.WriteString("\xff\xd8\xff\xe1")
+	binary.Write(&jpeg, binary.BigEndian, uint16(len(exif)+8))
+	jpeg.WriteString("Exif\x00\x00")
+	jpeg.Write(exif)
+	jpeg.WriteString("\xff\xd9")
+
+	path := filepath.Join(t.TempDir(), "IMG_0001.jpg")
+	if err := os.WriteFile(path, jpeg.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if content.PreviewImagePath != path || !strings.Contains(content.Text, "image preview is available") {
+		t.Fatalf("ExtractFileContent() = %+v, want the visual preview", content)
+	}
+	for _, want := range []string{"Taken: 2023-07-14 18:22:05", "Camera: Apple iPhone 12", "Orientation: rotated 90° clockwise", "GPS: 48.858367, -2.294500"} {
+		if !strings.Contains(content.Text, want) {
+			t.Fatalf("Text = %q, want %q", content.Text, want)
+		}
+	}
+	if content.Metadata["date_taken"] != "2023-07-14" || content.Metadata["camera_model"] != "iPhone 12" {
+		t.Fatalf("Metadata = %v, want date_taken and camera_model", content.Metadata)
+	}
+
+	opts := DefaultExtractOptions()
+	opts.ExtractMetadata = false
+	content, err = ExtractFileContent(path, opts)
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if strings.Contains(content.Text, "GPS") || content.Metadata != nil {
+		t.Fatalf("ExtractFileContent() = %+v, want no EXIF without extract_metadata", content)
+	}
+}
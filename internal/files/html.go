@@ -0,0 +1,329 @@
+package files
+
+import (
+	"cmp"
+	"html"
+	"slices"
+	"strings"
+)
+
+// maxHeadings caps the headings listed for a page.
+const maxHeadings = 20
+
+// htmlSkipped elements hold code or markup rather than readable text.
+var htmlSkipped = []string{"script", "style", "noscript", "template", "svg", "math", "iframe", "object", "canvas"}
+
+// htmlBlocks start a new line of visible text.
+var htmlBlocks = []string{
+	"address", "article", "aside", "blockquote", "br", "dd", "div", "dl", "dt", "figcaption", "figure", "footer",
+	"form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "li", "main", "nav", "ol", "p", "pre", "section",
+	"table", "td", "th", "tr", "ul",
+}
+
+// htmlMetaFields maps meta names and properties to the fields they fill; the
+// first value found for a field is kept.
+var htmlMetaFields = map[string]string{
+	"description":               "description",
+	"og:description":            "description",
+	"twitter:description":       "description",
+	"author":                    "author",
+	"article:author":            "author",
+	"dc.creator":                "author",
+	"citation_author":           "author",
+	"keywords":                  "keywords",
+	"og:title":                  "title",
+	"twitter:title":             "title",
+	"date":                      "date",
+	"dc.date":                   "date",
+	"dcterms.created":           "date",
+	"article:published_time":    "date",
+	"og:published_time":         "date",
+	"citation_publication_date": "date",
+	"citation_date":             "date",
+	"datepublished":             "date",
+	"pubdate":                   "date",
+}
+
+// htmlPage is what a page says about itself.
+type htmlPage struct {
+	title    string
+	language string
+	meta     map[string]string
+	headings []string
+	text     string
+}
+
+// htmlTag is one start or end tag.
+type htmlTag struct {
+	name        string
+	end         bool
+	selfClosing bool
+	attrs       map[string]string
+}
+
+func extractHTML(path string, _ FileType, opts ExtractOptions) (ExtractedContent, error) {
+	data, err := readPrefix(path, maxMarkupSize)
+	if err != nil {
+		return ExtractedContent{}, err
+	}
+	page := parseHTML(decodeText(data))
+
+	lines := []string{"HTML page"}
+	metadata := make(map[string]string)
+	title := page.title
+	if title == "" {
+		title = page.meta["title"]
+	}
+	if title != "" {
+		lines = append(lines, "Title: "+title)
+		metadata["title"] = title
+	}
+	if page.language != "" {
+		lines = append(lines, "Language: "+page.language)
+	}
+	for _, field := range []struct{ key, label string }{{"description", "Description"}, {"author", "Author"}, {"keywords", "Keywords"}} {
+		if value := page.meta[field.key]; value != "" {
+			lines = append(lines, field.label+": "+value)
+			metadata[field.key] = value
+		}
+	}
+	if value := page.meta["date"]; value != "" {
+		if published, ok := parseLooseDate(value); ok {
+			value = published.Format("2006-01-02")
+			metadata["created"] = value
+			metadata["year"] = published.Format("2006")
+		}
+		lines = append(lines, "Published: "+value)
+	}
+
+	if opts.ExtractText {
+		if len(page.headings) > 0 {
+			lines = append(lines, "Headings:")
+			for _, heading := range page.headings {
+				lines = append(lines, "- "+heading)
+			}
+		}
+		if page.text != "" {
+			lines = append(lines, "Text:", page.text)
+		}
+	}
+
+	if !opts.ExtractMetadata {
+		metadata = nil
+	}
+	return ExtractedContent{
+		Text:     strings.Join(lines, "\n"),
+		Metadata: metadata,
+		Sparse:   title == "" && page.text == "",
+	}, nil
+}
+
+// parseHTML walks the tags of a page. It is lenient by design: unclosed and
+// stray tags are ignored, and text is collected wherever it appears outside
+// the head and the skipped elements.
+func parseHTML(markup string) htmlPage {
+	page := htmlPage{meta: make(map[string]string)}
+	lower := asciiLower(markup)
+
+	var title, heading, body strings.Builder
+	var inHead, inTitle bool
+	headingLevel := ""
+
+	addText := func(raw string) {
+		if strings.TrimSpace(raw) == "" {
+			if body.Len() > 0 && !inHead {
+				body.WriteByte(' ')
+			}
+			return
+		}
+		text := html.UnescapeString(raw)
+		switch {
+		case inTitle:
+			title.WriteString(text)
+		case inHead:
+		default:
+			body.WriteString(text)
+			if headingLevel != "" {
+				heading.WriteString(text)
+			}
+		}
+	}
+
+	pos := 0
+	for pos < len(markup) {
+		lt := strings.IndexByte(markup[pos:], '<')
+		if lt < 0 {
+			addText(markup[pos:])
+			break
+		}
+		addText(markup[pos : pos+lt])
+		pos += lt
+
+		switch {
+		case strings.HasPrefix(markup[pos:], "<!--"):
+			end := strings.Index(markup[pos+4:], "-->")
+			if end < 0 {
+				pos = len(markup)
+				continue
+			}
+			pos += 4 + end + 3
+			continue
+		case strings.HasPrefix(markup[pos:], "<!") || strings.HasPrefix(markup[pos:], "<?"):
+			end := strings.IndexByte(markup[pos:], '>')
+			if end < 0 {
+				pos = len(markup)
+				continue
+			}
+			pos += end + 1
+			continue
+		}
+
+		tag, length, ok := parseHTMLTag(markup[pos:])
+		if !ok {
+			addText("<")
+			pos++
+			continue
+		}
+		pos += length
+
+		if !tag.end && !tag.selfClosing && slices.Contains(htmlSkipped, tag.name) {
+			end := strings.Index(lower[pos:], "</"+tag.name)
+			if end < 0 {
+				break
+			}
+			pos += end
+			continue
+		}
+
+		if slices.Contains(htmlBlocks, tag.name) {
+			body.WriteByte('\n')
+		}
+		switch tag.name {
+		case "html":
+			if lang := tag.attrs["lang"]; lang != "" && !tag.end {
+				page.language = lang
+			}
+		case "head":
+			inHead = !tag.end
+		case "body":
+			inHead = false
+		case "title":
+			inTitle = !tag.end && !tag.selfClosing
+		case "meta":
+			name := cmp.Or(tag.attrs["name"], tag.attrs["property"], tag.attrs["itemprop"])
+			field, known := htmlMetaFields[strings.ToLower(name)]
+			content := collapseSpace(tag.attrs["content"])
+			if known && content != "" && page.meta[field] == "" {
+				page.meta[field] = content
+			}
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			switch {
+			case !tag.end:
+				headingLevel = tag.name
+				heading.Reset()
+			case tag.name == headingLevel:
+				if text := collapseSpace(heading.String()); text != "" && len(page.headings) < maxHeadings && !slices.Contains(page.headings, text) {
+					page.headings = append(page.headings, text)
+				}
+				headingLevel = ""
+			}
+		}
+	}
+
+	page.title = collapseSpace(title.String())
+	var lines []string
+	for line := range strings.SplitSeq(body.String(), "\n") {
+		if line = collapseSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	page.text = strings.Join(lines, "\n")
+	return page
+}
+
+// parseHTMLTag reads the tag at the start of markup and returns its length.
+// Attribute names are lowercased and values unescaped.
+func parseHTMLTag(markup string) (htmlTag, int, bool) {
+	tag := htmlTag{attrs: make(map[string]string)}
+	pos := 1
+	if pos < len(markup) && markup[pos] == '/' {
+		tag.end = true
+		pos++
+	}
+
+	start := pos
+	// Tag names start with a letter; "a < b" is text.
+	if pos >= len(markup) || markup[pos]|0x20 < 'a' || markup[pos]|0x20 > 'z' {
+		return htmlTag{}, 0, false
+	}
+	for pos < len(markup) && isTagNameByte(markup[pos]) {
+		pos++
+	}
+	if pos == start {
+		return htmlTag{}, 0, false
+	}
+	tag.name = asciiLower(markup[start:pos])
+
+	for pos < len(markup) {
+		switch char := markup[pos]; {
+		case char == '>':
+			return tag, pos + 1, true
+		case char == '/':
+			tag.selfClosing = strings.HasPrefix(markup[pos:], "/>")
+			pos++
+		case char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == '\f':
+			pos++
+		default:
+			nameStart := pos
+			for pos < len(markup) && !strings.ContainsRune(" \t\n\r\f=>/", rune(markup[pos])) {
+				pos++
+			}
+			name := asciiLower(markup[nameStart:pos])
+			for pos < len(markup) && strings.ContainsRune(" \t\n\r\f", rune(markup[pos])) {
+				pos++
+			}
+			if pos >= len(markup) || markup[pos] != '=' {
+				tag.attrs[name] = ""
+				continue
+			}
+			pos++
+			for pos < len(markup) && strings.ContainsRune(" \t\n\r\f", rune(markup[pos])) {
+				pos++
+			}
+
+			var value string
+			if pos < len(markup) && (markup[pos] == '"' || markup[pos] == '\'') {
+				quote := markup[pos]
+				end := strings.IndexByte(markup[pos+1:], quote)
+				if end < 0 {
+					return htmlTag{}, 0, false
+				}
+				value = markup[pos+1 : pos+1+end]
+				pos += end + 2
+			} else {
+				valueStart := pos
+				for pos < len(markup) && !strings.ContainsRune(" \t\n\r\f>", rune(markup[pos])) {
+					pos++
+				}
+				value = markup[valueStart:pos]
+			}
+			tag.attrs[name] = html.UnescapeString(value)
+		}
+	}
+	return htmlTag{}, 0, false
+}
+
+func isTagNameByte(char byte) bool {
+	return char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || char == '-' || char == ':'
+}
+
+// asciiLower lowercases ASCII letters only, so byte offsets stay valid.
+func asciiLower(text string) string {
+	lower := []byte(text)
+	for index, char := range lower {
+		if char >= 'A' && char <= 'Z' {
+			lower[index] = char + 'a' - 'A'
+		}
+	}
+	return string(lower)
+}
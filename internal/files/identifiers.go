@@ -0,0 +1,147 @@
+package files
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxIdentifiers caps the identifiers of each kind listed for a document.
+const maxIdentifiers = 5
+
+var (
+	doiPattern   = regexp.MustCompile(`\b10\.\d{4,9}/[^\s"<>]+`)
+	arxivPattern = regexp.MustCompile(`(?i)\barxiv(?:\.org/(?:abs|pdf)/|:\s*)((?:\d{4}\.\d{4,5}|[a-z-]+(?:\.[a-z]{2})?/\d{7})(?:v\d+)?)`)
+	isbnPattern  = regexp.MustCompile(`(?i)\bISBN(?:-1[03])?:?\s*([0-9][0-9 -]{8,20}[0-9X]?)`)
+)
+
+// documentIdentifiers are the citable identifiers found in a document's text.
+type documentIdentifiers struct {
+	DOIs  []string
+	ArXiv []string
+	ISBNs []string
+}
+
+// findIdentifiers looks for DOIs, arXiv IDs and labelled ISBNs in text. ISBNs
+// must pass their checksum; each list keeps the order of first appearance.
+func findIdentifiers(text string) documentIdentifiers {
+	var ids documentIdentifiers
+	for _, match := range doiPattern.FindAllString(text, -1) {
+		ids.DOIs = appendIdentifier(ids.DOIs, trimDOI(match))
+	}
+	for _, match := range arxivPattern.FindAllStringSubmatch(text, -1) {
+		ids.ArXiv = appendIdentifier(ids.ArXiv, match[1])
+	}
+	for _, match := range isbnPattern.FindAllStringSubmatch(text, -1) {
+		if isbn := normalizeISBN(match[1]); isbn != "" {
+			ids.ISBNs = appendIdentifier(ids.ISBNs, isbn)
+		}
+	}
+	return ids
+}
+
+// describe lists the identifiers for the context and returns the first of each
+// kind as the doi, arxiv and isbn metadata fields.
+func (ids documentIdentifiers) describe() ([]string, map[string]string) {
+	var lines []string
+	metadata := make(map[string]string)
+	for _, kind := range []struct {
+		key, label string
+		values     []string
+	}{{"doi", "DOI", ids.DOIs}, {"arxiv", "arXiv", ids.ArXiv}, {"isbn", "ISBN", ids.ISBNs}} {
+		if len(kind.values) == 0 {
+			continue
+		}
+		lines = append(lines, kind.label+": "+strings.Join(kind.values, ", "))
+		metadata[kind.key] = kind.values[0]
+	}
+	return lines, metadata
+}
+
+func appendIdentifier(values []string, value string) []string {
+	if value == "" || len(values) == maxIdentifiers {
+		return values
+	}
+	for _, existing := range values {
+		if strings.EqualFold(existing, value) {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// trimDOI drops the sentence punctuation that follows a DOI in running text,
+// keeping closing brackets that belong to the DOI itself.
+func trimDOI(doi string) string {
+	for doi != "" {
+		last := doi[len(doi)-1]
+		switch {
+		case strings.IndexByte(".,;:'", last) >= 0:
+		case last == ')' && strings.Count(doi, "(") < strings.Count(doi, ")"):
+		case last == ']' && strings.Count(doi, "[") < strings.Count(doi, "]"):
+		default:
+			return doi
+		}
+		doi = doi[:len(doi)-1]
+	}
+	return doi
+}
+
+// normalizeISBN strips separators and returns the ISBN-13 or ISBN-10 at the
+// start of candidate, or "" when neither checksum holds.
+func normalizeISBN(candidate string) string {
+	digits := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(candidate))
+	if len(digits) >= 13 && validISBN13(digits[:13]) {
+		return digits[:13]
+	}
+	if len(digits) >= 10 && validISBN10(digits[:10]) {
+		return digits[:10]
+	}
+	return ""
+}
+
+// IsISBN13 reports whether the digits of value form an ISBN-13 with a valid
+// check digit. Redaction uses it to tell book numbers from phone numbers.
+func IsISBN13(value string) bool {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+	return len(digits) == 13 && validISBN13(digits)
+}
+
+func validISBN13(isbn string) bool {
+	if !strings.HasPrefix(isbn, "978") && !strings.HasPrefix(isbn, "979") {
+		return false
+	}
+	sum := 0
+	for index, char := range isbn {
+		if char < '0' || char > '9' {
+			return false
+		}
+		weight := 1
+		if index%2 == 1 {
+			weight = 3
+		}
+		sum += int(char-'0') * weight
+	}
+	return sum%10 == 0
+}
+
+func validISBN10(isbn string) bool {
+	sum := 0
+	for index, char := range isbn {
+		var value int
+		switch {
+		case char >= '0' && char <= '9':
+			value = int(char - '0')
+		case char == 'X' && index == 9:
+			value = 10
+		default:
+			return false
+		}
+		sum += value * (10 - index)
+	}
+	return sum%11 == 0
+}
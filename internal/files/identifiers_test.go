@@ -0,0 +1,39 @@
+package files
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindIdentifiers(t *testing.T) {
+	text := `Published in Nature (2021). doi:10.1038/s41586-021-03819-2.
+See also (https://doi.org/10.1016/S0140-6736(20)30183-5) and DOI 10.1038/S41586-021-03819-2.
+arXiv:2106.09685v2 [cs.LG] 16 Oct 2021, earlier as https://arxiv.org/abs/hep-th/9711200 and arXiv: math.GT/0309136.
+ISBN 978-0-306-40615-7 (hardcover), ISBN-10: 0-8044-2957-X, ISBN 978-0-306-40615-8 is a typo.`
+
+	ids := findIdentifiers(text)
+	if want := []string{"10.1038/s41586-021-03819-2", "10.1016/S0140-6736(20)30183-5"}; !slices.Equal(ids.DOIs, want) {
+		t.Fatalf("DOIs = %q, want %q", ids.DOIs, want)
+	}
+	if want := []string{"2106.09685v2", "hep-th/9711200", "math.GT/0309136"}; !slices.Equal(ids.ArXiv, want) {
+		t.Fatalf("ArXiv = %q, want %q", ids.ArXiv, want)
+	}
+	if want := []string{"9780306406157", "080442957X"}; !slices.Equal(ids.ISBNs, want) {
+		t.Fatalf("ISBNs = %q, want %q", ids.ISBNs, want)
+	}
+
+	lines, metadata := ids.describe()
+	if len(lines) != 3 || lines[1] != "arXiv: 2106.09685v2, hep-th/9711200, math.GT/0309136" {
+		t.Fatalf("lines = %q, want one line per kind", lines)
+	}
+	if metadata["doi"] != "10.1038/s41586-021-03819-2" || metadata["arxiv"] != "2106.09685v2" || metadata["isbn"] != "9780306406157" {
+		t.Fatalf("metadata = %v, want the first identifier of each kind", metadata)
+	}
+}
+
+func TestFindIdentifiersNone(t *testing.T) {
+	ids := findIdentifiers("Version 10.2 was released; call 555-0100. ISBN pending.")
+	if lines, metadata := ids.describe(); len(lines) != 0 || len(metadata) != 0 {
+		t.Fatalf("describe() = %q, %v, want nothing", lines, metadata)
+	}
+}
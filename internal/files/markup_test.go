@@ -0,0 +1,164 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractFileContentHTML(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html lang="de">
+<head>
+  <title>Quarterly Report &amp; Outlook</title>
+  <meta name="description" content="Revenue grew 12% in Q3">
+  <meta name="author" content="Jane Doe">
+  <meta property="article:published_time" content="2024-10-02T09:30:00Z">
+  <style>body { color: red; }</style>
+  <script>var secret = "<p>not text</p>";</script>
+</head>
+<body>
+  <!-- navigation removed -->
+  <h1>Results</h1>
+  <p>Sales were <b>strong</b> in every region.</p>
+  <h2>Outlook  for 2025</h2>
+  <ul><li>Hire</li><li>Expand</li></ul>
+  <noscript>Enable JavaScript</noscript>
+  <p>a < b and 3 > 2</p>
+</body>
+</html>`
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := os.WriteFile(path, []byte(page), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	for _, want := range []string{
+		"Title: Quarterly Report & Outlook",
+		"Language: de",
+		"Description: Revenue grew 12% in Q3",
+		"Author: Jane Doe",
+		"Published: 2024-10-02",
+		"- Results\n- Outlook for 2025",
+		"Sales were strong in every region.",
+		"Hire\nExpand",
+		"a < b and 3 > 2",
+	} {
+		if !strings.Contains(content.Text, want) {
+			t.Fatalf("Text = %q, want %q", content.Text, want)
+		}
+	}
+	for _, unwanted := range []string{"color: red", "secret", "Enable JavaScript", "navigation"} {
+		if strings.Contains(content.Text, unwanted) {
+			t.Fatalf("Text = %q, should not include %q", content.Text, unwanted)
+		}
+	}
+	if content.Metadata["title"] != "Quarterly Report & Outlook" || content.Metadata["created"] != "2024-10-02" {
+		t.Fatalf("Metadata = %v, want the title and publication date", content.Metadata)
+	}
+}
+
+func TestExtractFileContentXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		data     string
+		category string
+		want     []string
+		title    string
+	}{
+		{
+			name: "RSS feed",
+			file: "feed.xml",
+			data: `<?xml version="1.0" encoding="ISO-8859-1"?>
+<rss version="2.0"><channel>
+  <title>Caf` + "\xe9" + ` News</title>
+  <description>Daily updates</description>
+  <lastBuildDate>Tue, 01 Oct 2024 08:00:00 +0000</lastBuildDate>
+  <item><title>Opening hours</title></item>
+  <item><title><![CDATA[New menu]]></title></item>
+</channel></rss>`,
+			category: CategoryOthers,
+			want:     []string{"XML document: RSS feed", "Title: Café News", "Updated: 2024-10-01", "Items: 2", "- Opening hours\n- New menu"},
+			title:    "Café News",
+		},
+		{
+			name: "Atom feed",
+			file: "updates.atom",
+			data: `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+  <title>Release notes</title><updated>2024-09-30T12:00:00Z</updated>
+  <author><name>Build Bot</name></author>
+  <entry><title>v1.2.0</title></entry>
+</feed>`,
+			category: CategoryOthers,
+			want:     []string{"XML document: Atom feed", "Root: feed (http://www.w3.org/2005/Atom)", "Namespaces: media=http://search.yahoo.com/mrss/", "Author: Build Bot", "Entries: 1", "- v1.2.0"},
+			title:    "Release notes",
+		},
+		{
+			name: "SVG drawing",
+			file: "logo.svg",
+			data: `<svg xmlns="http://www.w3.org/2000/svg" width="120" height="40" viewBox="0 0 120 40">
+  <title>Acme logo</title><desc>Company wordmark</desc>
+  <text x="0" y="20">ACME <tspan>Corp</tspan></text>
+</svg>`,
+			category: CategoryImages,
+			want:     []string{"XML document: SVG image", "Title: Acme logo", "Description: Company wordmark", "Size: width=120 height=40 viewBox=0 0 120 40", "Text: ACME | Corp"},
+			title:    "Acme logo",
+		},
+		{
+			name: "Generic XML",
+			file: "pom.xml",
+			data: `<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <artifactId>billing-service</artifactId>
+  <dependencies><dependency><artifactId>json</artifactId></dependency></dependencies>
+  <dependency/><dependency/>
+</project>`,
+			category: CategoryOthers,
+			want:     []string{"Root: project (http://maven.apache.org/POM/4.0.0)", "Elements: 7", "Top-level elements: dependency (2), artifactId (1), dependencies (1)", "project/artifactId: billing-service"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			content, err := ExtractFileContent(path, DefaultExtractOptions())
+			if err != nil {
+				t.Fatalf("ExtractFileContent() error = %v", err)
+			}
+			if content.Category != tt.category {
+				t.Fatalf("Category = %q, want %q", content.Category, tt.category)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(content.Text, want) {
+					t.Fatalf("Text = %q, want %q", content.Text, want)
+				}
+			}
+			if content.Metadata["title"] != tt.title {
+				t.Fatalf("Metadata = %v, want title %q", content.Metadata, tt.title)
+			}
+		})
+	}
+}
+
+func TestExtractFileContentMalformedXMLFallsBackToText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.xml")
+	if err := os.WriteFile(path, []byte("just some notes, not markup"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if content.Text != "just some notes, not markup" {
+		t.Fatalf("Text = %q, want the raw text", content.Text)
+	}
+}
@@ -0,0 +1,865 @@
+package files
+
+import (
+	"archive/zip"
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gen2brain/go-fitz"
+)
+
+const (
+	// maxOfficePartSize caps the decompressed size read from one part of an
+	// office file, which protects against zip bombs.
+	maxOfficePartSize = 64 << 20
+	// maxSheets caps the sheets that are summarized.
+	maxSheets = 10
+	// maxRepeatedRows caps how often an OpenDocument row repeated with
+	// number-rows-repeated is counted.
+	maxRepeatedRows = 10000
+)
+
+// officeProperties are the document properties of OOXML core.xml and app.xml
+// or ODF meta.xml.
+type officeProperties struct {
+	title       string
+	subject     string
+	author      string
+	description string
+	keywords    string
+	modifiedBy  string
+	application string
+	created     time.Time
+	modified    time.Time
+	statistics  []string
+}
+
+// officeSheet is a spreadsheet tab with a summary of its rows.
+type officeSheet struct {
+	name  string
+	size  string
+	table tableSummary
+}
+
+// excelEpoch is day zero of the 1900 date system, allowing for its leap year bug.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func extractOffice(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fallbackDocumentContent(path, fmt.Errorf("opening office document: %w", err))
+	}
+	defer archive.Close()
+
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+
+	var props officeProperties
+	var body []string
+	ooxml := parts["[Content_Types].xml"] != nil
+	switch {
+	case ooxml:
+		props = readOOXMLProperties(parts)
+		if opts.ExtractText {
+			body, err = readOOXMLBody(parts)
+		}
+	case parts["content.xml"] != nil || parts["meta.xml"] != nil:
+		props = readODFProperties(parts)
+		if opts.ExtractText {
+			body, err = readODFBody(parts)
+		}
+	default:
+		return fallbackDocumentContent(path, errors.New("not an OOXML or OpenDocument file"))
+	}
+	if err != nil && len(body) == 0 && props.title == "" {
+		return fallbackDocumentContent(path, err)
+	}
+
+	lines := []string{cmp.Or(fileType.Description, "Office document")}
+	metadata := make(map[string]string)
+	for _, field := range []struct{ key, label, value string }{
+		{"title", "Title", props.title},
+		{"subject", "Subject", props.subject},
+		{"author", "Author", props.author},
+		{"keywords", "Keywords", props.keywords},
+		{"description", "Description", props.description},
+		{"", "Last modified by", props.modifiedBy},
+		{"", "Application", props.application},
+	} {
+		if field.value == "" {
+			continue
+		}
+		lines = append(lines, field.label+": "+field.value)
+		if field.key != "" {
+			metadata[field.key] = field.value
+		}
+	}
+	if !props.created.IsZero() {
+		lines = append(lines, "Created: "+props.created.Format("2006-01-02"))
+		metadata["created"] = props.created.Format("2006-01-02")
+		metadata["year"] = props.created.Format("2006")
+	}
+	if !props.modified.IsZero() {
+		lines = append(lines, "Modified: "+props.modified.Format("2006-01-02"))
+		metadata["modified"] = props.modified.Format("2006-01-02")
+	}
+	if len(props.statistics) > 0 {
+		lines = append(lines, strings.Join(props.statistics, ", "))
+	}
+	lines = append(lines, body...)
+
+	content := ExtractedContent{
+		Text:   strings.Join(lines, "\n"),
+		Sparse: props.title == "" && len(body) == 0,
+	}
+	if opts.ExtractMetadata && len(metadata) > 0 {
+		content.Metadata = metadata
+	}
+	if ooxml {
+		// MuPDF lays out OOXML documents, so vision models can still see the first page.
+		if doc, err := fitz.New(path); err == nil {
+			if preview, err := renderFirstPagePreview(doc, path); err == nil {
+				content.PreviewImagePath = preview
+			}
+			doc.Close()
+		}
+	}
+	return content, nil
+}
+
+// walkPart streams the XML tokens of one part; visit returns false to stop.
+func walkPart(file *zip.File, visit func(token xml.Token) bool) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+
+	decoder := xml.NewDecoder(io.LimitReader(reader, maxOfficePartSize))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", file.Name, err)
+		}
+		if !visit(token) {
+			return nil
+		}
+	}
+}
+
+// partTexts returns the text of every element of a flat XML part, such as
+// core.xml, keyed by local name. The first value of a name wins.
+func partTexts(file *zip.File) map[string]string {
+	texts := make(map[string]string)
+	if file == nil {
+		return texts
+	}
+	var current string
+	_ = walkPart(file, func(token xml.Token) bool {
+		switch token := token.(type) {
+		case xml.StartElement:
+			current = token.Name.Local
+		case xml.EndElement:
+			current = ""
+		case xml.CharData:
+			if text := collapseSpace(string(token)); text != "" && current != "" && texts[current] == "" {
+				texts[current] = text
+			}
+		}
+		return true
+	})
+	return texts
+}
+
+func attrValue(element xml.StartElement, local string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func parseOfficeDate(value string) time.Time {
+	if parsed, ok := parseLooseDate(value); ok && parsed.Year() > 1900 {
+		return parsed
+	}
+	return time.Time{}
+}
+
+func readOOXMLProperties(parts map[string]*zip.File) officeProperties {
+	core := partTexts(parts["docProps/core.xml"])
+	app := partTexts(parts["docProps/app.xml"])
+
+	props := officeProperties{
+		title:       core["title"],
+		subject:     core["subject"],
+		author:      core["creator"],
+		description: core["description"],
+		keywords:    core["keywords"],
+		modifiedBy:  core["lastModifiedBy"],
+		application: app["Application"],
+		created:     parseOfficeDate(core["created"]),
+		modified:    parseOfficeDate(core["modified"]),
+	}
+	for _, name := range []string{"Pages", "Slides", "Words"} {
+		if count, err := strconv.Atoi(app[name]); err == nil && count > 0 {
+			props.statistics = append(props.statistics, fmt.Sprintf("%s: %d", name, count))
+		}
+	}
+	return props
+}
+
+func readODFProperties(parts map[string]*zip.File) officeProperties {
+	props := officeProperties{}
+	file := parts["meta.xml"]
+	if file == nil {
+		return props
+	}
+
+	meta := partTexts(file)
+	props.title = meta["title"]
+	props.subject = meta["subject"]
+	props.description = meta["description"]
+	props.author = cmp.Or(meta["initial-creator"], meta["creator"])
+	props.keywords = meta["keyword"]
+	if meta["initial-creator"] != "" && meta["creator"] != meta["initial-creator"] {
+		props.modifiedBy = meta["creator"]
+	}
+	props.application, _, _ = strings.Cut(meta["generator"], "/")
+	props.created = parseOfficeDate(meta["creation-date"])
+	props.modified = parseOfficeDate(meta["date"])
+
+	_ = walkPart(file, func(token xml.Token) bool {
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "document-statistic" {
+			return true
+		}
+		for _, stat := range []struct{ attr, label string }{{"page-count", "Pages"}, {"word-count", "Words"}, {"table-count", "Tables"}} {
+			if count, err := strconv.Atoi(attrValue(element, stat.attr)); err == nil && count > 0 {
+				props.statistics = append(props.statistics, fmt.Sprintf("%s: %d", stat.label, count))
+			}
+		}
+		return false
+	})
+	return props
+}
+
+func readOOXMLBody(parts map[string]*zip.File) ([]string, error) {
+	switch {
+	case parts["word/document.xml"] != nil:
+		return readWordText(parts["word/document.xml"])
+	case parts["ppt/presentation.xml"] != nil:
+		return readSlides(parts)
+	case parts["xl/workbook.xml"] != nil:
+		sheets, err := readWorkbook(parts)
+		return describeSheets(sheets), err
+	}
+	return nil, nil
+}
+
+// readWordText lists the headings of a Word document and its paragraphs.
+func readWordText(file *zip.File) ([]string, error) {
+	var headings, paragraphs []string
+	var paragraph strings.Builder
+	var style string
+	inText := false
+	size := 0
+
+	err := walkPart(file, func(token xml.Token) bool {
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "p":
+				paragraph.Reset()
+				style = ""
+			case "pStyle":
+				style = strings.ToLower(attrValue(token, "val"))
+			case "t":
+				inText = true
+			case "tab":
+				paragraph.WriteByte('\t')
+			case "br", "cr":
+				paragraph.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := strings.TrimSpace(paragraph.String())
+				if text == "" {
+					return true
+				}
+				if strings.HasPrefix(style, "heading") || style == "title" {
+					headings = append(headings, collapseSpace(text))
+				}
+				paragraphs = append(paragraphs, text)
+				size += len(text)
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(token)
+			}
+		}
+		return size < maxMarkupSize
+	})
+
+	var lines []string
+	if len(headings) > 0 {
+		lines = append(lines, "Headings:")
+		for _, heading := range headings[:min(len(headings), maxHeadings)] {
+			lines = append(lines, "- "+heading)
+		}
+	}
+	if len(paragraphs) > 0 {
+		lines = append(lines, "Text:", strings.Join(paragraphs, "\n"))
+	}
+	return lines, err
+}
+
+// readSlides lists every slide of a presentation with its title and text.
+func readSlides(parts map[string]*zip.File) ([]string, error) {
+	type slide struct {
+		number int
+		file   *zip.File
+	}
+	var slides []slide
+	for name, file := range parts {
+		rest, ok := strings.CutPrefix(name, "ppt/slides/slide")
+		if !ok || !strings.HasSuffix(rest, ".xml") {
+			continue
+		}
+		if number, err := strconv.Atoi(strings.TrimSuffix(rest, ".xml")); err == nil {
+			slides = append(slides, slide{number: number, file: file})
+		}
+	}
+	slices.SortFunc(slides, func(a, b slide) int { return a.number - b.number })
+
+	lines := []string{fmt.Sprintf("Slides: %d", len(slides))}
+	var errs []error
+	for index, slide := range slides {
+		title, text, err := readSlide(slide.file)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		heading := fmt.Sprintf("Slide %d", index+1)
+		if title != "" {
+			heading += ": " + title
+		}
+		lines = append(lines, heading)
+		if text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return lines, errors.Join(errs...)
+}
+
+// readSlide returns the text of the title placeholder and the rest of a slide.
+func readSlide(file *zip.File) (string, string, error) {
+	var title string
+	var body []string
+	var shape, paragraph strings.Builder
+	inShape, isTitle, inText := false, false, false
+
+	flushParagraph := func() {
+		text := collapseSpace(paragraph.String())
+		paragraph.Reset()
+		switch {
+		case text == "":
+		case inShape:
+			if shape.Len() > 0 {
+				shape.WriteString(" / ")
+			}
+			shape.WriteString(text)
+		default:
+			body = append(body, text)
+		}
+	}
+
+	err := walkPart(file, func(token xml.Token) bool {
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "sp":
+				inShape, isTitle = true, false
+				shape.Reset()
+			case "ph":
+				kind := attrValue(token, "type")
+				isTitle = isTitle || kind == "title" || kind == "ctrTitle"
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				flushParagraph()
+			case "sp":
+				flushParagraph()
+				text := shape.String()
+				if isTitle && title == "" {
+					title = text
+				} else if text != "" {
+					body = append(body, text)
+				}
+				inShape = false
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(token)
+			}
+		}
+		return true
+	})
+	return title, strings.Join(body, "\n"), err
+}
+
+// readWorkbook lists the sheets of an Excel workbook with their first rows.
+func readWorkbook(parts map[string]*zip.File) ([]officeSheet, error) {
+	targets := make(map[string]string)
+	if rels := parts["xl/_rels/workbook.xml.rels"]; rels != nil {
+		_ = walkPart(rels, func(token xml.Token) bool {
+			if element, ok := token.(xml.StartElement); ok && element.Name.Local == "Relationship" {
+				target := attrValue(element, "Target")
+				if strings.HasPrefix(target, "/") {
+					target = strings.TrimPrefix(target, "/")
+				} else {
+					target = path.Join("xl", target)
+				}
+				targets[attrValue(element, "Id")] = target
+			}
+			return true
+		})
+	}
+
+	var sheets []officeSheet
+	var sheetParts []string
+	err := walkPart(parts["xl/workbook.xml"], func(token xml.Token) bool {
+		if element, ok := token.(xml.StartElement); ok && element.Name.Local == "sheet" {
+			sheets = append(sheets, officeSheet{name: attrValue(element, "name")})
+			sheetParts = append(sheetParts, targets[attrValue(element, "id")])
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := readSharedStrings(parts["xl/sharedStrings.xml"])
+	if err != nil {
+		return sheets, err
+	}
+
+	dateStyles, err := readDateStyles(parts["xl/styles.xml"])
+	if err != nil {
+		return sheets, err
+	}
+
+	var errs []error
+	for index := range sheets[:min(len(sheets), maxSheets)] {
+		file := parts[sheetParts[index]]
+		if file == nil {
+			continue
+		}
+		if err := readWorksheet(file, shared, dateStyles, &sheets[index]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return sheets, errors.Join(errs...)
+}
+
+func readSharedStrings(file *zip.File) ([]string, error) {
+	if file == nil {
+		return nil, nil
+	}
+
+	var shared []string
+	var current strings.Builder
+	inText, inPhonetic := false, false
+	err := walkPart(file, func(token xml.Token) bool {
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				inPhonetic = true
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "si":
+				shared = append(shared, current.String())
+			case "t":
+				inText = false
+			case "rPh":
+				inPhonetic = false
+			}
+		case xml.CharData:
+			if inText && !inPhonetic {
+				current.Write(token)
+			}
+		}
+		return true
+	})
+	return shared, err
+}
+
+// readDateStyles reports which cell styles of a workbook format numbers as
+// dates, by their built-in or custom number format.
+func readDateStyles(file *zip.File) ([]bool, error) {
+	if file == nil {
+		return nil, nil
+	}
+
+	customDates := make(map[string]bool)
+	var styles []bool
+	inCellFormats := false
+	err := walkPart(file, func(token xml.Token) bool {
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "numFmt":
+				customDates[attrValue(token, "numFmtId")] = isDateFormat(attrValue(token, "formatCode"))
+			case "cellXfs":
+				inCellFormats = true
+			case "xf":
+				if inCellFormats {
+					id := attrValue(token, "numFmtId")
+					styles = append(styles, customDates[id] || isBuiltinDateFormat(id))
+				}
+			}
+		case xml.EndElement:
+			if token.Name.Local == "cellXfs" {
+				inCellFormats = false
+			}
+		}
+		return true
+	})
+	return styles, err
+}
+
+func isBuiltinDateFormat(id string) bool {
+	number, err := strconv.Atoi(id)
+	return err == nil && (number >= 14 && number <= 22 || number >= 27 && number <= 36 || number >= 45 && number <= 47 || number >= 50 && number <= 58)
+}
+
+// isDateFormat reports whether a custom number format shows a day or a year,
+// ignoring quoted literals and bracketed colors and locales.
+func isDateFormat(code string) bool {
+	var visible strings.Builder
+	inQuote, inBracket := false, false
+	for _, char := range strings.ToLower(code) {
+		switch {
+		case char == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case char == '[':
+			inBracket = true
+		case char == ']':
+			inBracket = false
+		case !inBracket:
+			visible.WriteRune(char)
+		}
+	}
+	return strings.ContainsAny(visible.String(), "dy")
+}
+
+// readWorksheet reads the used range of a sheet and summarizes its rows.
+func readWorksheet(file *zip.File, shared []string, dateStyles []bool, sheet *officeSheet) error {
+	var cells []string
+	var cellType, value string
+	column, style := -1, -1
+	inValue := false
+
+	return walkPart(file, func(token xml.Token) bool {
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "dimension":
+				sheet.size = attrValue(token, "ref")
+			case "row":
+				cells = cells[:0]
+			case "c":
+				cellType, value = attrValue(token, "t"), ""
+				column = cellColumn(attrValue(token, "r"))
+				style = -1
+				if index, err := strconv.Atoi(attrValue(token, "s")); err == nil {
+					style = index
+				}
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				switch cellType {
+				case "s":
+					if index, err := strconv.Atoi(value); err == nil && index >= 0 && index < len(shared) {
+						value = shared[index]
+					}
+				case "b":
+					value = map[string]string{"0": "FALSE", "1": "TRUE"}[value]
+				case "", "n":
+					if style >= 0 && style < len(dateStyles) && dateStyles[style] {
+						if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 && serial < 2958466 {
+							value = excelEpoch.Add(time.Duration(serial * float64(24*time.Hour))).Format("2006-01-02")
+						}
+					}
+				}
+				// Cells left empty are not stored, so place each by its reference.
+				for column >= 0 && len(cells) < min(column, maxTableColumns) {
+					cells = append(cells, "")
+				}
+				if len(cells) < maxTableColumns {
+					cells = append(cells, collapseSpace(value))
+				}
+			case "row":
+				if !sheet.table.addRow(cells) {
+					return false
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value += string(token)
+			}
+		}
+		return true
+	})
+}
+
+// cellColumn returns the zero-based column of a cell reference such as "C12",
+// or -1 when ref has no column letters.
+func cellColumn(ref string) int {
+	column := 0
+	letters := 0
+	for _, char := range ref {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+		column = column*26 + int(char-'A'+1)
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return -1
+	}
+	return column - 1
+}
+
+// repeatCount reads a repeat attribute of an OpenDocument row or cell, capped at limit.
+func repeatCount(element xml.StartElement, name string, limit int) int {
+	count, err := strconv.Atoi(attrValue(element, name))
+	if err != nil || count < 1 {
+		return 1
+	}
+	return min(count, limit)
+}
+
+// joinCells joins the cells of a row, dropping trailing empty cells.
+func joinCells(cells []string) string {
+	end := len(cells)
+	for end > 0 && cells[end-1] == "" {
+		end--
+	}
+	return strings.Join(cells[:end], " | ")
+}
+
+func describeSheets(sheets []officeSheet) []string {
+	if len(sheets) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(sheets))
+	for _, sheet := range sheets {
+		names = append(names, sheet.name)
+	}
+	lines := []string{fmt.Sprintf("Sheets (%d): %s", len(sheets), strings.Join(names, ", "))}
+	for _, sheet := range sheets {
+		if !sheet.table.started {
+			continue
+		}
+		heading := "Sheet " + sheet.name
+		if sheet.size != "" {
+			heading += " (" + sheet.size + ")"
+		}
+		lines = append(lines, heading+":")
+		for _, line := range sheet.table.describe(true) {
+			lines = append(lines, "  "+line)
+		}
+		if sheet.table.partial {
+			lines = append(lines, fmt.Sprintf("  Note: only the first %d rows were read, so there are more rows.", maxTableRows))
+		}
+	}
+	return lines
+}
+
+// readODFBody reads content.xml of an OpenDocument text, spreadsheet or presentation.
+func readODFBody(parts map[string]*zip.File) ([]string, error) {
+	file := parts["content.xml"]
+	if file == nil {
+		return nil, nil
+	}
+
+	var lines, headings, paragraphs []string
+	var sheets []officeSheet
+	var paragraph strings.Builder
+	var cells []string
+	var kind, pageTitle, cellDate string
+	var pageText []string
+	pages, rowRepeat, cellRepeat := 0, 1, 1
+	inTitleFrame := false
+	depth := 0 // Nesting of paragraphs, whose text belongs to the outermost one
+	size := 0
+
+	flushPage := func() {
+		if pages == 0 {
+			return
+		}
+		heading := fmt.Sprintf("Slide %d", pages)
+		if pageTitle != "" {
+			heading += ": " + pageTitle
+		}
+		lines = append(lines, heading)
+		if len(pageText) > 0 {
+			lines = append(lines, strings.Join(pageText, "\n"))
+		}
+	}
+
+	err := walkPart(file, func(token xml.Token) bool {
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "text", "spreadsheet", "presentation", "drawing":
+				if kind == "" && token.Name.Space == "urn:oasis:names:tc:opendocument:xmlns:office:1.0" {
+					kind = token.Name.Local
+				}
+			case "page":
+				flushPage()
+				pages++
+				pageTitle, pageText = "", nil
+			case "frame":
+				inTitleFrame = attrValue(token, "class") == "title"
+			case "table":
+				if kind == "spreadsheet" {
+					sheets = append(sheets, officeSheet{name: attrValue(token, "name")})
+				}
+			case "table-row":
+				cells = cells[:0]
+				rowRepeat = repeatCount(token, "number-rows-repeated", maxRepeatedRows)
+			case "p", "h":
+				if depth == 0 && kind != "spreadsheet" {
+					paragraph.Reset()
+				} else if kind == "spreadsheet" && paragraph.Len() > 0 {
+					paragraph.WriteByte(' ')
+				}
+				depth++
+			case "table-cell", "covered-table-cell":
+				paragraph.Reset()
+				cellRepeat = repeatCount(token, "number-columns-repeated", maxTableColumns)
+				cellDate = ""
+				if attrValue(token, "value-type") == "date" {
+					cellDate = attrValue(token, "date-value")
+				}
+			case "s":
+				count, err := strconv.Atoi(attrValue(token, "c"))
+				if err != nil {
+					count = 1
+				}
+				paragraph.WriteString(strings.Repeat(" ", min(max(count, 1), 100)))
+			case "tab":
+				paragraph.WriteByte('\t')
+			case "line-break":
+				paragraph.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch token.Name.Local {
+			case "frame":
+				inTitleFrame = false
+			case "table-cell", "covered-table-cell":
+				if kind == "spreadsheet" {
+					value := collapseSpace(paragraph.String())
+					if date, ok := parseLooseDate(cellDate); ok {
+						value = date.Format("2006-01-02")
+					}
+					for range cellRepeat {
+						if len(cells) < maxTableColumns {
+							cells = append(cells, value)
+						}
+					}
+					paragraph.Reset()
+				}
+			case "table-row":
+				if kind == "spreadsheet" && len(sheets) > 0 && joinCells(cells) != "" {
+					for range rowRepeat {
+						if !sheets[len(sheets)-1].table.addRow(cells) {
+							break
+						}
+					}
+				}
+			case "p", "h":
+				depth--
+				if depth > 0 || kind == "spreadsheet" {
+					return true
+				}
+				text := strings.TrimSpace(paragraph.String())
+				paragraph.Reset()
+				if text == "" {
+					return true
+				}
+				switch {
+				case pages > 0 && inTitleFrame && pageTitle == "":
+					pageTitle = collapseSpace(text)
+				case pages > 0:
+					pageText = append(pageText, text)
+				default:
+					if token.Name.Local == "h" {
+						headings = append(headings, collapseSpace(text))
+					}
+					paragraphs = append(paragraphs, text)
+				}
+				size += len(text)
+			}
+		case xml.CharData:
+			if depth > 0 {
+				paragraph.Write(token)
+			}
+		}
+		return size < maxMarkupSize
+	})
+	flushPage()
+
+	switch {
+	case kind == "spreadsheet":
+		return describeSheets(sheets), err
+	case pages > 0:
+		return append([]string{fmt.Sprintf("Slides: %d", pages)}, lines...), err
+	}
+	if len(headings) > 0 {
+		lines = append(lines, "Headings:")
+		for _, heading := range headings[:min(len(headings), maxHeadings)] {
+			lines = append(lines, "- "+heading)
+		}
+	}
+	if len(paragraphs) > 0 {
+		lines = append(lines, "Text:", strings.Join(paragraphs, "\n"))
+	}
+	return lines, err
+}
@@ -0,0 +1,200 @@
+package files
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const ooxmlCore = `<?xml version="1.0" encoding="UTF-8"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/">
+  <dc:title>Annual Budget</dc:title>
+  <dc:creator>Jane Doe</dc:creator>
+  <cp:lastModifiedBy>John Roe</cp:lastModifiedBy>
+  <dcterms:created>2023-11-05T08:00:00Z</dcterms:created>
+  <dcterms:modified>2024-02-01T17:30:00Z</dcterms:modified>
+</cp:coreProperties>`
+
+// writeZip creates an office file from its parts, writing them in the order given.
+func writeZip(t *testing.T, name string, parts ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	for index := 0; index < len(parts); index += 2 {
+		method := zip.Deflate
+		if parts[index] == "mimetype" {
+			method = zip.Store
+		}
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: parts[index], Method: method})
+		if err != nil {
+			t.Fatalf("CreateHeader() error = %v", err)
+		}
+		if _, err := writer.Write([]byte(parts[index+1])); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return path
+}
+
+func TestExtractFileContentOffice(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		parts []string
+		want  []string
+	}{
+		{
+			name: "Word document",
+			file: "budget.docx",
+			parts: []string{
+				"[Content_Types].xml", `<Types/>`,
+				"docProps/core.xml", ooxmlCore,
+				"docProps/app.xml", `<Properties><Application>Microsoft Office Word</Application><Pages>3</Pages><Words>812</Words></Properties>`,
+				"word/document.xml", `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+					<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Overview</w:t></w:r></w:p>
+					<w:p><w:r><w:t xml:space="preserve">Spending rises </w:t></w:r><w:r><w:t>by 4%.</w:t></w:r><w:r><w:delText>deleted</w:delText></w:r></w:p>
+				</w:body></w:document>`,
+			},
+			want: []string{"Title: Annual Budget", "Author: Jane Doe", "Last modified by: John Roe", "Created: 2023-11-05", "Modified: 2024-02-01", "Application: Microsoft Office Word", "Pages: 3, Words: 812", "Headings:\n- Overview", "Spending rises by 4%."},
+		},
+		{
+			name: "PowerPoint presentation",
+			file: "pitch.pptx",
+			parts: []string{
+				"[Content_Types].xml", `<Types/>`,
+				"docProps/core.xml", ooxmlCore,
+				"ppt/presentation.xml", `<p:presentation/>`,
+				"ppt/slides/slide10.xml", `<p:sld><p:sp><p:nvSpPr><p:nvPr><p:ph type="title"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>Roadmap</a:t></a:r></a:p></p:txBody></p:sp></p:sld>`,
+				"ppt/slides/slide2.xml", `<p:sld><p:sp><p:nvSpPr><p:nvPr><p:ph type="ctrTitle"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>Market</a:t></a:r></a:p></p:txBody></p:sp>
+					<p:sp><p:txBody><a:p><a:r><a:t>Europe</a:t></a:r></a:p><a:p><a:r><a:t>Asia</a:t></a:r></a:p></p:txBody></p:sp></p:sld>`,
+			},
+			want: []string{"Slides: 2", "Slide 1: Market\nEurope / Asia", "Slide 2: Roadmap"},
+		},
+		{
+			name: "Excel workbook",
+			file: "budget.xlsx",
+			parts: []string{
+				"[Content_Types].xml", `<Types/>`,
+				"docProps/core.xml", ooxmlCore,
+				"xl/workbook.xml", `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Costs" sheetId="1" r:id="rId1"/><sheet name="Notes" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+				"xl/_rels/workbook.xml.rels", `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="/xl/worksheets/sheet2.xml"/></Relationships>`,
+				"xl/sharedStrings.xml", `<sst><si><t>Item</t></si><si><t>Cost</t></si><si><r><t>Rent</t></r><r><t> office</t></r><rPh><t>ignored</t></rPh></si></sst>`,
+				"xl/worksheets/sheet1.xml", `<worksheet><dimension ref="A1:B40"/><sheetData>
+					<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+					<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>1200</v></c><c r="C2" t="b"><v>1</v></c></row>
+					<row r="3"><c r="A3" t="inlineStr"><is><t>Power</t></is></c><c r="B3"/></row>
+				</sheetData></worksheet>`,
+				"xl/worksheets/sheet2.xml", `<worksheet><sheetData/></worksheet>`,
+			},
+			want: []string{"Sheets (2): Costs, Notes", "Sheet Costs (A1:B40):\n  Columns (3): Item, Cost, column 3\n  Rows: 2", "- Cost: integer, 1200, 1 empty", "Sample rows:\n    Rent office | 1200 | TRUE\n    Power"},
+		},
+		{
+			name: "OpenDocument text",
+			file: "letter.odt",
+			parts: []string{
+				"mimetype", "application/vnd.oasis.opendocument.text",
+				"meta.xml", `<office:document-meta xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:dc="http://purl.org/dc/elements/1.1/"><office:meta>
+					<meta:generator>LibreOffice/7.6</meta:generator><dc:title>Lease Renewal</dc:title><meta:initial-creator>Ana Lima</meta:initial-creator>
+					<meta:creation-date>2022-04-10T10:00:00.123</meta:creation-date><meta:document-statistic meta:page-count="2" meta:word-count="410"/>
+				</office:meta></office:document-meta>`,
+				"content.xml", `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text>
+					<text:h text:outline-level="1">Terms</text:h><text:p>The lease<text:s text:c="2"/>runs <text:span>until 2025</text:span>.</text:p>
+				</office:text></office:body></office:document-content>`,
+			},
+			want: []string{"OpenDocument text", "Title: Lease Renewal", "Author: Ana Lima", "Application: LibreOffice", "Created: 2022-04-10", "Pages: 2, Words: 410", "Headings:\n- Terms", "The lease  runs until 2025."},
+		},
+		{
+			name: "OpenDocument spreadsheet",
+			file: "stock.ods",
+			parts: []string{
+				"mimetype", "application/vnd.oasis.opendocument.spreadsheet",
+				"content.xml", `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:spreadsheet>
+					<table:table table:name="Inventory">
+						<table:table-row><table:table-cell><text:p>SKU</text:p></table:table-cell><table:table-cell><text:p>Qty</text:p></table:table-cell><table:table-cell><text:p>Received</text:p></table:table-cell></table:table-row>
+						<table:table-row><table:table-cell><text:p>A-1</text:p></table:table-cell><table:table-cell><text:p>7</text:p></table:table-cell><table:table-cell office:value-type="date" office:date-value="2023-11-15"><text:p>15.11.23</text:p></table:table-cell><table:table-cell table:number-columns-repeated="1000"/></table:table-row>
+						<table:table-row table:number-rows-repeated="2"><table:table-cell><text:p>B-2</text:p></table:table-cell><table:table-cell><text:p>7</text:p></table:table-cell><table:table-cell office:value-type="date" office:date-value="2023-10-02T00:00:00"><text:p>02.10.23</text:p></table:table-cell></table:table-row>
+						<table:table-row table:number-rows-repeated="1048000"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+					</table:table>
+				</office:spreadsheet></office:body></office:document-content>`,
+			},
+			want: []string{"OpenDocument spreadsheet", "Sheets (1): Inventory", "Sheet Inventory:\n  Columns (3): SKU, Qty, Received\n  Rows: 3", "- SKU: text, values: A-1, B-2", "- Qty: integer, 7", "- Received: date, 2023-10-02 to 2023-11-15", "Sample rows:\n    A-1 | 7 | 2023-11-15\n    B-2 | 7 | 2023-10-02"},
+		},
+		{
+			name: "OpenDocument presentation",
+			file: "talk.odp",
+			parts: []string{
+				"mimetype", "application/vnd.oasis.opendocument.presentation",
+				"content.xml", `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" xmlns:presentation="urn:oasis:names:tc:opendocument:xmlns:presentation:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:presentation>
+					<draw:page draw:name="page1"><draw:frame presentation:class="title"><draw:text-box><text:p>Welcome</text:p></draw:text-box></draw:frame>
+						<draw:frame presentation:class="outline"><draw:text-box><text:p>Agenda</text:p></draw:text-box></draw:frame></draw:page>
+					<draw:page draw:name="page2"><draw:frame presentation:class="title"><draw:text-box><text:p>Questions</text:p></draw:text-box></draw:frame></draw:page>
+				</office:presentation></office:body></office:document-content>`,
+			},
+			want: []string{"Slides: 2", "Slide 1: Welcome\nAgenda", "Slide 2: Questions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeZip(t, tt.file, tt.parts...)
+
+			content, err := ExtractFileContent(path, DefaultExtractOptions())
+			if err != nil {
+				t.Fatalf("ExtractFileContent() error = %v", err)
+			}
+			if content.PreviewImagePath != "" {
+				defer os.Remove(content.PreviewImagePath)
+			}
+			if content.Category != CategoryDocuments {
+				t.Fatalf("Category = %q, want Documents", content.Category)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(content.Text, want) {
+					t.Fatalf("Text = %q, want %q", content.Text, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractFileContentOfficeMetadata(t *testing.T) {
+	path := writeZip(t, "report.docx",
+		"[Content_Types].xml", `<Types/>`,
+		"docProps/core.xml", ooxmlCore,
+		"word/document.xml", `<w:document><w:body/></w:document>`,
+	)
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if content.PreviewImagePath != "" {
+		defer os.Remove(content.PreviewImagePath)
+	}
+	if content.Metadata["title"] != "Annual Budget" || content.Metadata["author"] != "Jane Doe" || content.Metadata["created"] != "2023-11-05" || content.Metadata["year"] != "2023" {
+		t.Fatalf("Metadata = %v, want the core properties", content.Metadata)
+	}
+}
+
+func TestDetectFileTypeOpenDocumentByContent(t *testing.T) {
+	path := writeZip(t, "renamed.zip", "mimetype", "application/vnd.oasis.opendocument.text", "content.xml", "<x/>")
+
+	fileType, err := DetectFileType(path)
+	if err != nil {
+		t.Fatalf("DetectFileType() error = %v", err)
+	}
+	if fileType.MIME != "application/vnd.oasis.opendocument.text" {
+		t.Fatalf("MIME = %q, want the type from the mimetype entry", fileType.MIME)
+	}
+}
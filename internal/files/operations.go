@@ -1,12 +1,15 @@
 package files
 
 import (
+	"cmp"
 	"fmt"
 	"image/jpeg"
+	"maps"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dhowden/tag"
 	"github.com/gen2brain/go-fitz"
@@ -15,75 +18,106 @@ import (
 type ExtractedContent struct {
 	Text             string
 	PreviewImagePath string
+	// Metadata holds structured fields such as title, author or artist, keyed in snake case.
+	Metadata map[string]string
+	// Sparse is set when little usable content was found, so names built from it are mostly guesses.
+	Sparse bool
+	// Truncated is set when the middle of Text was left out to respect MaxContentLength.
+	Truncated bool
+	// FullLength is the size in bytes of the content before truncation.
+	FullLength int64
+	// MIME is the detected content type.
+	MIME string
+	// Category is the output folder of the file's format, such as Images.
+	Category string
+	// Warning describes a configured extractor that failed before the built-in one was used.
+	Warning string
 }
 
 func ReadFile(path string) (string, error) {
-	content, err := ExtractFileContent(path)
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
 	if err != nil {
 		return "", err
 	}
 	return content.Text, nil
 }
 
-func ExtractFileContent(path string) (ExtractedContent, error) {
-	extension := strings.TrimPrefix(GetFileExtension(path), ".")
+// ExtractFileContent reads the text, metadata and preview of path within the
+// limits of opts. A matching command extractor runs first; when it fails, the
+// registered extractor for the file's format is used instead.
+func ExtractFileContent(path string, opts ExtractOptions) (ExtractedContent, error) {
+	extension := GetFileExtension(path)
+	fileType, err := DetectFileType(path)
+	if err != nil {
+		// Unreadable files are reported by the extractor; images only need their path.
+		fileType = FileTypeForExtension(extension)
+	}
+	format := DefaultRegistry.Lookup(fileType, extension)
 
-	switch extension {
-	case "txt", "md", "json":
-		content, err := readRawFile(path)
-		if err != nil {
-			return ExtractedContent{}, fmt.Errorf("there was an error reading the file %s: %w", path, err)
-		}
-		return ExtractedContent{Text: content}, nil
-	case "png", "jpg", "jpeg", "webp":
-		text, err := readImageFile(path)
-		if err != nil {
-			return ExtractedContent{}, fmt.Errorf("there was an error reading the file %s: %w", path, err)
-		}
-		return ExtractedContent{Text: text, PreviewImagePath: path}, nil
-	case "pdf", "docx", "epub", "pptx", "xlsx", "xls":
-		return readDocumentContent(path)
-	case "mp3", "ogg", "mp4", "flac", "m4a", "dsf", "wav":
-		text, err := readMetadata(path)
-		if err != nil {
-			return ExtractedContent{}, fmt.Errorf("there was an error reading the file %s: %w", path, err)
-		}
-		return ExtractedContent{Text: text}, nil
-	default:
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return ExtractedContent{}, fmt.Errorf("there was an error reading the file %s: %w", path, err)
-		}
-		return ExtractedContent{Text: string(content)}, nil
+	extracted, command, err := extractWithCommand(path, fileType, extension, opts)
+	var warning string
+	switch {
+	case command != nil && err == nil:
+		extracted.MIME = fileType.MIME
+		extracted.Category = cmp.Or(command.Category, format.Category)
+		return truncateContent(extracted, opts.MaxContentLength), nil
+	case command != nil:
+		warning = fmt.Sprintf("extractor %s failed for %s, using the built-in extractor: %v", command.Name, path, err)
 	}
-}
 
-func readRawFile(path string) (string, error) {
-	content, err := os.ReadFile(path)
+	extracted, err = format.extractor().Extract(path, fileType, opts)
 	if err != nil {
-		return "", err
+		return ExtractedContent{}, fmt.Errorf("there was an error reading the file %s: %w", path, err)
 	}
-	return string(content), nil
+	extracted.MIME = fileType.MIME
+	extracted.Category = format.Category
+	extracted.Warning = warning
+	return truncateContent(extracted, opts.MaxContentLength), nil
 }
 
-func readImageFile(_ string) (string, error) {
-	return "An image preview is available for this file. Use the visual contents to infer a better filename.", nil
+// readImageFile points vision models at the preview and adds what the image
+// headers say, so text-only models still get the size, capture date and camera.
+// Unreadable headers leave just the preview note.
+func readImageFile(path string, withMetadata bool) ExtractedContent {
+	content := ExtractedContent{
+		Text:             "An image preview is available for this file. Use the visual contents to infer a better filename.",
+		PreviewImagePath: path,
+	}
+	if !withMetadata {
+		return content
+	}
+
+	details, err := readImageDetails(path)
+	if err != nil {
+		return content
+	}
+	lines, metadata := details.describe()
+	if len(lines) > 0 {
+		content.Text += "\n\nImage details:\n" + strings.Join(lines, "\n")
+		content.Metadata = metadata
+	}
+	return content
 }
 
-func readDocumentContent(path string) (ExtractedContent, error) {
+func readDocumentContent(path string, opts ExtractOptions) (ExtractedContent, error) {
 	doc, err := fitz.New(path)
 	if err != nil {
 		return fallbackDocumentContent(path, fmt.Errorf("creating fitz document: %w", err))
 	}
 	defer doc.Close()
 
-	text, textErr := extractDocumentText(doc, path)
+	var text string
+	var textErr error
+	if opts.ExtractText {
+		text, textErr = extractDocumentText(doc, path)
+	}
 	previewPath, previewErr := renderFirstPagePreview(doc, path)
 
 	if textErr != nil && previewErr != nil {
 		return fallbackDocumentContent(path, fmt.Errorf("extracting document content failed: %v; preview failed: %v", textErr, previewErr))
 	}
 
+	sparse := text == "" && previewErr != nil
 	if text == "" {
 		text = "Minimal document text was extracted. Prefer the first-page preview if available."
 	}
@@ -92,12 +126,75 @@ func readDocumentContent(path string) (ExtractedContent, error) {
 		text += "\nA first-page preview image is available for this document."
 	}
 
+	var metadata map[string]string
+	if opts.ExtractMetadata {
+		var info []string
+		info, metadata = documentMetadata(doc)
+		idLines, idMetadata := findIdentifiers(text).describe()
+		info = append(info, idLines...)
+		maps.Copy(metadata, idMetadata)
+		if len(info) > 0 {
+			text = "Document info:\n" + strings.Join(info, "\n") + "\n\n" + text
+		}
+	}
+
 	return ExtractedContent{
 		Text:             text,
 		PreviewImagePath: previewPath,
+		Metadata:         metadata,
+		Sparse:           sparse,
 	}, nil
 }
 
+// documentMetadata describes the non-empty info dictionary fields of a
+// document and returns the ones templates can use.
+func documentMetadata(doc *fitz.Document) ([]string, map[string]string) {
+	info := doc.Metadata()
+	var lines []string
+	metadata := make(map[string]string)
+	for _, field := range []struct{ key, label string }{
+		{"title", "Title"}, {"author", "Author"}, {"subject", "Subject"}, {"keywords", "Keywords"},
+		{"creator", "Application"}, {"producer", "Producer"}, {"creationDate", "Created"}, {"modDate", "Modified"},
+	} {
+		value, _, _ := strings.Cut(info[field.key], "\x00")
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		switch field.key {
+		case "title", "author", "subject", "keywords":
+			metadata[field.key] = value
+		case "creationDate", "modDate":
+			date, ok := parsePDFDate(value)
+			if !ok {
+				continue
+			}
+			value = date.Format("2006-01-02")
+			if field.key == "creationDate" {
+				metadata["created"] = value
+				metadata["year"] = date.Format("2006")
+			}
+		}
+		lines = append(lines, field.label+": "+value)
+	}
+	return lines, metadata
+}
+
+// parsePDFDate parses the date prefix of a PDF date string such as D:20230115120000Z.
+func parsePDFDate(value string) (time.Time, bool) {
+	value = strings.TrimPrefix(value, "D:")
+	for _, layout := range []string{"20060102150405", "200601021504", "20060102", "200601", "2006"} {
+		if len(value) < len(layout) {
+			continue
+		}
+		if parsed, err := time.Parse(layout, value[:len(layout)]); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
 func extractDocumentText(doc *fitz.Document, path string) (string, error) {
 	pageCount := doc.NumPage()
 	if pageCount == 0 {
@@ -148,7 +245,7 @@ func renderFirstPagePreview(doc *fitz.Document, sourcePath string) (string, erro
 func fallbackDocumentContent(path string, cause error) (ExtractedContent, error) {
 	info, err := os.Stat(path)
 	if err != nil {
-		return ExtractedContent{}, fmt.Errorf("there was an error reading the file %s: %w", path, cause)
+		return ExtractedContent{}, fmt.Errorf("%v: %w", cause, err)
 	}
 
 	return ExtractedContent{
@@ -160,46 +257,80 @@ func fallbackDocumentContent(path string, cause error) (ExtractedContent, error)
 			fmt.Sprintf("Parser error: %v", cause),
 			"Use the filename and any available visual preview to infer a better name.",
 		}, "\n"),
+		Sparse: true,
 	}, nil
 }
 
-func readMetadata(path string) (string, error) {
+func readMetadata(path string) (ExtractedContent, error) {
+	metadata, fields, err := readTags(path)
+	if err != nil {
+		return ExtractedContent{}, err
+	}
+
+	if len(metadata) == 0 {
+		return ExtractedContent{Metadata: fields, Sparse: true}, nil
+	}
+
+	text := strings.Join(metadata, "\n")
+	if text == "" || strings.Count(text, "\n") <= 1 {
+		return ExtractedContent{
+			Text:     "Sparse metadata found for file: " + filepath.Base(path) + "\n" + text,
+			Metadata: fields,
+			Sparse:   true,
+		}, nil
+	}
+
+	return ExtractedContent{Text: text, Metadata: fields}, nil
+}
+
+// readTags lists the ID3, Vorbis and MP4 tags of a media file as context
+// lines and metadata fields.
+func readTags(path string) ([]string, map[string]string, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 	defer f.Close()
 
 	m, err := tag.ReadFrom(f)
 	if err != nil {
-		return "", err
+		return nil, nil, err
 	}
 
+	fields := make(map[string]string)
 	var metadata []string
 	if title := m.Title(); title != "" {
 		metadata = append(metadata, fmt.Sprintf("Title: %s", title))
+		fields["title"] = title
 	}
 	if album := m.Album(); album != "" {
 		metadata = append(metadata, fmt.Sprintf("Album: %s", album))
+		fields["album"] = album
 	}
 	if artist := m.Artist(); artist != "" {
 		metadata = append(metadata, fmt.Sprintf("Artist: %s", artist))
+		fields["artist"] = artist
 	}
 	if albumArtist := m.AlbumArtist(); albumArtist != "" {
 		metadata = append(metadata, fmt.Sprintf("Album Artist: %s", albumArtist))
+		fields["album_artist"] = albumArtist
 	}
 	if composer := m.Composer(); composer != "" {
 		metadata = append(metadata, fmt.Sprintf("Composer: %s", composer))
+		fields["composer"] = composer
 	}
 	if genre := m.Genre(); genre != "" {
 		metadata = append(metadata, fmt.Sprintf("Genre: %s", genre))
+		fields["genre"] = genre
 	}
 	if year := m.Year(); year != 0 {
 		metadata = append(metadata, fmt.Sprintf("Year: %d", year))
+		fields["year"] = strconv.Itoa(year)
 	}
 
 	trackNum, trackTotal := m.Track()
 	if trackNum != 0 {
+		fields["track"] = fmt.Sprintf("%02d", trackNum)
 		if trackTotal != 0 {
 			metadata = append(metadata, fmt.Sprintf("Track: %d/%d", trackNum, trackTotal))
 		} else {
@@ -231,15 +362,5 @@ func readMetadata(path string) (string, error) {
 	if picture := m.Picture(); picture != nil {
 		metadata = append(metadata, "Artwork: Present")
 	}
-
-	if len(metadata) == 0 {
-		return "", nil
-	}
-
-	text := strings.Join(metadata, "\n")
-	if text == "" || strings.Count(text, "\n") <= 1 {
-		return "Sparse metadata found for file: " + filepath.Base(path) + "\n" + text, nil
-	}
-
-	return text, nil
+	return metadata, fields, nil
 }
@@ -26,7 +26,7 @@ func TestReadFileText(t *testing.T) {
 func TestExtractFileContentImageUsesVisualSource(t *testing.T) {
 	path := filepath.Join(demoDir(t), "image1.png")
 
-	content, err := ExtractFileContent(path)
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
 	if err != nil {
 		t.Fatalf("ExtractFileContent() error = %v", err)
 	}
@@ -48,7 +48,7 @@ func TestExtractFileContentDocumentFallbackIsStructured(t *testing.T) {
 		t.Fatalf("WriteFile() error = %v", err)
 	}
 
-	content, err := ExtractFileContent(path)
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
 	if err != nil {
 		t.Fatalf("ExtractFileContent() error = %v", err)
 	}
@@ -93,3 +93,24 @@ func TestListFiles(t *testing.T) {
 		t.Fatal("expected demo directory to contain files")
 	}
 }
+
+func TestParsePDFDate(t *testing.T) {
+	cases := map[string]string{
+		"D:20230415103000+02'00'": "2023-04-15",
+		"D:20230415":              "2023-04-15",
+		"2019":                    "2019-01-01",
+	}
+	for raw, want := range cases {
+		parsed, ok := parsePDFDate(raw)
+		if !ok {
+			t.Fatalf("parsePDFDate(%q) failed", raw)
+		}
+		if got := parsed.Format("2006-01-02"); got != want {
+			t.Fatalf("parsePDFDate(%q) = %s, want %s", raw, got, want)
+		}
+	}
+
+	if _, ok := parsePDFDate("unknown"); ok {
+		t.Fatal("expected invalid date to fail")
+	}
+}
@@ -0,0 +1,313 @@
+package files
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Categories files are sorted into when organizing output.
+const (
+	CategoryImages    = "Images"
+	CategoryDocuments = "Documents"
+	CategoryAudios    = "Audios"
+	CategoryVideos    = "Videos"
+	CategoryOthers    = "Others"
+)
+
+// Extractor reads the text, metadata and preview of one family of formats.
+type Extractor interface {
+	Extract(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error)
+}
+
+// ExtractorFunc adapts a function to the Extractor interface.
+type ExtractorFunc func(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error)
+
+func (f ExtractorFunc) Extract(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error) {
+	return f(path, fileType, opts)
+}
+
+// Format declares which files an extractor handles and how they are categorized.
+type Format struct {
+	Name        string
+	Description string
+	Extensions  []string // Lowercase, with the leading dot
+	// MIMETypes are matched against the sniffed type; "text/*" matches a whole family.
+	MIMETypes []string
+	Category  string
+	// Vision is set when the file itself can be sent to a vision model as an image.
+	Vision bool
+	// Extractor reads the content. Formats without one send text as is and
+	// describe binaries by their detected type.
+	Extractor Extractor
+}
+
+func (f Format) extractor() Extractor {
+	if f.Extractor == nil {
+		return ExtractorFunc(extractGeneric)
+	}
+	return f.Extractor
+}
+
+// Registry finds the format of a file from its sniffed type and extension.
+// Formats registered later take precedence over earlier ones.
+type Registry struct {
+	mu      sync.RWMutex
+	formats []Format
+}
+
+// NewRegistry returns a registry holding formats.
+func NewRegistry(formats ...Format) *Registry {
+	registry := &Registry{}
+	for _, format := range formats {
+		registry.Register(format)
+	}
+	return registry
+}
+
+// Register adds format, normalizing its extensions.
+func (r *Registry) Register(format Format) {
+	extensions := make([]string, 0, len(format.Extensions))
+	for _, extension := range format.Extensions {
+		extension = strings.ToLower(strings.TrimSpace(extension))
+		if extension != "" && !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		extensions = append(extensions, extension)
+	}
+	format.Extensions = extensions
+	if format.Category == "" {
+		format.Category = CategoryOthers
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.formats = append(r.formats, format)
+}
+
+// Formats returns every registered format in registration order.
+func (r *Registry) Formats() []Format {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.formats)
+}
+
+// Lookup picks the format for a file. A type identified by its magic bytes is
+// trusted over the extension, so misnamed files reach the right extractor;
+// otherwise the extension decides and the sniffed type is the fallback.
+func (r *Registry) Lookup(fileType FileType, extension string) Format {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	extension = strings.ToLower(extension)
+	if !fileType.sniffed {
+		if format, ok := r.find(func(f Format) bool { return slices.Contains(f.Extensions, extension) }); ok {
+			return format
+		}
+	}
+	if format, ok := r.find(func(f Format) bool { return slices.Contains(f.MIMETypes, fileType.MIME) }); ok {
+		return format
+	}
+	if family, _, ok := strings.Cut(fileType.MIME, "/"); ok {
+		if format, ok := r.find(func(f Format) bool { return slices.Contains(f.MIMETypes, family+"/*") }); ok {
+			return format
+		}
+	}
+	return Format{Name: "other", Category: CategoryOthers}
+}
+
+// find returns the most recently registered format matching match.
+func (r *Registry) find(match func(Format) bool) (Format, bool) {
+	for index := len(r.formats) - 1; index >= 0; index-- {
+		if match(r.formats[index]) {
+			return r.formats[index], true
+		}
+	}
+	return Format{}, false
+}
+
+// DefaultRegistry holds the built-in formats.
+var DefaultRegistry = NewRegistry(builtinFormats()...)
+
+// Register adds a format to the default registry.
+func Register(format Format) {
+	DefaultRegistry.Register(format)
+}
+
+// Formats lists the formats of the default registry.
+func Formats() []Format {
+	return DefaultRegistry.Formats()
+}
+
+// FormatForExtension looks a file name up by its extension alone.
+func FormatForExtension(fileName string) Format {
+	extension := GetFileExtension(fileName)
+	return DefaultRegistry.Lookup(FileTypeForExtension(extension), extension)
+}
+
+func builtinFormats() []Format {
+	return []Format{
+		{
+			Name:        "text",
+			Description: "Text and data files, read within content_extraction.max_content_length",
+			Extensions:  []string{".json", ".log", ".yaml", ".yml", ".ini", ".conf", ".cfg", ".sql"},
+			MIMETypes:   []string{"text/*", "application/json", "application/yaml"},
+			Extractor:   ExtractorFunc(extractGeneric),
+		},
+		{
+			Name:        "tables",
+			Description: "Delimiter, encoding, columns with their types and ranges, row count and sample rows of delimited data",
+			Extensions:  []string{".csv", ".tsv", ".tab"},
+			MIMETypes:   []string{"text/csv", "text/tab-separated-values"},
+			Extractor:   ExtractorFunc(extractTable),
+		},
+		{
+			Name:        "text-documents",
+			Description: "Plain text documents",
+			Extensions:  []string{".txt", ".md", ".rtf"},
+			MIMETypes:   []string{"text/markdown", "application/rtf"},
+			Category:    CategoryDocuments,
+			Extractor:   ExtractorFunc(extractGeneric),
+		},
+		{
+			Name:        "documents",
+			Description: "Text of the first two pages, document properties and a first-page preview (go-fitz)",
+			Extensions:  []string{".pdf", ".epub", ".xls"},
+			MIMETypes:   []string{"application/pdf", "application/epub+zip", "application/vnd.ms-excel"},
+			Category:    CategoryDocuments,
+			Extractor:   ExtractorFunc(extractDocument),
+		},
+		{
+			Name:        "office",
+			Description: "Document properties (title, author, dates), text, slide titles, and sheet columns, types and sample rows of OOXML and OpenDocument files",
+			Extensions:  []string{".docx", ".pptx", ".xlsx", ".odt", ".odp", ".ods"},
+			MIMETypes: []string{
+				"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+				"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+				"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+				"application/vnd.oasis.opendocument.text",
+				"application/vnd.oasis.opendocument.spreadsheet",
+				"application/vnd.oasis.opendocument.presentation",
+			},
+			Category:  CategoryDocuments,
+			Extractor: ExtractorFunc(extractOffice),
+		},
+		{
+			Name:        "office-documents",
+			Description: "Legacy office documents, described by type only",
+			Extensions:  []string{".doc", ".ppt"},
+			MIMETypes:   []string{"application/msword", "application/vnd.ms-powerpoint"},
+			Category:    CategoryDocuments,
+		},
+		{
+			Name:        "images",
+			Description: "Images sent to vision models as is, with size, capture date, camera and GPS from EXIF",
+			Extensions:  []string{".png", ".jpg", ".jpeg", ".webp"},
+			MIMETypes:   []string{"image/png", "image/jpeg", "image/webp"},
+			Category:    CategoryImages,
+			Vision:      true,
+			Extractor:   ExtractorFunc(extractImage),
+		},
+		{
+			Name:        "other-images",
+			Description: "Images vision models do not accept, described by type only",
+			Extensions:  []string{".gif", ".bmp", ".tif", ".tiff", ".heic", ".avif"},
+			MIMETypes:   []string{"image/*"},
+			Category:    CategoryImages,
+		},
+		{
+			Name:        "audio",
+			Description: "ID3, Vorbis and MP4 tags (artist, title, album, track, year)",
+			Extensions:  []string{".mp3", ".ogg", ".flac", ".m4a", ".dsf", ".wav", ".aac"},
+			MIMETypes:   []string{"audio/mpeg", "audio/ogg", "audio/flac", "audio/mp4", "audio/x-dsf", "audio/wav", "audio/aac"},
+			Category:    CategoryAudios,
+			Extractor:   ExtractorFunc(extractTags),
+		},
+		{
+			Name:        "videos",
+			Description: "Duration, dimensions, codecs, creation time and GPS of QuickTime, Matroska, WebM and AVI videos",
+			Extensions:  []string{".mov", ".avi", ".mkv", ".wmv", ".webm", ".3gp"},
+			MIMETypes:   []string{"video/*"},
+			Category:    CategoryVideos,
+			Extractor:   ExtractorFunc(extractVideo),
+		},
+		{
+			Name:        "html",
+			Description: "Title, meta description, author and date, headings and visible text of web pages",
+			Extensions:  []string{".html", ".htm", ".xhtml"},
+			MIMETypes:   []string{"text/html", "application/xhtml+xml"},
+			Extractor:   ExtractorFunc(extractHTML),
+		},
+		{
+			Name:        "xml",
+			Description: "Root element, namespaces and key text of XML; titles and entries of RSS and Atom feeds",
+			Extensions:  []string{".xml", ".rss", ".atom", ".xsd", ".xsl", ".kml", ".gpx", ".plist"},
+			MIMETypes:   []string{"text/xml", "application/xml", "application/rss+xml", "application/atom+xml"},
+			Extractor:   ExtractorFunc(extractXML),
+		},
+		{
+			Name:        "svg",
+			Description: "Title, description, size and text of SVG drawings",
+			Extensions:  []string{".svg"},
+			MIMETypes:   []string{"image/svg+xml"},
+			Category:    CategoryImages,
+			Extractor:   ExtractorFunc(extractXML),
+		},
+		{
+			Name:        "code",
+			Description: "Language, package, imports, top-level functions and types, and the leading doc comment of source code",
+			Extensions:  codeExtensions(),
+			MIMETypes:   codeMIMETypes(),
+			Extractor:   ExtractorFunc(extractCode),
+		},
+		{
+			Name:        "archives",
+			Description: "Entry count, total size, top-level folders, file types, first paths and README text of zip, tar and gzip or bzip2 tarballs",
+			Extensions:  []string{".zip", ".jar", ".tar", ".tgz", ".gz", ".tbz", ".tbz2", ".bz2", ".txz", ".xz", ".7z", ".rar"},
+			MIMETypes:   []string{"application/zip", "application/java-archive", "application/x-tar", "application/gzip", "application/x-bzip2", "application/x-xz", "application/x-7z-compressed", "application/vnd.rar"},
+			Extractor:   ExtractorFunc(extractArchive),
+		},
+		{
+			Name:        "email",
+			Description: "Subject, sender, recipients, date, attachment names and first text part of messages; message count, dates, senders and subjects of mailboxes",
+			Extensions:  []string{".eml", ".mbox"},
+			MIMETypes:   []string{"message/rfc822", "application/mbox"},
+			Category:    CategoryDocuments,
+			Extractor:   ExtractorFunc(extractEmail),
+		},
+		{
+			Name:        "mp4",
+			Description: "Duration, dimensions, codecs, creation time, GPS and tags (title, artist, year) of MP4 videos",
+			Extensions:  []string{".mp4", ".m4v"},
+			MIMETypes:   []string{"video/mp4"},
+			Category:    CategoryVideos,
+			Extractor:   ExtractorFunc(extractVideo),
+		},
+	}
+}
+
+// extractGeneric sends text content as is and describes binaries by their detected type.
+func extractGeneric(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error) {
+	if fileType.Binary {
+		return binaryContent(fileType), nil
+	}
+	if !opts.ExtractText {
+		return ExtractedContent{Sparse: true}, nil
+	}
+	return readBounded(path, opts.MaxContentLength)
+}
+
+func extractImage(path string, _ FileType, opts ExtractOptions) (ExtractedContent, error) {
+	return readImageFile(path, opts.ExtractMetadata), nil
+}
+
+func extractDocument(path string, _ FileType, opts ExtractOptions) (ExtractedContent, error) {
+	return readDocumentContent(path, opts)
+}
+
+func extractTags(path string, _ FileType, opts ExtractOptions) (ExtractedContent, error) {
+	if !opts.ExtractMetadata {
+		return ExtractedContent{Sparse: true}, nil
+	}
+	return readMetadata(path)
+}
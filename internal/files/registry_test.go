@@ -0,0 +1,110 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileType  FileType
+		extension string
+		format    string
+		category  string
+	}{
+		{name: "Extension", fileType: FileType{MIME: "text/plain"}, extension: ".TXT", format: "text-documents", category: CategoryDocuments},
+		{name: "Sniffed type wins over extension", fileType: FileType{MIME: "image/jpeg", Binary: true, sniffed: true}, extension: ".txt", format: "images", category: CategoryImages},
+		{name: "Unsniffed content keeps the extension", fileType: FileType{MIME: "text/plain"}, extension: ".pdf", format: "documents", category: CategoryDocuments},
+		{name: "MIME family", fileType: FileType{MIME: "video/x-flv", Binary: true, sniffed: true}, extension: ".flv", format: "videos", category: CategoryVideos},
+		{name: "Text fallback", fileType: FileType{MIME: "text/plain"}, extension: "", format: "text", category: CategoryOthers},
+		{name: "Unknown binary", fileType: FileType{MIME: "application/vnd.sqlite3", Binary: true, sniffed: true}, extension: ".txt", format: "other", category: CategoryOthers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := DefaultRegistry.Lookup(tt.fileType, tt.extension)
+			if format.Name != tt.format || format.Category != tt.category {
+				t.Fatalf("Lookup() = %s (%s), want %s (%s)", format.Name, format.Category, tt.format, tt.category)
+			}
+		})
+	}
+
+	if !IsImageFile("photo.JPG") || IsImageFile("anim.gif") || !IsDocumentFile("paper.pdf") || IsDocumentFile("notes.txt") {
+		t.Fatal("IsImageFile() or IsDocumentFile() disagree with the registry")
+	}
+}
+
+// TestRegistryCategories pins the folder --organize puts each extension in,
+// so moving one is a deliberate, documented change.
+func TestRegistryCategories(t *testing.T) {
+	categories := map[string][]string{
+		CategoryImages: {".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp", ".tif", ".tiff", ".heic", ".avif", ".svg"},
+		CategoryDocuments: {
+			".pdf", ".doc", ".docx", ".txt", ".md", ".rtf",
+			".epub", ".xls", ".xlsx", ".ppt", ".pptx", ".odt", ".ods", ".odp", ".eml", ".mbox",
+		},
+		CategoryAudios: {".mp3", ".wav", ".flac", ".m4a", ".aac", ".ogg", ".dsf"},
+		CategoryVideos: {".mp4", ".mov", ".avi", ".mkv", ".wmv", ".m4v", ".webm", ".3gp"},
+		CategoryOthers: {
+			".json", ".yaml", ".yml", ".log", ".ini", ".conf", ".cfg", ".sql", ".csv", ".tsv", ".tab",
+			".html", ".htm", ".xhtml", ".xml", ".rss", ".atom", ".xsd", ".xsl", ".kml", ".gpx", ".plist",
+			".go", ".py", ".pyw", ".js", ".mjs", ".cjs", ".jsx", ".ts", ".mts", ".cts", ".tsx", ".c", ".h", ".cpp", ".cc", ".cxx", ".hpp", ".hh", ".hxx",
+			".java", ".cs", ".kt", ".kts", ".scala", ".sc", ".dart", ".sh", ".bash", ".zsh", ".ksh", ".rb", ".rake", ".php", ".swift", ".pl", ".pm", ".rs", ".lua", ".ps1", ".psm1",
+			".zip", ".jar", ".tar", ".tgz", ".gz", ".tbz", ".tbz2", ".bz2", ".txz", ".xz", ".7z", ".rar",
+		},
+	}
+
+	pinned := make(map[string]bool)
+	for category, extensions := range categories {
+		for _, extension := range extensions {
+			pinned[extension] = true
+			if got := FormatForExtension("file" + extension).Category; got != category {
+				t.Errorf("category of %s = %s, want %s", extension, got, category)
+			}
+		}
+	}
+	for _, format := range Formats() {
+		for _, extension := range format.Extensions {
+			if !pinned[extension] {
+				t.Errorf("%s (%s) is organized into %s but not pinned here", extension, format.Name, format.Category)
+			}
+		}
+	}
+}
+
+func TestRegistryLaterFormatsWin(t *testing.T) {
+	registry := NewRegistry(builtinFormats()...)
+	registry.Register(Format{
+		Name:       "notes",
+		Extensions: []string{"TXT"},
+		Extractor: ExtractorFunc(func(string, FileType, ExtractOptions) (ExtractedContent, error) {
+			return ExtractedContent{Text: "custom"}, nil
+		}),
+	})
+
+	format := registry.Lookup(FileType{MIME: "text/plain"}, ".txt")
+	if format.Name != "notes" || format.Category != CategoryOthers {
+		t.Fatalf("Lookup() = %+v, want the registered notes format in Others", format)
+	}
+	extracted, err := format.extractor().Extract("notes.txt", FileType{}, DefaultExtractOptions())
+	if err != nil || extracted.Text != "custom" {
+		t.Fatalf("Extract() = %+v, %v, want the custom extractor", extracted, err)
+	}
+}
+
+func TestExtractFileContentSetsCategory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.txt")
+	if err := os.WriteFile(path, []byte("%PDF-1.7\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if content.MIME != "application/pdf" || content.Category != CategoryDocuments {
+		t.Fatalf("ExtractFileContent() = %+v, want a PDF document", content)
+	}
+}
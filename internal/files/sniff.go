@@ -0,0 +1,402 @@
+package files
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// sniffLength is how much of the start of a file is read to detect its type.
+const sniffLength = 512
+
+// FileType is what a file's content says it is, regardless of its extension.
+type FileType struct {
+	MIME        string
+	Description string
+	// Binary is set when the content is not text.
+	Binary bool
+	// Header holds facts read from the file header, such as an ISO volume label.
+	Header []string
+
+	// sniffed is set when a signature matched, as opposed to a guess from text or the extension.
+	sniffed bool
+}
+
+// signature identifies a format by the bytes at offset. refine, when set,
+// narrows container formats such as zip down using the header and extension.
+// Short magics that could also start a text file set binaryHeader to require
+// a NUL byte in the header.
+type signature struct {
+	offset       int
+	magic        string
+	mime         string
+	description  string
+	binaryHeader bool
+	refine       func(header []byte, extension string) (mime, description string)
+}
+
+var signatures = []signature{
+	{magic: "%PDF-", mime: "application/pdf", description: "PDF document"},
+	{magic: "\x89PNG\r\n\x1a\n", mime: "image/png", description: "PNG image"},
+	{magic: "\xff\xd8\xff", mime: "image/jpeg", description: "JPEG image"},
+	{magic: "GIF87a", mime: "image/gif", description: "GIF image"},
+	{magic: "GIF89a", mime: "image/gif", description: "GIF image"},
+	{magic: "BM", mime: "image/bmp", description: "BMP image", binaryHeader: true},
+	{magic: "II*\x00", mime: "image/tiff", description: "TIFF image"},
+	{magic: "MM\x00*", mime: "image/tiff", description: "TIFF image"},
+	{magic: "RIFF", mime: "application/octet-stream", description: "RIFF container", refine: refineRIFF},
+	{offset: 4, magic: "ftyp", mime: "video/mp4", description: "MP4 video", refine: refineISOBMFF},
+	{magic: "\x1a\x45\xdf\xa3", mime: "video/x-matroska", description: "Matroska video", refine: refineMatroska},
+	{magic: "ID3", mime: "audio/mpeg", description: "MP3 audio"},
+	{magic: "\xff\xfb", mime: "audio/mpeg", description: "MP3 audio"},
+	{magic: "\xff\xf3", mime: "audio/mpeg", description: "MP3 audio"},
+	{magic: "\xff\xf2", mime: "audio/mpeg", description: "MP3 audio"},
+	{magic: "fLaC", mime: "audio/flac", description: "FLAC audio"},
+	{magic: "OggS", mime: "audio/ogg", description: "Ogg audio"},
+	{magic: "DSD ", mime: "audio/x-dsf", description: "DSF audio"},
+	{magic: "PK\x03\x04", mime: "application/zip", description: "ZIP archive", refine: refineZip},
+	{magic: "PK\x05\x06", mime: "application/zip", description: "empty ZIP archive", refine: refineZip},
+	{magic: "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1", mime: "application/x-ole-storage", description: "OLE compound document", refine: refineOLE},
+	{magic: "\x1f\x8b", mime: "application/gzip", description: "gzip archive"},
+	{magic: "BZh", mime: "application/x-bzip2", description: "bzip2 archive"},
+	{magic: "\xfd7zXZ\x00", mime: "application/x-xz", description: "xz archive"},
+	{magic: "\x28\xb5\x2f\xfd", mime: "application/zstd", description: "Zstandard archive"},
+	{magic: "7z\xbc\xaf\x27\x1c", mime: "application/x-7z-compressed", description: "7-Zip archive"},
+	{magic: "Rar!\x1a\x07", mime: "application/vnd.rar", description: "RAR archive"},
+	{offset: 257, magic: "ustar", mime: "application/x-tar", description: "tar archive"},
+	{offset: 32769, magic: "CD001", mime: "application/x-iso9660-image", description: "ISO 9660 disc image"},
+	{magic: "SQLite format 3\x00", mime: "application/vnd.sqlite3", description: "SQLite database"},
+	{magic: "\x7fELF", mime: "application/x-elf", description: "ELF executable"},
+	{magic: "MZ", mime: "application/vnd.microsoft.portable-executable", description: "Windows executable", binaryHeader: true},
+	{magic: "\xcf\xfa\xed\xfe", mime: "application/x-mach-binary", description: "Mach-O executable"},
+	{magic: "\xce\xfa\xed\xfe", mime: "application/x-mach-binary", description: "Mach-O executable"},
+	{magic: "\xca\xfe\xba\xbe", mime: "application/x-mach-binary", description: "Mach-O universal binary"},
+	{magic: "\x00asm", mime: "application/wasm", description: "WebAssembly module"},
+	{magic: "wOFF", mime: "font/woff", description: "WOFF font"},
+	{magic: "wOF2", mime: "font/woff2", description: "WOFF2 font"},
+	{magic: "\x00\x01\x00\x00", mime: "font/ttf", description: "TrueType font"},
+	{magic: "OTTO", mime: "font/otf", description: "OpenType font"},
+	{magic: "{\\rtf", mime: "application/rtf", description: "RTF document"},
+}
+
+// zipContainers maps the extensions of zip based formats to their MIME types.
+var zipContainers = map[string][2]string{
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "Word document"},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "Excel spreadsheet"},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", "PowerPoint presentation"},
+	".epub": {"application/epub+zip", "EPUB book"},
+	".odt":  {"application/vnd.oasis.opendocument.text", "OpenDocument text"},
+	".ods":  {"application/vnd.oasis.opendocument.spreadsheet", "OpenDocument spreadsheet"},
+	".odp":  {"application/vnd.oasis.opendocument.presentation", "OpenDocument presentation"},
+	".jar":  {"application/java-archive", "Java archive"},
+	".apk":  {"application/vnd.android.package-archive", "Android package"},
+}
+
+// extensionTypes is used when a file cannot be read to sniff it.
+var extensionTypes = map[string][2]string{
+	".pdf":  {"application/pdf", "PDF document"},
+	".png":  {"image/png", "PNG image"},
+	".jpg":  {"image/jpeg", "JPEG image"},
+	".jpeg": {"image/jpeg", "JPEG image"},
+	".webp": {"image/webp", "WebP image"},
+	".gif":  {"image/gif", "GIF image"},
+	".mp3":  {"audio/mpeg", "MP3 audio"},
+	".flac": {"audio/flac", "FLAC audio"},
+	".ogg":  {"audio/ogg", "Ogg audio"},
+	".wav":  {"audio/wav", "WAV audio"},
+	".m4a":  {"audio/mp4", "MPEG-4 audio"},
+	".dsf":  {"audio/x-dsf", "DSF audio"},
+	".mp4":  {"video/mp4", "MP4 video"},
+	".mov":  {"video/quicktime", "QuickTime video"},
+	".xls":  {"application/vnd.ms-excel", "Excel spreadsheet"},
+	".zip":  {"application/zip", "ZIP archive"},
+}
+
+// textExtensions gives text files a more specific MIME type than text/plain.
+var textExtensions = map[string]string{
+	".md":   "text/markdown",
+	".json": "application/json",
+	".csv":  "text/csv",
+	".tsv":  "text/tab-separated-values",
+	".xml":  "application/xml",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".html": "text/html",
+	".htm":  "text/html",
+	".svg":  "image/svg+xml",
+	".js":   "text/javascript",
+	".eml":  "message/rfc822",
+	".mbox": "application/mbox",
+	".go":   "text/x-go",
+	".py":   "text/x-python",
+}
+
+// DetectFileType sniffs the type of path from its leading bytes, falling back
+// to http.DetectContentType and the extension for formats without a signature.
+func DetectFileType(path string) (FileType, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return FileType{}, err
+	}
+	defer file.Close()
+
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return FileType{}, fmt.Errorf("reading file header: %w", err)
+	}
+	return detectFileType(header[:n], file, strings.ToLower(filepath.Ext(path))), nil
+}
+
+// FileTypeForExtension guesses the type of a file that cannot be read.
+func FileTypeForExtension(extension string) FileType {
+	extension = strings.ToLower(extension)
+	if known, ok := extensionTypes[extension]; ok {
+		return FileType{MIME: known[0], Description: known[1], Binary: !isTextMIME(known[0])}
+	}
+	if container, ok := zipContainers[extension]; ok {
+		return FileType{MIME: container[0], Description: container[1], Binary: true}
+	}
+	if mime, ok := textExtensions[extension]; ok {
+		return FileType{MIME: mime, Description: "text"}
+	}
+	return FileType{MIME: "text/plain", Description: "text"}
+}
+
+func detectFileType(header []byte, file io.ReaderAt, extension string) FileType {
+	for _, candidate := range signatures {
+		if !hasMagic(header, file, candidate.offset, candidate.magic) {
+			continue
+		}
+		if candidate.binaryHeader && bytes.IndexByte(header[:min(len(header), 64)], 0) < 0 {
+			continue
+		}
+
+		fileType := FileType{MIME: candidate.mime, Description: candidate.description, sniffed: true}
+		if candidate.refine != nil {
+			fileType.MIME, fileType.Description = candidate.refine(header, extension)
+		}
+		fileType.Header = headerFacts(fileType.MIME, header, file)
+		fileType.Binary = !isTextMIME(fileType.MIME)
+		return fileType
+	}
+
+	mime, _, _ := strings.Cut(http.DetectContentType(header), ";")
+	if !isTextMIME(mime) && !hasBinaryBytes(header) {
+		// Signatures rejected above, such as text starting with "BM", are still text.
+		mime = "text/plain"
+	}
+	if mime == "text/plain" {
+		if specific, ok := textExtensions[extension]; ok {
+			mime = specific
+		} else if script := scriptMIME(header); script != "" {
+			mime = script
+		} else if mail := mailMIME(header); mail != "" {
+			mime = mail
+		}
+	}
+	if isTextMIME(mime) {
+		return FileType{MIME: mime, Description: "text"}
+	}
+	return FileType{MIME: mime, Description: "binary data", Binary: true}
+}
+
+// scriptMIME gives scripts without a known extension the type of the
+// interpreter their shebang line names.
+func scriptMIME(header []byte) string {
+	if !bytes.HasPrefix(header, []byte("#!")) {
+		return ""
+	}
+	language, ok := codeLanguageFor("", "", string(header))
+	if !ok {
+		return ""
+	}
+	return language.mimeTypes[0]
+}
+
+// mailHeaders start saved messages; mail clients write one of them first.
+var mailHeaders = []string{"Return-Path:", "Received:", "Delivered-To:", "Message-ID:", "MIME-Version:", "X-Mozilla-Status:"}
+
+// mailMIME recognizes saved messages and mbox files by their first line.
+func mailMIME(header []byte) string {
+	line, _, _ := bytes.Cut(header, []byte("\n"))
+	switch {
+	case bytes.HasPrefix(line, []byte("From ")) && bytes.Contains(header, []byte("\nFrom:")):
+		return "application/mbox"
+	case slices.ContainsFunc(mailHeaders, func(name string) bool { return bytes.HasPrefix(bytes.ToLower(line), bytes.ToLower([]byte(name))) }):
+		return "message/rfc822"
+	}
+	return ""
+}
+
+func hasMagic(header []byte, file io.ReaderAt, offset int, magic string) bool {
+	if offset+len(magic) <= len(header) {
+		return string(header[offset:offset+len(magic)]) == magic
+	}
+	// A header shorter than sniffLength means the file ends before offset.
+	if file == nil || len(header) < sniffLength {
+		return false
+	}
+
+	buf := make([]byte, len(magic))
+	if _, err := file.ReadAt(buf, int64(offset)); err != nil {
+		return false
+	}
+	return string(buf) == magic
+}
+
+// isTextMIME reports whether content of this type can be sent as text.
+func isTextMIME(mime string) bool {
+	return strings.HasPrefix(mime, "text/") ||
+		strings.HasSuffix(mime, "+xml") ||
+		strings.HasSuffix(mime, "+json") ||
+		mime == "application/json" ||
+		mime == "application/xml" ||
+		mime == "application/yaml" ||
+		mime == "application/javascript" ||
+		mime == "message/rfc822" ||
+		mime == "application/mbox" ||
+		mime == "application/rtf"
+}
+
+// hasBinaryBytes applies the control character test of http.DetectContentType.
+func hasBinaryBytes(header []byte) bool {
+	for _, b := range header {
+		if b <= 0x08 || b == 0x0b || (b >= 0x0e && b <= 0x1a) || (b >= 0x1c && b <= 0x1f) {
+			return true
+		}
+	}
+	return false
+}
+
+func refineZip(header []byte, extension string) (string, string) {
+	if container, ok := zipContainers[extension]; ok {
+		return container[0], container[1]
+	}
+	// OpenDocument files start with an uncompressed "mimetype" entry naming their type.
+	if len(header) > 38 && string(header[30:38]) == "mimetype" {
+		mime := header[38:]
+		if end := bytes.Index(mime, []byte("PK")); end >= 0 {
+			mime = mime[:end]
+		}
+		for _, container := range zipContainers {
+			if container[0] == string(mime) {
+				return container[0], container[1]
+			}
+		}
+	}
+	return "application/zip", "ZIP archive"
+}
+
+func refineOLE(_ []byte, extension string) (string, string) {
+	switch extension {
+	case ".xls":
+		return "application/vnd.ms-excel", "Excel spreadsheet"
+	case ".doc":
+		return "application/msword", "Word document"
+	case ".ppt":
+		return "application/vnd.ms-powerpoint", "PowerPoint presentation"
+	case ".msg":
+		return "application/vnd.ms-outlook", "Outlook message"
+	}
+	return "application/x-ole-storage", "OLE compound document"
+}
+
+func refineRIFF(header []byte, _ string) (string, string) {
+	if len(header) < 12 {
+		return "application/octet-stream", "RIFF container"
+	}
+	switch string(header[8:12]) {
+	case "WEBP":
+		return "image/webp", "WebP image"
+	case "WAVE":
+		return "audio/wav", "WAV audio"
+	case "AVI ":
+		return "video/x-msvideo", "AVI video"
+	}
+	return "application/octet-stream", "RIFF container"
+}
+
+func refineISOBMFF(header []byte, extension string) (string, string) {
+	if len(header) < 12 {
+		return "video/mp4", "MP4 video"
+	}
+	switch brand := string(header[8:12]); {
+	case brand == "qt  ":
+		return "video/quicktime", "QuickTime video"
+	case brand == "M4A " || brand == "M4B " || extension == ".m4a":
+		return "audio/mp4", "MPEG-4 audio"
+	case brand == "heic" || brand == "heix" || brand == "mif1" || brand == "msf1":
+		return "image/heic", "HEIC image"
+	case brand == "avif":
+		return "image/avif", "AVIF image"
+	case strings.HasPrefix(brand, "3g"):
+		return "video/3gpp", "3GPP video"
+	}
+	return "video/mp4", "MP4 video"
+}
+
+func refineMatroska(header []byte, _ string) (string, string) {
+	if bytes.Contains(header, []byte("webm")) {
+		return "video/webm", "WebM video"
+	}
+	return "video/x-matroska", "Matroska video"
+}
+
+// headerFacts reads a few identifying fields from the headers of common binary formats.
+func headerFacts(mime string, header []byte, file io.ReaderAt) []string {
+	var facts []string
+	switch mime {
+	case "application/x-iso9660-image":
+		label := make([]byte, 32)
+		if file != nil {
+			if _, err := file.ReadAt(label, 32808); err == nil {
+				if volume := strings.TrimSpace(string(label)); volume != "" {
+					facts = append(facts, "Volume label: "+volume)
+				}
+			}
+		}
+	case "application/x-elf":
+		if len(header) >= 20 {
+			class := map[byte]string{1: "32-bit", 2: "64-bit"}[header[4]]
+			kind := map[uint16]string{1: "relocatable", 2: "executable", 3: "shared object", 4: "core dump"}
+			order := binary.ByteOrder(binary.LittleEndian)
+			if header[5] == 2 {
+				order = binary.BigEndian
+			}
+			if name := kind[order.Uint16(header[16:18])]; name != "" {
+				facts = append(facts, strings.TrimSpace("ELF "+class+" "+name))
+			}
+		}
+	case "application/vnd.sqlite3":
+		if len(header) >= 100 {
+			pageSize := int(binary.BigEndian.Uint16(header[16:18]))
+			if pageSize == 1 {
+				pageSize = 65536
+			}
+			pages := binary.BigEndian.Uint32(header[28:32])
+			facts = append(facts, fmt.Sprintf("SQLite page size %d, %d pages", pageSize, pages))
+		}
+	case "application/x-tar":
+		if name := strings.TrimRight(string(header[:100]), "\x00"); name != "" {
+			facts = append(facts, "First entry: "+name)
+		}
+	}
+	return facts
+}
+
+// binaryContent describes a file that has no text worth sending.
+func binaryContent(fileType FileType) ExtractedContent {
+	lines := []string{
+		"Binary file, no text was extracted.",
+		fmt.Sprintf("Detected type: %s (%s)", fileType.Description, fileType.MIME),
+	}
+	lines = append(lines, fileType.Header...)
+	return ExtractedContent{Text: strings.Join(lines, "\n"), Sparse: true}
+}
@@ -0,0 +1,87 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectFileType(t *testing.T) {
+	sqlite := append([]byte("SQLite format 3\x00\x10\x00"), make([]byte, 120)...)
+	elf := append([]byte("\x7fELF\x02\x01\x01"), make([]byte, 9)...)
+	elf = append(elf, 0x02, 0x00, 0x3e, 0x00)
+
+	tests := []struct {
+		name   string
+		file   string
+		data   []byte
+		mime   string
+		binary bool
+	}{
+		{name: "PNG with a text extension", file: "photo.txt", data: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), mime: "image/png", binary: true},
+		{name: "SQLite database", file: "app.db", data: sqlite, mime: "application/vnd.sqlite3", binary: true},
+		{name: "ELF executable", file: "tool", data: elf, mime: "application/x-elf", binary: true},
+		{name: "Word document", file: "letter.docx", data: []byte("PK\x03\x04\x14\x00\x00\x00"), mime: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", binary: true},
+		{name: "Plain zip", file: "bundle.zip", data: []byte("PK\x03\x04\x14\x00\x00\x00"), mime: "application/zip", binary: true},
+		{name: "WebP image", file: "image.bin", data: []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), mime: "image/webp", binary: true},
+		{name: "JSON text", file: "data.json", data: []byte(`{"name": "value"}`), mime: "application/json"},
+		{name: "Text starting like a bitmap", file: "notes", data: []byte("BMW service notes"), mime: "text/plain"},
+		{name: "Unknown bytes", file: "blob.dat", data: []byte{0x01, 0x02, 0x03, 0x00, 0xfe}, mime: "application/octet-stream", binary: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			fileType, err := DetectFileType(path)
+			if err != nil {
+				t.Fatalf("DetectFileType() error = %v", err)
+			}
+			if fileType.MIME != tt.mime || fileType.Binary != tt.binary {
+				t.Fatalf("DetectFileType() = %+v, want %s with binary %t", fileType, tt.mime, tt.binary)
+			}
+		})
+	}
+}
+
+func TestExtractFileContentBinaryIsMetadataOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.sqlite")
+	data := append([]byte("SQLite format 3\x00\x10\x00"), make([]byte, 200)...)
+	data = append(data, []byte("secret row contents")...)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if content.MIME != "application/vnd.sqlite3" || !content.Sparse {
+		t.Fatalf("ExtractFileContent() = %+v, want sparse SQLite content", content)
+	}
+	if !strings.Contains(content.Text, "Detected type: SQLite database") || !strings.Contains(content.Text, "page size 4096") {
+		t.Fatalf("Text = %q, want the detected type and header info", content.Text)
+	}
+	if strings.Contains(content.Text, "secret row contents") {
+		t.Fatalf("Text = %q, should not include raw bytes", content.Text)
+	}
+}
+
+func TestExtractFileContentRoutesMisnamedImage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.txt")
+	if err := os.WriteFile(path, []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if content.MIME != "image/jpeg" || content.PreviewImagePath != path {
+		t.Fatalf("ExtractFileContent() = %+v, want a JPEG with a visual preview", content)
+	}
+}
@@ -0,0 +1,263 @@
+package files
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxTableRows caps the data rows read from a delimited file or sheet;
+	// larger tables report their row count as a lower bound.
+	maxTableRows = 20000
+	// maxTableColumns caps the columns described and the cells kept per row.
+	maxTableColumns = 30
+	// maxTableSamples is how many data rows are listed as samples.
+	maxTableSamples = 5
+	// maxTableValues is how many distinct values a column may have to be listed.
+	maxTableValues = 6
+	// maxTableCell clips long cells in sample rows.
+	maxTableCell = 60
+)
+
+// cellKind is the type a single cell reads as.
+type cellKind int
+
+const (
+	cellText cellKind = iota
+	cellInteger
+	cellDecimal
+	cellDate
+	cellBoolean
+)
+
+// tableSummary collects what a table holds one row at a time, so large CSV
+// files and sheets are summarized without keeping their rows.
+type tableSummary struct {
+	header  []string
+	columns []columnStats
+	rows    int // Data rows, without the header
+	samples []string
+	started bool
+	partial bool // More rows followed the ones read
+}
+
+// columnStats describes the non-empty cells of one column.
+type columnStats struct {
+	filled               int
+	kinds                [cellBoolean + 1]int
+	minNumber, maxNumber float64
+	minDate, maxDate     time.Time
+	values               []string // Distinct values, until there are more than maxTableValues
+}
+
+// addRow records a row until maxTableRows data rows were read. A further
+// non-empty row marks the table partial and returns false.
+func (t *tableSummary) addRow(cells []string) bool {
+	if t.rows == maxTableRows && strings.TrimSpace(strings.Join(cells, "")) != "" {
+		t.partial = true
+		return false
+	}
+	t.add(cells)
+	return true
+}
+
+// add records a row. Empty rows are skipped; the first row is taken as the
+// header when none of its cells read as numbers, dates or booleans.
+func (t *tableSummary) add(cells []string) {
+	end := len(cells)
+	for end > 0 && strings.TrimSpace(cells[end-1]) == "" {
+		end--
+	}
+	if end == 0 {
+		return
+	}
+	cells = cells[:min(end, maxTableColumns)]
+
+	if !t.started {
+		t.started = true
+		if isHeaderRow(cells) {
+			for _, cell := range cells {
+				t.header = append(t.header, collapseSpace(cell))
+			}
+			t.columns = make([]columnStats, len(cells))
+			return
+		}
+	}
+
+	t.rows++
+	for len(t.columns) < len(cells) {
+		t.columns = append(t.columns, columnStats{})
+	}
+	for index, cell := range cells {
+		t.columns[index].add(strings.TrimSpace(cell))
+	}
+	if len(t.samples) < maxTableSamples {
+		clipped := make([]string, len(cells))
+		for index, cell := range cells {
+			clipped[index] = clip(collapseSpace(cell), maxTableCell)
+		}
+		t.samples = append(t.samples, joinCells(clipped))
+	}
+}
+
+func isHeaderRow(cells []string) bool {
+	for _, cell := range cells {
+		if kind, _, _ := readCell(cell); kind != cellText {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *columnStats) add(value string) {
+	if value == "" {
+		return
+	}
+	kind, number, date := readCell(value)
+	c.filled++
+	c.kinds[kind]++
+	switch kind {
+	case cellInteger, cellDecimal:
+		if c.kinds[cellInteger]+c.kinds[cellDecimal] == 1 || number < c.minNumber {
+			c.minNumber = number
+		}
+		if c.kinds[cellInteger]+c.kinds[cellDecimal] == 1 || number > c.maxNumber {
+			c.maxNumber = number
+		}
+	case cellDate:
+		if c.minDate.IsZero() || date.Before(c.minDate) {
+			c.minDate = date
+		}
+		if date.After(c.maxDate) {
+			c.maxDate = date
+		}
+	}
+	if value = clip(collapseSpace(value), maxTableCell); len(c.values) <= maxTableValues && !containsFold(c.values, value) {
+		c.values = append(c.values, value)
+	}
+}
+
+// kind is the type that covers every non-empty cell of the column.
+func (c columnStats) kind() string {
+	switch c.filled {
+	case 0:
+		return "empty"
+	case c.kinds[cellInteger]:
+		return "integer"
+	case c.kinds[cellInteger] + c.kinds[cellDecimal]:
+		return "decimal"
+	case c.kinds[cellDate]:
+		return "date"
+	case c.kinds[cellBoolean]:
+		return "boolean"
+	}
+	return "text"
+}
+
+// readCell reads a cell as a number, a date or a boolean, falling back to text.
+// Thousands separators, currency symbols and percent signs are ignored.
+func readCell(value string) (cellKind, float64, time.Time) {
+	value = strings.TrimSpace(value)
+	if value == "" || len(value) > 40 {
+		return cellText, 0, time.Time{}
+	}
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no":
+		return cellBoolean, 0, time.Time{}
+	}
+
+	number := strings.TrimSuffix(strings.TrimLeft(value, "$€£¥"), "%")
+	number = strings.ReplaceAll(number, ",", "")
+	if integer, err := strconv.ParseInt(number, 10, 64); err == nil && number != "" {
+		return cellInteger, float64(integer), time.Time{}
+	}
+	if decimal, err := strconv.ParseFloat(number, 64); err == nil && strings.Trim(number, "0123456789.+-") == "" {
+		return cellDecimal, decimal, time.Time{}
+	}
+	if date, ok := parseLooseDate(value); ok && value[0] >= '0' && value[0] <= '9' {
+		return cellDate, 0, date
+	}
+	return cellText, 0, time.Time{}
+}
+
+// describe lists the columns with their types and ranges, the row count and,
+// when withSamples is set, the first data rows.
+func (t *tableSummary) describe(withSamples bool) []string {
+	if !t.started {
+		return []string{"Rows: 0"}
+	}
+
+	names := make([]string, len(t.columns))
+	for index := range t.columns {
+		names[index] = fmt.Sprintf("column %d", index+1)
+		if index < len(t.header) && t.header[index] != "" {
+			names[index] = t.header[index]
+		}
+	}
+	lines := []string{
+		fmt.Sprintf("Columns (%d): %s", len(names), strings.Join(names, ", ")),
+		fmt.Sprintf("Rows: %d", t.rows),
+	}
+	if t.partial {
+		lines[1] = fmt.Sprintf("Rows: at least %d", t.rows)
+	}
+	if t.rows == 0 {
+		return lines
+	}
+
+	lines = append(lines, "Column types:")
+	var first, last time.Time
+	for index, column := range t.columns {
+		kind := column.kind()
+		line := "- " + names[index] + ": " + kind
+		switch kind {
+		case "integer", "decimal":
+			line += ", " + formatNumber(column.minNumber)
+			if column.maxNumber != column.minNumber {
+				line += " to " + formatNumber(column.maxNumber)
+			}
+		case "date":
+			line += ", " + column.minDate.Format("2006-01-02") + " to " + column.maxDate.Format("2006-01-02")
+			if first.IsZero() || column.minDate.Before(first) {
+				first = column.minDate
+			}
+			if column.maxDate.After(last) {
+				last = column.maxDate
+			}
+		case "text", "boolean":
+			if len(column.values) <= maxTableValues {
+				line += ", values: " + strings.Join(column.values, ", ")
+			}
+		}
+		if empty := t.rows - column.filled; empty > 0 && column.filled > 0 {
+			line += fmt.Sprintf(", %d empty", empty)
+		}
+		lines = append(lines, line)
+	}
+	if !first.IsZero() {
+		lines = append(lines, "Date range: "+first.Format("2006-01-02")+" to "+last.Format("2006-01-02"))
+	}
+
+	if withSamples {
+		lines = append(lines, "Sample rows:")
+		for _, row := range t.samples {
+			lines = append(lines, "  "+row)
+		}
+	}
+	return lines
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, existing := range values {
+		if strings.EqualFold(existing, value) {
+			return true
+		}
+	}
+	return false
+}
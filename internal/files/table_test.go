@@ -0,0 +1,152 @@
+package files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractFileContentTable(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		want []string
+	}{
+		{
+			name: "Comma separated with header",
+			file: "export.csv",
+			data: "\ufeffdate,region,revenue,notes\n" +
+				"2023-10-01,North,\"1,200.50\",\n" +
+				"2023-11-15,South,980,\"late, paid\"\n" +
+				"2023-12-31,North,75,\n",
+			want: []string{
+				"Delimiter: comma", "Encoding: UTF-8",
+				"Columns (4): date, region, revenue, notes", "Rows: 3",
+				"- date: date, 2023-10-01 to 2023-12-31",
+				"- region: text, values: North, South",
+				"- revenue: decimal, 75 to 1200.5",
+				"- notes: text, values: late, paid, 2 empty",
+				"Date range: 2023-10-01 to 2023-12-31",
+				"Sample rows:\n  2023-10-01 | North | 1,200.50\n  2023-11-15 | South | 980 | late, paid",
+			},
+		},
+		{
+			name: "Semicolons in Windows-1252",
+			file: "kunden.csv",
+			data: "Name;Stadt;Aktiv\nM\xfcller;K\xf6ln;yes\nSchmidt;Berlin;no\n",
+			want: []string{"Delimiter: semicolon", "Encoding: Windows-1252", "Columns (3): Name, Stadt, Aktiv", "- Stadt: text, values: Köln, Berlin", "- Aktiv: boolean"},
+		},
+		{
+			name: "Tabs without header",
+			file: "readings.tsv",
+			data: "1\t20.5\n2\t21\n3\t19.75\n",
+			want: []string{"Delimiter: tab", "Columns (2): column 1, column 2", "Rows: 3", "- column 1: integer, 1 to 3", "- column 2: decimal, 19.75 to 21"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			content, err := ExtractFileContent(path, DefaultExtractOptions())
+			if err != nil {
+				t.Fatalf("ExtractFileContent() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(content.Text, want) {
+					t.Fatalf("Text = %q, want %q", content.Text, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractFileContentLargeTable(t *testing.T) {
+	var data strings.Builder
+	data.WriteString("id,amount\n")
+	for row := range maxTableRows + 50 {
+		fmt.Fprintf(&data, "%d,%d.5\n", row+1, row%7)
+	}
+	path := filepath.Join(t.TempDir(), "ledger.csv")
+	if err := os.WriteFile(path, []byte(data.String()), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	for _, want := range []string{"Rows: at least 20000", "- id: integer, 1 to 20000", "Note: only the first 20000 rows were read"} {
+		if !strings.Contains(content.Text, want) {
+			t.Fatalf("Text = %q, want %q", content.Text, want)
+		}
+	}
+}
+
+func TestExtractFileContentLargeSheets(t *testing.T) {
+	var rows strings.Builder
+	rows.WriteString(`<row><c r="A1" t="inlineStr"><is><t>id</t></is></c></row>`)
+	for row := 2; row <= maxTableRows+50; row++ {
+		fmt.Fprintf(&rows, `<row><c r="A%d"><v>%d</v></c></row>`, row, row-1)
+	}
+	workbook := writeZip(t, "ledger.xlsx",
+		"[Content_Types].xml", `<Types/>`,
+		"xl/workbook.xml", `<workbook><sheets><sheet name="Ledger" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels", `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml", `<worksheet><sheetData>`+rows.String()+`</sheetData></worksheet>`,
+	)
+	spreadsheet := writeZip(t, "ledger.ods",
+		"mimetype", "application/vnd.oasis.opendocument.spreadsheet",
+		"content.xml", `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:spreadsheet>
+			<table:table table:name="Ledger">
+				<table:table-row><table:table-cell><text:p>id</text:p></table:table-cell></table:table-row>
+				<table:table-row table:number-rows-repeated="10000"><table:table-cell><text:p>7</text:p></table:table-cell></table:table-row>
+				<table:table-row table:number-rows-repeated="10000"><table:table-cell><text:p>8</text:p></table:table-cell></table:table-row>
+				<table:table-row><table:table-cell><text:p>9</text:p></table:table-cell></table:table-row>
+			</table:table>
+		</office:spreadsheet></office:body></office:document-content>`,
+	)
+
+	for _, path := range []string{workbook, spreadsheet} {
+		content, err := ExtractFileContent(path, DefaultExtractOptions())
+		if err != nil {
+			t.Fatalf("ExtractFileContent(%s) error = %v", filepath.Base(path), err)
+		}
+		for _, want := range []string{"Rows: at least 20000", "Note: only the first 20000 rows were read"} {
+			if !strings.Contains(content.Text, want) {
+				t.Fatalf("%s: Text = %q, want %q", filepath.Base(path), content.Text, want)
+			}
+		}
+	}
+}
+
+func TestExtractFileContentWorkbookDates(t *testing.T) {
+	path := writeZip(t, "sales.xlsx",
+		"[Content_Types].xml", `<Types/>`,
+		"xl/workbook.xml", `<workbook><sheets><sheet name="Q4" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels", `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/styles.xml", `<styleSheet><numFmts><numFmt numFmtId="164" formatCode="[$-409]dd/mm/yyyy;@"/><numFmt numFmtId="165" formatCode="&quot;day&quot; 0"/></numFmts>
+			<cellStyleXfs><xf numFmtId="14"/></cellStyleXfs><cellXfs><xf numFmtId="0"/><xf numFmtId="164"/><xf numFmtId="14"/><xf numFmtId="165"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml", `<worksheet><sheetData>
+			<row><c r="A1" t="inlineStr"><is><t>Closed</t></is></c><c r="C1" t="inlineStr"><is><t>Units</t></is></c></row>
+			<row><c r="A2" s="1"><v>45204</v></c><c r="C2" s="3"><v>12</v></c></row>
+			<row><c r="A3" s="2"><v>45291.5</v></c><c r="C3" s="0"><v>3</v></c></row>
+		</sheetData></worksheet>`,
+	)
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	for _, want := range []string{"Columns (3): Closed, column 2, Units", "- Closed: date, 2023-10-05 to 2023-12-31", "- Units: integer, 3 to 12", "2023-10-05 |  | 12"} {
+		if !strings.Contains(content.Text, want) {
+			t.Fatalf("Text = %q, want %q", content.Text, want)
+		}
+	}
+}
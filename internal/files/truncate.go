@@ -0,0 +1,178 @@
+package files
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	utils "nomnom/internal/utils"
+)
+
+// omissionMarker replaces the middle of truncated content.
+const omissionMarker = "\n[...]\n"
+
+// ExtractOptions limits what ExtractFileContent reads from a file.
+type ExtractOptions struct {
+	// MaxContentLength is the number of bytes of text kept, split between the
+	// start and the end of the content. A negative value keeps everything.
+	MaxContentLength int
+	ExtractText      bool // Read file text and document pages
+	ExtractMetadata  bool // Read tags and document properties
+	// Commands run before the built-in extractor of matching files; the first match wins.
+	Commands []CommandExtractor
+}
+
+// DefaultExtractOptions returns the content_extraction defaults written by setup.
+func DefaultExtractOptions() ExtractOptions {
+	return NewExtractOptions(utils.DefaultConfig().ContentExtraction)
+}
+
+// NewExtractOptions converts the content_extraction config. A config without
+// any setting means the defaults, since its booleans would otherwise turn all
+// extraction off; a zero max_content_length also means the default. Command
+// extractors are added separately with NewCommandExtractors.
+func NewExtractOptions(config utils.ContentExtractionConfig) ExtractOptions {
+	defaults := utils.DefaultConfig().ContentExtraction
+	if !config.ExtractText && !config.ExtractMetadata && config.MaxContentLength == 0 && !config.SkipLargeFiles && !config.ReadContext {
+		config = defaults
+	}
+	if config.MaxContentLength == 0 {
+		config.MaxContentLength = defaults.MaxContentLength
+	}
+	return ExtractOptions{
+		MaxContentLength: config.MaxContentLength,
+		ExtractText:      config.ExtractText,
+		ExtractMetadata:  config.ExtractMetadata,
+	}
+}
+
+// readBounded reads at most limit bytes of path: the whole file when it fits,
+// otherwise its start and end joined by an omission marker.
+func readBounded(path string, limit int) (ExtractedContent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ExtractedContent{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return ExtractedContent{}, err
+	}
+	if limit < 0 || info.Size() <= int64(limit) {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return ExtractedContent{}, err
+		}
+		return ExtractedContent{Text: string(data)}, nil
+	}
+
+	headSize, tailSize := splitBudget(limit)
+	head := make([]byte, headSize)
+	if _, err := io.ReadFull(file, head); err != nil {
+		return ExtractedContent{}, fmt.Errorf("reading start of file: %w", err)
+	}
+	tail := make([]byte, tailSize)
+	if _, err := file.ReadAt(tail, info.Size()-int64(tailSize)); err != nil && err != io.EOF {
+		return ExtractedContent{}, fmt.Errorf("reading end of file: %w", err)
+	}
+
+	return ExtractedContent{
+		Text:       joinHeadTail(string(head), string(tail)),
+		Truncated:  true,
+		FullLength: info.Size(),
+	}, nil
+}
+
+// truncateContent applies the length limit to text that was extracted in full.
+func truncateContent(extracted ExtractedContent, limit int) ExtractedContent {
+	if extracted.Truncated || limit < 0 || len(extracted.Text) <= limit {
+		return extracted
+	}
+
+	headSize, tailSize := splitBudget(limit)
+	text := extracted.Text
+	extracted.FullLength = int64(len(text))
+	extracted.Text = joinHeadTail(text[:headSize], text[len(text)-tailSize:])
+	extracted.Truncated = true
+	return extracted
+}
+
+// splitBudget gives two thirds of the limit to the start, where titles and
+// summaries usually are, and the rest to the end.
+func splitBudget(limit int) (head, tail int) {
+	head = limit * 2 / 3
+	return head, limit - head
+}
+
+func joinHeadTail(head, tail string) string {
+	head = strings.TrimSpace(headPart(head))
+	tail = strings.TrimSpace(tailPart(tail))
+	return strings.ToValidUTF8(head+omissionMarker+tail, "")
+}
+
+// headPart cuts window after its last sentence end, falling back to the last
+// space when that would drop over half of it. Characters split by the window
+// are dropped by joinHeadTail.
+func headPart(window string) string {
+	if window == "" {
+		return ""
+	}
+	half := len(window) / 2
+
+	if cut := lastSentenceEnd(window); cut >= half {
+		return window[:cut]
+	}
+	if cut := strings.LastIndexFunc(window, unicode.IsSpace); cut >= half {
+		return window[:cut]
+	}
+	return window
+}
+
+// tailPart cuts window before its first sentence start in the same way.
+func tailPart(window string) string {
+	if window == "" {
+		return ""
+	}
+	half := len(window) / 2
+
+	if cut := firstSentenceStart(window); cut >= 0 && cut <= half {
+		return window[cut:]
+	}
+	if cut := strings.IndexFunc(window, unicode.IsSpace); cut >= 0 && cut <= half {
+		return window[cut:]
+	}
+	return window
+}
+
+// lastSentenceEnd returns the index just past the last sentence terminator or line break, or -1.
+func lastSentenceEnd(text string) int {
+	for index := len(text) - 1; index >= 0; index-- {
+		if isSentenceEnd(text, index) {
+			return index + 1
+		}
+	}
+	return -1
+}
+
+// firstSentenceStart returns the index just past the first sentence terminator or line break, or -1.
+func firstSentenceStart(text string) int {
+	for index := 0; index < len(text); index++ {
+		if isSentenceEnd(text, index) {
+			return index + 1
+		}
+	}
+	return -1
+}
+
+func isSentenceEnd(text string, index int) bool {
+	switch text[index] {
+	case '\n':
+		return true
+	case '.', '!', '?':
+		return index+1 < len(text) && (text[index+1] == ' ' || text[index+1] == '\n' || text[index+1] == '\t')
+	}
+	return false
+}
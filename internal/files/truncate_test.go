@@ -0,0 +1,79 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	utils "nomnom/internal/utils"
+)
+
+func TestNewExtractOptions(t *testing.T) {
+	defaults := NewExtractOptions(utils.ContentExtractionConfig{})
+	if !defaults.ExtractText || !defaults.ExtractMetadata || defaults.MaxContentLength != 5000 {
+		t.Fatalf("NewExtractOptions(zero) = %+v, want the setup defaults", defaults)
+	}
+
+	opts := NewExtractOptions(utils.ContentExtractionConfig{ExtractMetadata: true})
+	if opts.ExtractText || !opts.ExtractMetadata || opts.MaxContentLength != 5000 {
+		t.Fatalf("NewExtractOptions(metadata only) = %+v", opts)
+	}
+}
+
+func TestExtractFileContentTruncatesAtSentences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	text := "Quarterly report for Acme. " + strings.Repeat("Filler sentence here. ", 2000) + "Signed by the auditor."
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, ExtractOptions{MaxContentLength: 300, ExtractText: true})
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+
+	if !content.Truncated || content.FullLength != int64(len(text)) {
+		t.Fatalf("Truncated = %t, FullLength = %d, want truncated from %d", content.Truncated, content.FullLength, len(text))
+	}
+	if len(content.Text) > 300+len(omissionMarker) {
+		t.Fatalf("len(Text) = %d, want at most the limit plus the marker", len(content.Text))
+	}
+	head, tail, found := strings.Cut(content.Text, omissionMarker)
+	if !found {
+		t.Fatalf("Text = %q, want an omission marker", content.Text)
+	}
+	if !strings.HasPrefix(head, "Quarterly report for Acme.") || !strings.HasSuffix(head, ".") {
+		t.Fatalf("head = %q, want whole sentences from the start", head)
+	}
+	if !strings.HasPrefix(tail, "Filler") || !strings.HasSuffix(tail, "Signed by the auditor.") {
+		t.Fatalf("tail = %q, want whole sentences up to the end", tail)
+	}
+}
+
+func TestExtractFileContentHonorsExtractText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("private notes"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, ExtractOptions{MaxContentLength: 100})
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if content.Text != "" || !content.Sparse {
+		t.Fatalf("ExtractFileContent() = %+v, want no text when extract_text is off", content)
+	}
+}
+
+func TestTruncateContentKeepsShortText(t *testing.T) {
+	content := truncateContent(ExtractedContent{Text: "short"}, 10)
+	if content.Truncated || content.Text != "short" {
+		t.Fatalf("truncateContent() = %+v, want text unchanged", content)
+	}
+
+	content = truncateContent(ExtractedContent{Text: strings.Repeat("ab ", 20)}, -1)
+	if content.Truncated {
+		t.Fatal("truncateContent() truncated with a negative limit")
+	}
+}
@@ -1,87 +1,28 @@
 package files
 
 import (
+	"fmt"
 	"path/filepath"
-	"slices"
-	"strings"
 )
 
-// SupportedTypes lists the file types supported by nomnom, categorized by type.
-var SupportedTypes = map[string][]string{
-	"document": {
-		"pdf",
-		"docx",
-		"txt",
-		"md",
-		"html",
-		"htm",
-		"rtf",
-		"epub",
-		"odt",
-	},
-	"spreadsheet": {
-		"ods",
-		"csv",
-		"tsv",
-	},
-	"presentation": {
-		"odp",
-	},
-	"data": {
-		"json",
-		"yaml",
-		"yml",
-		"xml",
-		"log",
-		"ini",
-		"conf",
-		"cfg",
-		"sql",
-	},
-	"code": {
-		"go",
-		"py",
-		"js",
-		"ts",
-		"c",
-		"cpp",
-		"h",
-		"hpp",
-		"java",
-		"cs",
-		"sh",
-		"rb",
-		"php",
-		"swift",
-		"kt",
-		"scala",
-		"pl",
-		"rs",
-		"dart",
-		"lua",
-	},
-}
-
-// IsTypeSupported checks if the given file type is supported.
-func IsTypeSupported(fileType string) bool {
-	for _, types := range SupportedTypes {
-		if slices.Contains(types, fileType) {
-			return true
-		}
-	}
-	return false
-}
-
 func GetFileExtension(path string) string {
 	return filepath.Ext(path)
 }
 
+// IsDocumentFile reports whether fileName is read by the paged document extractor.
 func IsDocumentFile(fileName string) bool {
-	documentExtensions := []string{".pdf", ".docx", ".epub", ".pptx", ".xlsx", ".xls"}
-	for _, ext := range documentExtensions {
-		if strings.HasSuffix(strings.ToLower(fileName), ext) {
-			return true
-		}
+	return FormatForExtension(fileName).Name == "documents"
+}
+
+// FormatSize writes a byte count the way file sizes are shown in file context.
+func FormatSize(size int64) string {
+	switch {
+	case size < 1<<10:
+		return fmt.Sprintf("%dB", size)
+	case size < 1<<20:
+		return fmt.Sprintf("%.2fKB", float64(size)/(1<<10))
+	case size < 1<<30:
+		return fmt.Sprintf("%.2fMB", float64(size)/(1<<20))
 	}
-	return false
+	return fmt.Sprintf("%.2fGB", float64(size)/(1<<30))
 }
@@ -102,14 +102,7 @@ func IsAValidFileName(s string) (bool, string) {
 	return true, ""
 }
 
+// IsImageFile reports whether fileName is an image vision models accept as is.
 func IsImageFile(fileName string) bool {
-	// Check if the file name has an image extension
-	imageExtensions := []string{".png", ".jpg", ".jpeg", ".webp"}
-	for _, ext := range imageExtensions {
-		if strings.HasSuffix(strings.ToLower(fileName), ext) {
-			return true
-		}
-	}
-
-	return false
+	return FormatForExtension(fileName).Vision
 }
@@ -0,0 +1,794 @@
+package files
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxMovieBoxSize caps the moov box read from MP4 and QuickTime files.
+	maxMovieBoxSize = 64 << 20
+	// maxMatroskaHeader is how much of a Matroska file is read to find its
+	// info and tracks, which come before the first cluster.
+	maxMatroskaHeader = 4 << 20
+	// maxRIFFListSize caps the AVI header and INFO lists read.
+	maxRIFFListSize = 4 << 20
+)
+
+var errNoVideoHeader = errors.New("unrecognized video header")
+
+var (
+	// quickTimeEpoch is where MP4 and QuickTime timestamps count from.
+	quickTimeEpoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	// matroskaEpoch is where the Matroska DateUTC element counts from.
+	matroskaEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// iso6709Pattern reads the latitude and longitude of an ISO 6709 location
+// such as "+37.7749-122.4194+010.000/", as phones store it in videos.
+var iso6709Pattern = regexp.MustCompile(`^([+-]\d+(?:\.\d+)?)([+-]\d+(?:\.\d+)?)`)
+
+// videoDetails is what the container headers say about a video.
+type videoDetails struct {
+	Duration   time.Duration
+	Width      int
+	Height     int
+	Rotation   int // Degrees clockwise, from the MP4 track matrix
+	VideoCodec string
+	AudioCodec string
+	Created    time.Time
+	Make       string
+	Model      string
+	Title      string
+	HasGPS     bool
+	Latitude   float64
+	Longitude  float64
+}
+
+// extractVideo reads the duration, dimensions, codecs, creation time and
+// location from MP4, QuickTime, Matroska, WebM and AVI headers, plus the tags
+// of MP4 files.
+func extractVideo(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error) {
+	if !fileType.Binary {
+		// Named like a video but holding text.
+		return extractGeneric(path, fileType, opts)
+	}
+	if !opts.ExtractMetadata {
+		return binaryContent(fileType), nil
+	}
+
+	details, err := readVideoDetails(path, fileType.MIME)
+	lines, metadata := details.describe()
+	if isMovieMIME(fileType.MIME) {
+		// Tags without fields only name the container again.
+		if tags, fields, tagErr := readTags(path); tagErr == nil && len(fields) > 0 {
+			lines = append(lines, tags...)
+			for key, value := range fields {
+				if _, ok := metadata[key]; !ok {
+					metadata[key] = value
+				}
+			}
+		}
+	}
+	if len(lines) == 0 {
+		content := binaryContent(fileType)
+		if err != nil {
+			content.Text += fmt.Sprintf("\nThe video headers could not be read: %v", err)
+		}
+		return content, nil
+	}
+
+	return ExtractedContent{
+		Text:     "Video: " + fileType.Description + "\n" + strings.Join(lines, "\n"),
+		Metadata: metadata,
+	}, nil
+}
+
+func isMovieMIME(mime string) bool {
+	return mime == "video/mp4" || mime == "video/quicktime" || mime == "video/3gpp"
+}
+
+// readVideoDetails reads the headers of an MP4, QuickTime, 3GPP, Matroska,
+// WebM or AVI file.
+func readVideoDetails(path, mime string) (videoDetails, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return videoDetails{}, err
+	}
+	defer file.Close()
+
+	switch {
+	case isMovieMIME(mime):
+		return readMovieDetails(file)
+	case mime == "video/x-matroska" || mime == "video/webm":
+		return readMatroskaDetails(file)
+	case mime == "video/x-msvideo":
+		return readAVIDetails(file)
+	}
+	return videoDetails{}, errNoVideoHeader
+}
+
+// describe lists the details as context lines and metadata fields.
+func (d videoDetails) describe() ([]string, map[string]string) {
+	var lines []string
+	metadata := make(map[string]string)
+
+	if d.Duration > 0 {
+		lines = append(lines, "Duration: "+formatDuration(d.Duration))
+	}
+	width, height := d.Width, d.Height
+	if d.Rotation == 90 || d.Rotation == 270 {
+		// Phones record portrait videos sideways and rotate them on playback.
+		width, height = height, width
+	}
+	if width > 0 && height > 0 {
+		lines = append(lines, fmt.Sprintf("Dimensions: %dx%d", width, height))
+		metadata["width"] = strconv.Itoa(width)
+		metadata["height"] = strconv.Itoa(height)
+	}
+	if d.Rotation != 0 {
+		lines = append(lines, fmt.Sprintf("Rotation: %d°", d.Rotation))
+	}
+	if d.VideoCodec != "" {
+		lines = append(lines, "Video codec: "+codecName(d.VideoCodec))
+	}
+	if d.AudioCodec != "" {
+		lines = append(lines, "Audio codec: "+codecName(d.AudioCodec))
+	}
+	if d.Title != "" {
+		lines = append(lines, "Title: "+d.Title)
+		metadata["title"] = d.Title
+	}
+	if !d.Created.IsZero() {
+		lines = append(lines, "Created: "+d.Created.Format("2006-01-02 15:04:05"))
+		metadata["created"] = d.Created.Format("2006-01-02")
+		metadata["year"] = d.Created.Format("2006")
+	}
+
+	camera := d.Model
+	if d.Make != "" && !strings.HasPrefix(strings.ToLower(d.Model), strings.ToLower(d.Make)) {
+		camera = strings.TrimSpace(d.Make + " " + d.Model)
+	}
+	if camera != "" {
+		lines = append(lines, "Camera: "+camera)
+		metadata["camera"] = camera
+	}
+	if d.Make != "" {
+		metadata["camera_make"] = d.Make
+	}
+	if d.Model != "" {
+		metadata["camera_model"] = d.Model
+	}
+
+	if d.HasGPS {
+		lines = append(lines, fmt.Sprintf("GPS: %.6f, %.6f", d.Latitude, d.Longitude))
+		metadata["gps_latitude"] = strconv.FormatFloat(d.Latitude, 'f', 6, 64)
+		metadata["gps_longitude"] = strconv.FormatFloat(d.Longitude, 'f', 6, 64)
+	}
+	return lines, metadata
+}
+
+// formatDuration writes a duration as h:mm:ss, or m:ss under an hour.
+func formatDuration(duration time.Duration) string {
+	seconds := int64(duration.Round(time.Second) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// codecNames names codecs by MP4 sample entry, AVI FourCC or Matroska codec
+// ID, all lower-cased.
+var codecNames = map[string]string{
+	"avc1": "H.264", "avc3": "H.264", "h264": "H.264", "x264": "H.264", "v_mpeg4/iso/avc": "H.264",
+	"hvc1": "H.265", "hev1": "H.265", "v_mpegh/iso/hevc": "H.265",
+	"av01": "AV1", "v_av1": "AV1",
+	"vp08": "VP8", "v_vp8": "VP8", "vp09": "VP9", "v_vp9": "VP9",
+	"mp4v": "MPEG-4 Part 2", "xvid": "MPEG-4 Part 2", "divx": "MPEG-4 Part 2", "dx50": "MPEG-4 Part 2", "v_mpeg4/iso/asp": "MPEG-4 Part 2",
+	"mjpg": "Motion JPEG", "jpeg": "Motion JPEG", "v_mjpeg": "Motion JPEG",
+	"apcn": "Apple ProRes", "apch": "Apple ProRes", "apcs": "Apple ProRes", "apco": "Apple ProRes", "ap4h": "Apple ProRes", "ap4x": "Apple ProRes",
+	"mp4a": "AAC", "a_aac": "AAC",
+	"ac-3": "AC-3", "a_ac3": "AC-3", "ec-3": "E-AC-3", "a_eac3": "E-AC-3",
+	"alac": "ALAC", "opus": "Opus", "a_opus": "Opus", "a_vorbis": "Vorbis", "flac": "FLAC", "a_flac": "FLAC",
+	".mp3": "MP3", "a_mpeg/l3": "MP3",
+	"lpcm": "PCM", "sowt": "PCM", "twos": "PCM", "a_pcm": "PCM",
+}
+
+// codecName adds the common name of a codec to its code when it is known.
+func codecName(code string) string {
+	code = strings.TrimSpace(strings.TrimRight(code, "\x00"))
+	lower := strings.ToLower(code)
+	name, ok := codecNames[lower]
+	if !ok {
+		// Matroska IDs add a profile, as in A_AAC/MPEG4/LC.
+		prefix, _, _ := strings.Cut(lower, "/")
+		name, ok = codecNames[prefix]
+	}
+	if !ok || name == code {
+		return code
+	}
+	return name + " (" + code + ")"
+}
+
+// setLocation reads an ISO 6709 position, skipping empty 0,0 positions.
+func (d *videoDetails) setLocation(value string) {
+	match := iso6709Pattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return
+	}
+	latitude, latErr := strconv.ParseFloat(match[1], 64)
+	longitude, lonErr := strconv.ParseFloat(match[2], 64)
+	if latErr != nil || lonErr != nil || math.Abs(latitude) > 90 || math.Abs(longitude) > 180 || latitude == 0 && longitude == 0 {
+		return
+	}
+	d.HasGPS, d.Latitude, d.Longitude = true, latitude, longitude
+}
+
+// parseVideoDate reads the dates cameras write in metadata, such as Apple's
+// "2024-03-01T10:15:00+0100".
+func parseVideoDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(strings.TrimRight(value, "\x00"))
+	if parsed, err := time.Parse("2006-01-02T15:04:05-0700", value); err == nil {
+		return parsed, true
+	}
+	return parseLooseDate(value)
+}
+
+// readMovieDetails finds the moov box of an MP4 or QuickTime file, which may
+// sit after the media data, and reads the movie and track headers in it.
+func readMovieDetails(file *os.File) (videoDetails, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return videoDetails{}, err
+	}
+
+	header := make([]byte, 16)
+	for offset := int64(0); offset+8 <= info.Size(); {
+		if _, err := file.ReadAt(header[:8], offset); err != nil {
+			return videoDetails{}, err
+		}
+		size, headerSize := int64(binary.BigEndian.Uint32(header)), int64(8)
+		switch size {
+		case 0:
+			size = info.Size() - offset
+		case 1:
+			if _, err := file.ReadAt(header[8:16], offset+8); err != nil {
+				return videoDetails{}, err
+			}
+			size, headerSize = int64(binary.BigEndian.Uint64(header[8:16])), 16
+		}
+		if size < headerSize {
+			break
+		}
+
+		if string(header[4:8]) == "moov" {
+			if size-headerSize > maxMovieBoxSize {
+				return videoDetails{}, fmt.Errorf("movie header of %s is too large", FormatSize(size))
+			}
+			moov := make([]byte, size-headerSize)
+			if _, err := file.ReadAt(moov, offset+headerSize); err != nil {
+				return videoDetails{}, err
+			}
+			var details videoDetails
+			details.readMovie(moov)
+			return details, nil
+		}
+		offset += size
+	}
+	return videoDetails{}, errors.New("no movie header found")
+}
+
+// eachBox calls visit with the type and body of each ISO-BMFF box in data.
+func eachBox(data []byte, visit func(kind string, body []byte)) {
+	for len(data) >= 8 {
+		size, start := uint64(binary.BigEndian.Uint32(data)), uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return
+			}
+			size, start = binary.BigEndian.Uint64(data[8:16]), 16
+		}
+		if size < start || size > uint64(len(data)) {
+			return
+		}
+		visit(string(data[4:8]), data[start:size])
+		data = data[size:]
+	}
+}
+
+func (d *videoDetails) readMovie(moov []byte) {
+	eachBox(moov, func(kind string, body []byte) {
+		switch kind {
+		case "mvhd":
+			d.readMovieHeader(body)
+		case "trak":
+			d.readTrack(body)
+		case "udta":
+			d.readUserData(body)
+		case "meta":
+			d.readMetadataItems(body)
+		}
+	})
+}
+
+// readMovieHeader reads the duration and creation time of the movie.
+func (d *videoDetails) readMovieHeader(body []byte) {
+	var created, timescale, duration uint64
+	switch {
+	case len(body) >= 32 && body[0] == 1:
+		created = binary.BigEndian.Uint64(body[4:])
+		timescale = uint64(binary.BigEndian.Uint32(body[20:]))
+		duration = binary.BigEndian.Uint64(body[24:])
+	case len(body) >= 20 && body[0] == 0:
+		created = uint64(binary.BigEndian.Uint32(body[4:]))
+		timescale = uint64(binary.BigEndian.Uint32(body[12:]))
+		duration = uint64(binary.BigEndian.Uint32(body[16:]))
+	default:
+		return
+	}
+
+	// Fragmented files leave the duration unknown, as all ones.
+	if timescale > 0 && duration != math.MaxUint32 && duration != math.MaxUint64 {
+		d.Duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+	}
+	// Encoders without a clock write zero, which is 1904.
+	if created > 0 && created < 1<<33 && d.Created.IsZero() {
+		if taken := quickTimeEpoch.Add(time.Duration(created) * time.Second); taken.Year() > 1970 {
+			d.Created = taken
+		}
+	}
+}
+
+// readTrack reads the codec of the first video and audio tracks, and the
+// display size and rotation of the video.
+func (d *videoDetails) readTrack(trak []byte) {
+	var handler, codec string
+	var width, height, rotation, entryWidth, entryHeight int
+	var walk func(data []byte)
+	walk = func(data []byte) {
+		eachBox(data, func(kind string, body []byte) {
+			switch kind {
+			case "mdia", "minf", "stbl":
+				walk(body)
+			case "tkhd":
+				width, height, rotation = readTrackHeader(body)
+			case "hdlr":
+				// QuickTime also has a data handler in minf; the media handler in mdia comes first.
+				if handler == "" && len(body) >= 12 {
+					handler = string(body[8:12])
+				}
+			case "stsd":
+				// Version and flags and the entry count come before the first sample entry.
+				if len(body) >= 16 {
+					codec = string(body[12:16])
+				}
+				if len(body) >= 44 {
+					entryWidth = int(binary.BigEndian.Uint16(body[40:]))
+					entryHeight = int(binary.BigEndian.Uint16(body[42:]))
+				}
+			}
+		})
+	}
+	walk(trak)
+
+	switch handler {
+	case "vide":
+		if d.VideoCodec != "" {
+			return
+		}
+		d.VideoCodec = codec
+		if width == 0 || height == 0 {
+			width, height = entryWidth, entryHeight
+		}
+		d.Width, d.Height, d.Rotation = width, height, rotation
+	case "soun":
+		if d.AudioCodec == "" {
+			d.AudioCodec = codec
+		}
+	}
+}
+
+// readTrackHeader reads the display size of a track and its rotation from
+// the transformation matrix.
+func readTrackHeader(body []byte) (width, height, rotation int) {
+	// Version and flags, then times, track ID and duration.
+	matrix := 4 + 20
+	if len(body) > 0 && body[0] == 1 {
+		matrix = 4 + 32
+	}
+	// Reserved, layer, alternate group and volume come before the matrix.
+	matrix += 16
+	if len(body) < matrix+44 {
+		return 0, 0, 0
+	}
+
+	a := int32(binary.BigEndian.Uint32(body[matrix:]))
+	b := int32(binary.BigEndian.Uint32(body[matrix+4:]))
+	switch {
+	case a == 0 && b > 0:
+		rotation = 90
+	case a == 0 && b < 0:
+		rotation = 270
+	case a < 0 && b == 0:
+		rotation = 180
+	}
+	// The size is a 16.16 fixed-point number.
+	width = int(binary.BigEndian.Uint32(body[matrix+36:]) >> 16)
+	height = int(binary.BigEndian.Uint32(body[matrix+40:]) >> 16)
+	return width, height, rotation
+}
+
+// readUserData reads the QuickTime user data texts that cameras and Android
+// phones write, such as ©xyz for the location.
+func (d *videoDetails) readUserData(udta []byte) {
+	eachBox(udta, func(kind string, body []byte) {
+		switch kind {
+		case "\xa9xyz":
+			d.setLocation(userDataText(body))
+		case "\xa9day":
+			if created, ok := parseVideoDate(userDataText(body)); ok {
+				d.Created = created
+			}
+		case "\xa9mak":
+			d.Make = userDataText(body)
+		case "\xa9mod":
+			d.Model = userDataText(body)
+		case "\xa9nam":
+			d.Title = userDataText(body)
+		case "meta":
+			d.readMetadataItems(body)
+		}
+	})
+}
+
+// userDataText reads a user data text: a 16-bit length and a language code,
+// then the text.
+func userDataText(body []byte) string {
+	if len(body) < 4 {
+		return ""
+	}
+	length := min(int(binary.BigEndian.Uint16(body)), len(body)-4)
+	return strings.TrimSpace(strings.ToValidUTF8(string(body[4:4+length]), ""))
+}
+
+// readMetadataItems reads QuickTime metadata as iPhones write it: a keys box
+// naming each item and an ilst box holding the values by key index.
+func (d *videoDetails) readMetadataItems(meta []byte) {
+	// ISO meta boxes start with version and flags; QuickTime ones do not.
+	if len(meta) >= 8 && string(meta[4:8]) != "hdlr" {
+		meta = meta[4:]
+	}
+
+	var keys []string
+	values := make(map[uint32]string)
+	eachBox(meta, func(kind string, body []byte) {
+		switch kind {
+		case "keys":
+			// Version and flags and the key count come before the keys, each
+			// laid out like a box with the namespace as its type.
+			if len(body) >= 8 {
+				eachBox(body[8:], func(_ string, key []byte) {
+					keys = append(keys, string(key))
+				})
+			}
+		case "ilst":
+			eachBox(body, func(index string, item []byte) {
+				eachBox(item, func(kind string, data []byte) {
+					// The value type and locale come before the value.
+					if kind == "data" && len(data) >= 8 {
+						values[binary.BigEndian.Uint32([]byte(index))] = strings.ToValidUTF8(string(data[8:]), "")
+					}
+				})
+			})
+		}
+	})
+
+	for index, value := range values {
+		if index == 0 || int(index) > len(keys) {
+			continue
+		}
+		switch keys[index-1] {
+		case "com.apple.quicktime.location.ISO6709":
+			d.setLocation(value)
+		case "com.apple.quicktime.creationdate":
+			if created, ok := parseVideoDate(value); ok {
+				d.Created = created
+			}
+		case "com.apple.quicktime.make":
+			d.Make = strings.TrimSpace(value)
+		case "com.apple.quicktime.model":
+			d.Model = strings.TrimSpace(value)
+		case "com.apple.quicktime.title":
+			d.Title = strings.TrimSpace(value)
+		}
+	}
+}
+
+// Matroska element IDs, with their length markers.
+const (
+	ebmlHeaderID    = 0x1a45dfa3
+	segmentID       = 0x18538067
+	segmentInfoID   = 0x1549a966
+	timecodeScaleID = 0x2ad7b1
+	durationID      = 0x4489
+	dateUTCID       = 0x4461
+	titleID         = 0x7ba9
+	tracksID        = 0x1654ae6b
+	trackEntryID    = 0xae
+	trackTypeID     = 0x83
+	codecID         = 0x86
+	trackVideoID    = 0xe0
+	pixelWidthID    = 0xb0
+	pixelHeightID   = 0xba
+	clusterID       = 0x1f43b675
+)
+
+// readMatroskaDetails reads the segment info and tracks of a Matroska or
+// WebM file, stopping at the first cluster of media data.
+func readMatroskaDetails(file *os.File) (videoDetails, error) {
+	data, err := io.ReadAll(io.LimitReader(file, maxMatroskaHeader))
+	if err != nil {
+		return videoDetails{}, err
+	}
+	id, _, rest, ok := readElement(data)
+	if !ok || id != ebmlHeaderID {
+		return videoDetails{}, errNoVideoHeader
+	}
+	id, segment, _, ok := readElement(rest)
+	if !ok || id != segmentID {
+		return videoDetails{}, errors.New("no segment found")
+	}
+
+	var details videoDetails
+	scale, duration := uint64(1000000), 0.0
+	eachElement(segment, func(id uint64, body []byte) bool {
+		switch id {
+		case segmentInfoID:
+			eachElement(body, func(id uint64, value []byte) bool {
+				switch id {
+				case timecodeScaleID:
+					scale = readUint(value)
+				case durationID:
+					duration = readFloat(value)
+				case dateUTCID:
+					if len(value) == 8 {
+						details.Created = matroskaEpoch.Add(time.Duration(int64(binary.BigEndian.Uint64(value))))
+					}
+				case titleID:
+					details.Title = strings.TrimSpace(string(value))
+				}
+				return true
+			})
+		case tracksID:
+			eachElement(body, func(id uint64, entry []byte) bool {
+				if id == trackEntryID {
+					details.readMatroskaTrack(entry)
+				}
+				return true
+			})
+		case clusterID:
+			return false
+		}
+		return true
+	})
+	// Durations count in units of the timecode scale, in nanoseconds.
+	details.Duration = time.Duration(duration * float64(scale))
+	return details, nil
+}
+
+func (d *videoDetails) readMatroskaTrack(entry []byte) {
+	var trackType uint64
+	var codec string
+	var width, height int
+	eachElement(entry, func(id uint64, value []byte) bool {
+		switch id {
+		case trackTypeID:
+			trackType = readUint(value)
+		case codecID:
+			codec = string(value)
+		case trackVideoID:
+			eachElement(value, func(id uint64, value []byte) bool {
+				switch id {
+				case pixelWidthID:
+					width = int(readUint(value))
+				case pixelHeightID:
+					height = int(readUint(value))
+				}
+				return true
+			})
+		}
+		return true
+	})
+
+	switch trackType {
+	case 1:
+		if d.VideoCodec == "" {
+			d.VideoCodec, d.Width, d.Height = codec, width, height
+		}
+	case 2:
+		if d.AudioCodec == "" {
+			d.AudioCodec = codec
+		}
+	}
+}
+
+// eachElement calls visit with the ID and body of each EBML element in data
+// until visit returns false.
+func eachElement(data []byte, visit func(id uint64, body []byte) bool) {
+	for len(data) > 0 {
+		id, body, rest, ok := readElement(data)
+		if !ok || !visit(id, body) {
+			return
+		}
+		data = rest
+	}
+}
+
+// readElement reads one EBML element. Elements of unknown size, or cut off
+// by the end of data, run to the end of data.
+func readElement(data []byte) (id uint64, body, rest []byte, ok bool) {
+	id, idLength, ok := readVint(data, true)
+	if !ok {
+		return 0, nil, nil, false
+	}
+	size, sizeLength, ok := readVint(data[idLength:], false)
+	if !ok {
+		return 0, nil, nil, false
+	}
+	start := idLength + sizeLength
+	end := len(data)
+	if size != 1<<(7*sizeLength)-1 && size < uint64(len(data)-start) {
+		end = start + int(size)
+	}
+	return id, data[start:end], data[end:], true
+}
+
+// readVint reads an EBML variable-length integer. IDs keep their length
+// marker; sizes drop it.
+func readVint(data []byte, keepMarker bool) (value uint64, length int, ok bool) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0, false
+	}
+	length = bits.LeadingZeros8(data[0]) + 1
+	if len(data) < length {
+		return 0, 0, false
+	}
+	value = uint64(data[0])
+	if !keepMarker {
+		value &= 0xff >> length
+	}
+	for _, b := range data[1:length] {
+		value = value<<8 | uint64(b)
+	}
+	return value, length, true
+}
+
+func readUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data[:min(len(data), 8)] {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func readFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+	return 0
+}
+
+// readAVIDetails reads the main header, stream formats and INFO list of an
+// AVI file, skipping the movie data between them.
+func readAVIDetails(file *os.File) (videoDetails, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return videoDetails{}, err
+	}
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(file, header); err != nil || string(header[:4]) != "RIFF" || string(header[8:12]) != "AVI " {
+		return videoDetails{}, errNoVideoHeader
+	}
+
+	var details videoDetails
+	for offset := int64(12); offset+12 <= info.Size(); {
+		if _, err := file.ReadAt(header, offset); err != nil {
+			break
+		}
+		size := int64(binary.LittleEndian.Uint32(header[4:8]))
+		if string(header[:4]) == "LIST" && size >= 4 && size <= maxRIFFListSize {
+			switch string(header[8:12]) {
+			case "hdrl", "INFO":
+				list := make([]byte, size-4)
+				if _, err := file.ReadAt(list, offset+12); err != nil {
+					return details, err
+				}
+				details.readAVIList(list)
+			}
+		}
+		offset += 8 + size + size%2
+	}
+	return details, nil
+}
+
+// eachChunk calls visit with the ID and body of each RIFF chunk in data.
+func eachChunk(data []byte, visit func(id string, body []byte)) {
+	for len(data) >= 8 {
+		size := uint64(binary.LittleEndian.Uint32(data[4:8]))
+		if size > uint64(len(data)-8) {
+			return
+		}
+		visit(string(data[:4]), data[8:8+size])
+		data = data[min(8+size+size%2, uint64(len(data))):]
+	}
+}
+
+func (d *videoDetails) readAVIList(list []byte) {
+	var streamType string
+	eachChunk(list, func(id string, body []byte) {
+		switch id {
+		case "LIST":
+			// Stream lists, each with a stream header and format.
+			if len(body) >= 4 {
+				d.readAVIList(body[4:])
+			}
+		case "avih":
+			if len(body) >= 40 {
+				frameTime := time.Duration(binary.LittleEndian.Uint32(body)) * time.Microsecond
+				d.Duration = time.Duration(binary.LittleEndian.Uint32(body[16:])) * frameTime
+				d.Width = int(binary.LittleEndian.Uint32(body[32:]))
+				d.Height = int(binary.LittleEndian.Uint32(body[36:]))
+			}
+		case "strh":
+			if len(body) >= 4 {
+				streamType = string(body[:4])
+			}
+		case "strf":
+			switch {
+			case streamType == "vids" && d.VideoCodec == "" && len(body) >= 20:
+				// The compression FourCC of the BITMAPINFOHEADER.
+				d.VideoCodec = strings.TrimRight(string(body[16:20]), "\x00")
+			case streamType == "auds" && d.AudioCodec == "" && len(body) >= 2:
+				d.AudioCodec = waveFormatName(binary.LittleEndian.Uint16(body))
+			}
+		case "IDIT", "ICRD":
+			if created, ok := parseVideoDate(string(body)); ok {
+				d.Created = created
+			}
+		case "INAM":
+			d.Title = strings.TrimSpace(strings.TrimRight(string(body), "\x00"))
+		}
+	})
+}
+
+// waveFormatName names the audio formats common in AVI files.
+func waveFormatName(tag uint16) string {
+	switch tag {
+	case 0x0001:
+		return "PCM"
+	case 0x0050:
+		return "MPEG audio"
+	case 0x0055:
+		return "MP3"
+	case 0x00ff:
+		return "AAC"
+	case 0x2000:
+		return "AC-3"
+	}
+	return fmt.Sprintf("format 0x%04x", tag)
+}
@@ -0,0 +1,228 @@
+package files
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBox(kind string, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(out, kind...), body...)
+}
+
+// buildMovie lays out a QuickTime file as iPhones write it: the media data
+// first, then a moov box with a rotated HEVC track, an AAC track and the
+// location and creation date as keyed metadata.
+func buildMovie() []byte {
+	be32 := func(value uint32) []byte { return binary.BigEndian.AppendUint32(nil, value) }
+	zeros := func(count int) []byte { return make([]byte, count) }
+
+	created := uint32(time.Date(2024, 3, 1, 9, 15, 0, 0, time.UTC).Sub(quickTimeEpoch) / time.Second)
+	mvhd := testBox("mvhd", zeros(4), be32(created), be32(created), be32(600), be32(600*83), zeros(80))
+
+	// Rotated 90° clockwise: a=0, b=1, c=-1, d=0 in 16.16, then u, v, x, y, w.
+	matrix := bytes.Join([][]byte{be32(0), be32(0x10000), be32(0), be32(0xffff0000), be32(0), be32(0), be32(0), be32(0), be32(0x40000000)}, nil)
+	videoTrack := testBox("trak",
+		testBox("tkhd", zeros(4+20+16), matrix, be32(1920<<16), be32(1080<<16)),
+		testBox("mdia",
+			testBox("hdlr", zeros(8), []byte("vide"), zeros(12)),
+			testBox("minf", testBox("stbl", testBox("stsd", zeros(4), be32(1), testBox("hvc1", zeros(78)))))))
+	audioTrack := testBox("trak",
+		testBox("tkhd", zeros(4+20+16+36+8)),
+		testBox("mdia",
+			testBox("hdlr", zeros(8), []byte("soun"), zeros(12)),
+			testBox("minf", testBox("stbl", testBox("stsd", zeros(4), be32(1), testBox("mp4a", zeros(28)))))))
+
+	keys := []string{"com.apple.quicktime.location.ISO6709", "com.apple.quicktime.make", "com.apple.quicktime.model", "com.apple.quicktime.creationdate"}
+	values := []string{"+37.7749-122.4194+010.000/", "Apple", "iPhone 15 Pro", "2024-03-01T10:15:00+0100"}
+	keyBoxes := [][]byte{zeros(4), be32(uint32(len(keys)))}
+	var items [][]byte
+	for index, key := range keys {
+		keyBoxes = append(keyBoxes, testBox("mdta", []byte(key)))
+		items = append(items, testBox(string(be32(uint32(index+1))), testBox("data", be32(1), zeros(4), []byte(values[index]))))
+	}
+	meta := testBox("meta",
+		testBox("hdlr", zeros(8), []byte("mdta"), zeros(12)),
+		testBox("keys", keyBoxes...),
+		testBox("ilst", items...))
+
+	return bytes.Join([][]byte{
+		testBox("ftyp", []byte("qt  "), zeros(4), []byte("qt  ")),
+		testBox("wide"),
+		testBox("mdat", bytes.Repeat([]byte{0x42}, 4096)),
+		testBox("moov", mvhd, videoTrack, audioTrack, meta),
+	}, nil)
+}
+
+func testElement(id []byte, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	size := binary.BigEndian.AppendUint64(nil, uint64(len(body)))
+	size[0] = 0x01 // An 8-byte size
+	return append(append(append([]byte{}, id...), size...), body...)
+}
+
+func buildMatroska() []byte {
+	be16 := func(value uint16) []byte { return binary.BigEndian.AppendUint16(nil, value) }
+	be64 := func(value uint64) []byte { return binary.BigEndian.AppendUint64(nil, value) }
+	recorded := time.Date(2023, 7, 14, 18, 30, 0, 0, time.UTC).Sub(matroskaEpoch)
+
+	header := testElement([]byte{0x1a, 0x45, 0xdf, 0xa3}, testElement([]byte{0x42, 0x82}, []byte("webm")))
+	info := testElement([]byte{0x15, 0x49, 0xa9, 0x66},
+		testElement([]byte{0x2a, 0xd7, 0xb1}, []byte{0x0f, 0x42, 0x40}),
+		testElement([]byte{0x44, 0x89}, be64(math.Float64bits(5000))),
+		testElement([]byte{0x44, 0x61}, be64(uint64(recorded))),
+		testElement([]byte{0x7b, 0xa9}, []byte("Beach day")))
+	tracks := testElement([]byte{0x16, 0x54, 0xae, 0x6b},
+		testElement([]byte{0xae},
+			testElement([]byte{0x83}, []byte{1}),
+			testElement([]byte{0x86}, []byte("V_VP9")),
+			testElement([]byte{0xe0}, testElement([]byte{0xb0}, be16(640)), testElement([]byte{0xba}, be16(360)))),
+		testElement([]byte{0xae},
+			testElement([]byte{0x83}, []byte{2}),
+			testElement([]byte{0x86}, []byte("A_OPUS"))))
+	cluster := testElement([]byte{0x1f, 0x43, 0xb6, 0x75}, bytes.Repeat([]byte{0xae}, 512))
+
+	// Live recordings leave the segment size unknown.
+	segment := append([]byte{0x18, 0x53, 0x80, 0x67, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, bytes.Join([][]byte{info, tracks, cluster}, nil)...)
+	return append(header, segment...)
+}
+
+func testChunk(id string, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	out := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	out = append(out, body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func buildAVI() []byte {
+	le16 := func(value uint16) []byte { return binary.LittleEndian.AppendUint16(nil, value) }
+	le32 := func(value uint32) []byte { return binary.LittleEndian.AppendUint32(nil, value) }
+	zeros := func(count int) []byte { return make([]byte, count) }
+
+	avih := testChunk("avih", le32(40000), zeros(12), le32(250), zeros(12), le32(720), le32(576), zeros(16))
+	videoStream := testChunk("LIST", []byte("strl"),
+		testChunk("strh", []byte("vidsXVID"), zeros(48)),
+		testChunk("strf", le32(40), le32(720), le32(576), le16(1), le16(24), []byte("XVID"), zeros(20)))
+	audioStream := testChunk("LIST", []byte("strl"),
+		testChunk("strh", []byte("auds"), zeros(52)),
+		testChunk("strf", le16(0x0055), zeros(16)))
+	body := bytes.Join([][]byte{
+		[]byte("AVI "),
+		testChunk("LIST", []byte("hdrl"), avih, videoStream, audioStream),
+		testChunk("LIST", []byte("movi"), testChunk("00dc", bytes.Repeat([]byte{0x42}, 2048))),
+		testChunk("LIST", []byte("INFO"), testChunk("ICRD", []byte("2009-08-15\x00")), testChunk("INAM", []byte("Birthday\x00"))),
+	}, nil)
+	return testChunk("RIFF", body)
+}
+
+func TestExtractFileContentVideo(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		data     []byte
+		want     []string
+		metadata map[string]string
+	}{
+		{
+			name: "QuickTime from a phone",
+			file: "IMG_0042.MOV",
+			data: buildMovie(),
+			want: []string{
+				"Video: QuickTime video",
+				"Duration: 1:23",
+				"Dimensions: 1080x1920",
+				"Rotation: 90°",
+				"Video codec: H.265 (hvc1)",
+				"Audio codec: AAC (mp4a)",
+				"Created: 2024-03-01 10:15:00",
+				"Camera: Apple iPhone 15 Pro",
+				"GPS: 37.774900, -122.419400",
+			},
+			metadata: map[string]string{"created": "2024-03-01", "year": "2024", "width": "1080", "height": "1920", "gps_longitude": "-122.419400"},
+		},
+		{
+			name: "WebM with an unknown segment size",
+			file: "recording.webm",
+			data: buildMatroska(),
+			want: []string{
+				"Video: WebM video",
+				"Duration: 0:05",
+				"Dimensions: 640x360",
+				"Video codec: VP9 (V_VP9)",
+				"Audio codec: Opus (A_OPUS)",
+				"Title: Beach day",
+				"Created: 2023-07-14 18:30:00",
+			},
+			metadata: map[string]string{"title": "Beach day", "created": "2023-07-14"},
+		},
+		{
+			name: "AVI with an INFO list after the movie data",
+			file: "MOV00012.avi",
+			data: buildAVI(),
+			want: []string{
+				"Video: AVI video",
+				"Duration: 0:10",
+				"Dimensions: 720x576",
+				"Video codec: MPEG-4 Part 2 (XVID)",
+				"Audio codec: MP3",
+				"Title: Birthday",
+				"Created: 2009-08-15 00:00:00",
+			},
+			metadata: map[string]string{"title": "Birthday", "year": "2009", "width": "720"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			content, err := ExtractFileContent(path, DefaultExtractOptions())
+			if err != nil {
+				t.Fatalf("ExtractFileContent() error = %v", err)
+			}
+			if content.Category != CategoryVideos || content.Sparse {
+				t.Fatalf("Category = %q, Sparse = %v, want Videos and not sparse", content.Category, content.Sparse)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(content.Text, want+"\n") && !strings.HasSuffix(content.Text, want) {
+					t.Errorf("Text = %q, want line %q", content.Text, want)
+				}
+			}
+			for key, want := range tt.metadata {
+				if got := content.Metadata[key]; got != want {
+					t.Errorf("Metadata[%q] = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractFileContentUnreadableVideo(t *testing.T) {
+	// A moov box claiming more bytes than the file has.
+	data := append(testBox("ftyp", []byte("isom"), make([]byte, 4)), 0x00, 0x10, 0x00, 0x00, 'm', 'o', 'o', 'v')
+	path := filepath.Join(t.TempDir(), "broken.mp4")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	content, err := ExtractFileContent(path, DefaultExtractOptions())
+	if err != nil {
+		t.Fatalf("ExtractFileContent() error = %v", err)
+	}
+	if !content.Sparse || !strings.Contains(content.Text, "Detected type: MP4 video") || !strings.Contains(content.Text, "The video headers could not be read") {
+		t.Fatalf("Text = %q, want the detected type and why the headers were not read", content.Text)
+	}
+}
@@ -0,0 +1,280 @@
+package files
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const (
+	// maxXMLTextNodes caps the text nodes listed for generic XML.
+	maxXMLTextNodes = 40
+	// maxXMLEntries caps the feed entry titles and SVG texts listed.
+	maxXMLEntries = 15
+	// maxXMLStoredTexts bounds the text nodes kept while parsing large files.
+	maxXMLStoredTexts = 2000
+)
+
+// xmlDocument is what a single pass over an XML file collected.
+type xmlDocument struct {
+	root       xml.Name
+	namespaces []string
+	attrs      map[string]string // Attributes of the root element
+	elements   int
+	counts     map[string]int    // Elements by slash-separated path of local names
+	firstText  map[string]string // First text of each path
+	texts      []xmlText         // Text nodes in document order
+	malformed  error
+}
+
+type xmlText struct {
+	path string
+	text string
+}
+
+func extractXML(path string, fileType FileType, opts ExtractOptions) (ExtractedContent, error) {
+	data, err := readPrefix(path, maxMarkupSize)
+	if err != nil {
+		return ExtractedContent{}, err
+	}
+
+	doc := parseXML(data)
+	if doc.root.Local == "" {
+		// Not XML after all; send it like any other text.
+		return extractGeneric(path, fileType, opts)
+	}
+
+	var lines []string
+	metadata := make(map[string]string)
+	switch kind := doc.kind(); kind {
+	case "RSS feed", "Atom feed":
+		lines = doc.describeFeed(kind, metadata)
+	case "SVG image":
+		lines = doc.describeSVG(metadata)
+	default:
+		lines = doc.describeGeneric(opts.ExtractText)
+	}
+	if doc.malformed != nil {
+		lines = append(lines, fmt.Sprintf("Note: the XML is malformed after %d elements (%v).", doc.elements, doc.malformed))
+	}
+
+	if !opts.ExtractMetadata || len(metadata) == 0 {
+		metadata = nil
+	}
+	return ExtractedContent{
+		Text:     strings.Join(lines, "\n"),
+		Metadata: metadata,
+		Sparse:   len(doc.texts) == 0,
+	}, nil
+}
+
+// parseXML reads the element tree without building it, recording element
+// counts and text nodes by path. Parsing stops at the first syntax error,
+// keeping what was read so far.
+func parseXML(data []byte) xmlDocument {
+	doc := xmlDocument{counts: make(map[string]int), firstText: make(map[string]string), attrs: make(map[string]string)}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.CharsetReader = charsetReader
+
+	var stack []string
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				doc.malformed = err
+			}
+			return doc
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if doc.root.Local == "" {
+				doc.root = token.Name
+				for _, attr := range token.Attr {
+					switch {
+					case attr.Name.Space == "xmlns":
+						doc.namespaces = append(doc.namespaces, attr.Name.Local+"="+attr.Value)
+					case attr.Name.Local == "xmlns" && attr.Name.Space == "":
+					default:
+						doc.attrs[attr.Name.Local] = attr.Value
+					}
+				}
+			}
+			stack = append(stack, token.Name.Local)
+			doc.elements++
+			doc.counts[strings.Join(stack, "/")]++
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			text := collapseSpace(string(token))
+			if text == "" || len(stack) == 0 {
+				continue
+			}
+			path := strings.Join(stack, "/")
+			if _, seen := doc.firstText[path]; !seen {
+				doc.firstText[path] = text
+			}
+			if len(doc.texts) < maxXMLStoredTexts {
+				doc.texts = append(doc.texts, xmlText{path: path, text: text})
+			}
+		}
+	}
+}
+
+func (d xmlDocument) kind() string {
+	switch {
+	case d.root.Local == "rss" || (d.root.Local == "RDF" && d.counts["RDF/channel"] > 0):
+		return "RSS feed"
+	case d.root.Local == "feed" && d.root.Space == "http://www.w3.org/2005/Atom":
+		return "Atom feed"
+	case d.root.Local == "svg":
+		return "SVG image"
+	}
+	return ""
+}
+
+func (d xmlDocument) header(kind string) []string {
+	lines := []string{"XML document"}
+	if kind != "" {
+		lines[0] += ": " + kind
+	}
+	root := "Root: " + d.root.Local
+	if d.root.Space != "" {
+		root += " (" + d.root.Space + ")"
+	}
+	lines = append(lines, root)
+	if len(d.namespaces) > 0 {
+		lines = append(lines, "Namespaces: "+strings.Join(d.namespaces, ", "))
+	}
+	return lines
+}
+
+// textsUnder lists the texts of the paths accepted by match, at most limit.
+func (d xmlDocument) textsUnder(limit int, match func(path string) bool) []string {
+	var texts []string
+	for _, node := range d.texts {
+		if len(texts) == limit {
+			break
+		}
+		if text := clip(node.text, 200); match(node.path) && !slices.Contains(texts, text) {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+func (d xmlDocument) describeFeed(kind string, metadata map[string]string) []string {
+	// RSS 2.0 nests items in the channel, RSS 1.0 lists them beside it, Atom has entries.
+	channel, entry, entryLabel := "rss/channel", "rss/channel/item", "Items"
+	switch {
+	case d.root.Local == "RDF":
+		channel, entry = "RDF/channel", "RDF/item"
+	case kind == "Atom feed":
+		channel, entry, entryLabel = "feed", "feed/entry", "Entries"
+	}
+
+	lines := d.header(kind)
+	title := d.firstText[channel+"/title"]
+	if title != "" {
+		lines = append(lines, "Title: "+title)
+		metadata["title"] = title
+	}
+	if description := cmp.Or(d.firstText[channel+"/description"], d.firstText[channel+"/subtitle"]); description != "" {
+		lines = append(lines, "Description: "+clip(description, 300))
+		metadata["description"] = description
+	}
+	if author := cmp.Or(d.firstText[channel+"/author/name"], d.firstText[channel+"/managingEditor"], d.firstText[channel+"/creator"]); author != "" {
+		lines = append(lines, "Author: "+author)
+		metadata["author"] = author
+	}
+	if updated := cmp.Or(d.firstText[channel+"/updated"], d.firstText[channel+"/lastBuildDate"], d.firstText[channel+"/pubDate"], d.firstText[channel+"/date"]); updated != "" {
+		if parsed, ok := parseLooseDate(updated); ok {
+			updated = parsed.Format("2006-01-02")
+			metadata["modified"] = updated
+			metadata["year"] = parsed.Format("2006")
+		}
+		lines = append(lines, "Updated: "+updated)
+	}
+
+	lines = append(lines, fmt.Sprintf("%s: %d", entryLabel, d.counts[entry]))
+	for _, title := range d.textsUnder(maxXMLEntries, func(path string) bool { return path == entry+"/title" }) {
+		lines = append(lines, "- "+title)
+	}
+	return lines
+}
+
+func (d xmlDocument) describeSVG(metadata map[string]string) []string {
+	lines := d.header("SVG image")
+	isTitle := func(path string) bool { return strings.HasSuffix(path, "/title") }
+	if titles := d.textsUnder(1, isTitle); len(titles) > 0 {
+		lines = append(lines, "Title: "+titles[0])
+		metadata["title"] = titles[0]
+	}
+	if descriptions := d.textsUnder(1, func(path string) bool { return strings.HasSuffix(path, "/desc") }); len(descriptions) > 0 {
+		lines = append(lines, "Description: "+descriptions[0])
+		metadata["description"] = descriptions[0]
+	}
+
+	var size []string
+	for _, attr := range []string{"width", "height", "viewBox"} {
+		if value := d.attrs[attr]; value != "" {
+			size = append(size, attr+"="+value)
+		}
+	}
+	if len(size) > 0 {
+		lines = append(lines, "Size: "+strings.Join(size, " "))
+	}
+
+	texts := d.textsUnder(maxXMLEntries, func(path string) bool {
+		return strings.HasSuffix(path, "/text") || strings.HasSuffix(path, "/tspan") || strings.HasSuffix(path, "/textPath")
+	})
+	if len(texts) > 0 {
+		lines = append(lines, "Text: "+strings.Join(texts, " | "))
+	}
+	return lines
+}
+
+func (d xmlDocument) describeGeneric(withText bool) []string {
+	lines := d.header("")
+	lines = append(lines, fmt.Sprintf("Elements: %d", d.elements))
+
+	// Children of the root show what the document is a collection of.
+	prefix := d.root.Local + "/"
+	type child struct {
+		name  string
+		count int
+	}
+	var children []child
+	for path, count := range d.counts {
+		if name, ok := strings.CutPrefix(path, prefix); ok && !strings.Contains(name, "/") {
+			children = append(children, child{name: name, count: count})
+		}
+	}
+	slices.SortFunc(children, func(a, b child) int {
+		return cmp.Or(b.count-a.count, strings.Compare(a.name, b.name))
+	})
+	if len(children) > 0 {
+		parts := make([]string, 0, min(len(children), 15))
+		for _, child := range children[:min(len(children), 15)] {
+			parts = append(parts, fmt.Sprintf("%s (%d)", child.name, child.count))
+		}
+		lines = append(lines, "Top-level elements: "+strings.Join(parts, ", "))
+	}
+
+	if withText && len(d.texts) > 0 {
+		lines = append(lines, "Text:")
+		for _, node := range d.texts[:min(len(d.texts), maxXMLTextNodes)] {
+			lines = append(lines, node.path+": "+clip(node.text, 200))
+		}
+	}
+	return lines
+}
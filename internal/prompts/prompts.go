@@ -0,0 +1,237 @@
+// Package prompts resolves named prompts from the built-ins shipped in the
+// binary and the user's prompt directory next to the config file.
+package prompts
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	utils "nomnom/internal/utils"
+)
+
+// Default is used when neither the command line nor the config sets a prompt.
+const Default = "You are a desktop organizer that creates nice names for the files with their context. Please follow snake case naming convention. Only respond with the new name and the file extension. Do not change the file extension."
+
+const extension = ".txt"
+
+//go:embed builtin/*.txt
+var builtinFS embed.FS
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Source says where a prompt was loaded from.
+type Source string
+
+const (
+	SourceBuiltin Source = "built-in"
+	SourceUser    Source = "user"
+)
+
+type Prompt struct {
+	Name   string
+	Text   string
+	Source Source
+	Path   string // File backing a user prompt
+	// Overrides is set when a user prompt replaces a built-in of the same name.
+	Overrides bool
+}
+
+// Library looks prompts up in a user directory first and the built-ins second.
+type Library struct {
+	dir string
+}
+
+// NewLibrary returns a library backed by dir.
+func NewLibrary(dir string) Library {
+	return Library{dir: dir}
+}
+
+// ForConfig returns the library whose user prompts live in a "prompts"
+// directory next to the config file at configPath.
+func ForConfig(configPath string) (Library, error) {
+	resolved, err := utils.ResolveConfigPath(configPath)
+	if err != nil {
+		return Library{}, err
+	}
+	return NewLibrary(filepath.Join(filepath.Dir(resolved), "prompts")), nil
+}
+
+// Dir returns the user prompt directory.
+func (l Library) Dir() string {
+	return l.dir
+}
+
+// ValidateName reports whether name can be used for a prompt file.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid prompt name %q: use lowercase letters, digits, hyphens and underscores", name)
+	}
+	return nil
+}
+
+// Resolve turns the --prompt value into prompt text. An empty value falls back
+// to the config prompt, a known name loads that prompt, and anything else is
+// used as prompt text as is.
+func (l Library) Resolve(prompt string, config utils.Config) (string, error) {
+	trimmed := strings.TrimSpace(prompt)
+	if trimmed == "" {
+		if strings.TrimSpace(config.AI.Prompt) != "" {
+			return config.AI.Prompt, nil
+		}
+		return Default, nil
+	}
+
+	name := strings.ToLower(trimmed)
+	if ValidateName(name) != nil {
+		return trimmed, nil
+	}
+
+	found, ok, err := l.Lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return trimmed, nil
+	}
+	return found.Text, nil
+}
+
+// Lookup finds a prompt by name, preferring the user's copy.
+func (l Library) Lookup(name string) (Prompt, bool, error) {
+	if ValidateName(name) != nil {
+		return Prompt{}, false, nil
+	}
+
+	builtin, hasBuiltin := readBuiltin(name)
+	if l.dir != "" {
+		path := l.path(name)
+		text, err := os.ReadFile(path)
+		if err == nil {
+			return Prompt{Name: name, Text: string(text), Source: SourceUser, Path: path, Overrides: hasBuiltin}, true, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return Prompt{}, false, fmt.Errorf("failed to read prompt %q: %w", name, err)
+		}
+	}
+
+	if hasBuiltin {
+		return builtin, true, nil
+	}
+	return Prompt{}, false, nil
+}
+
+// List returns every available prompt sorted by name.
+func (l Library) List() ([]Prompt, error) {
+	byName := make(map[string]Prompt)
+
+	builtins, err := fs.Glob(builtinFS, "builtin/*"+extension)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range builtins {
+		name := strings.TrimSuffix(filepath.Base(path), extension)
+		if builtin, ok := readBuiltin(name); ok {
+			byName[name] = builtin
+		}
+	}
+
+	if l.dir != "" {
+		entries, err := os.ReadDir(l.dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read prompt directory: %w", err)
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry.Name(), extension)
+			if entry.IsDir() || filepath.Ext(entry.Name()) != extension || ValidateName(name) != nil {
+				continue
+			}
+			found, ok, err := l.Lookup(name)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				byName[name] = found
+			}
+		}
+	}
+
+	list := make([]Prompt, 0, len(byName))
+	for _, name := range slices.Sorted(maps.Keys(byName)) {
+		list = append(list, byName[name])
+	}
+	return list, nil
+}
+
+// Add stores a new user prompt. It fails when a user prompt with that name exists.
+func (l Library) Add(name, text string) (Prompt, error) {
+	if err := ValidateName(name); err != nil {
+		return Prompt{}, err
+	}
+	if _, err := os.Stat(l.path(name)); err == nil {
+		return Prompt{}, fmt.Errorf("prompt %q already exists, use edit to change it", name)
+	}
+	return l.Save(name, text)
+}
+
+// Save writes a user prompt, creating or replacing it.
+func (l Library) Save(name, text string) (Prompt, error) {
+	if err := ValidateName(name); err != nil {
+		return Prompt{}, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return Prompt{}, fmt.Errorf("prompt %q is empty", name)
+	}
+	if l.dir == "" {
+		return Prompt{}, fmt.Errorf("no prompt directory configured")
+	}
+
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return Prompt{}, fmt.Errorf("failed to create prompt directory: %w", err)
+	}
+	if err := os.WriteFile(l.path(name), []byte(text), 0644); err != nil {
+		return Prompt{}, fmt.Errorf("failed to write prompt %q: %w", name, err)
+	}
+
+	_, overrides := readBuiltin(name)
+	return Prompt{Name: name, Text: text, Source: SourceUser, Path: l.path(name), Overrides: overrides}, nil
+}
+
+// Remove deletes a user prompt. Built-in prompts cannot be removed.
+func (l Library) Remove(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	err := os.Remove(l.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		if _, ok := readBuiltin(name); ok {
+			return fmt.Errorf("prompt %q is built in and cannot be removed", name)
+		}
+		return fmt.Errorf("prompt %q not found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove prompt %q: %w", name, err)
+	}
+	return nil
+}
+
+// path returns the file a user prompt with this name is stored in.
+func (l Library) path(name string) string {
+	return filepath.Join(l.dir, name+extension)
+}
+
+func readBuiltin(name string) (Prompt, bool) {
+	text, err := builtinFS.ReadFile("builtin/" + name + extension)
+	if err != nil {
+		return Prompt{}, false
+	}
+	return Prompt{Name: name, Text: string(text), Source: SourceBuiltin}, true
+}
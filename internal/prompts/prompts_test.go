@@ -0,0 +1,95 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	utils "nomnom/internal/utils"
+)
+
+func TestResolve(t *testing.T) {
+	library := NewLibrary(t.TempDir())
+	imagePrompt, _ := readBuiltin("images")
+	researchPrompt, _ := readBuiltin("research")
+
+	tests := []struct {
+		name     string
+		prompt   string
+		config   utils.Config
+		expected string
+	}{
+		{name: "Default prompt", prompt: "", config: utils.Config{}, expected: Default},
+		{name: "Images prompt", prompt: "images", config: utils.Config{}, expected: imagePrompt.Text},
+		{name: "Research prompt", prompt: "Research", config: utils.Config{}, expected: researchPrompt.Text},
+		{name: "Config prompt", prompt: "", config: utils.Config{AI: utils.AIConfig{Prompt: "Custom prompt from config"}}, expected: "Custom prompt from config"},
+		{name: "Custom prompt", prompt: "Custom prompt", config: utils.Config{}, expected: "Custom prompt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := library.Resolve(tt.prompt, tt.config)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Fatalf("Resolve() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	if imagePrompt.Text == "" || researchPrompt.Text == "" {
+		t.Fatal("built-in prompts are not embedded")
+	}
+}
+
+func TestLibraryUserPrompts(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "prompts")
+	library := NewLibrary(dir)
+
+	if _, err := library.Add("invoices", "Name invoices by vendor and date."); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := library.Add("invoices", "again"); err == nil {
+		t.Fatal("Add() error = nil, want duplicate error")
+	}
+	if _, err := library.Add("Bad Name", "text"); err == nil {
+		t.Fatal("Add() error = nil, want invalid name error")
+	}
+	override, err := library.Save("images", "Team image prompt")
+	if err != nil || !override.Overrides {
+		t.Fatalf("Save() = %#v, %v, want override of built-in", override, err)
+	}
+
+	resolved, err := library.Resolve("invoices", utils.Config{})
+	if err != nil || resolved != "Name invoices by vendor and date." {
+		t.Fatalf("Resolve() = %q, %v", resolved, err)
+	}
+
+	list, err := library.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var names []string
+	for _, prompt := range list {
+		names = append(names, prompt.Name+":"+string(prompt.Source))
+	}
+	if got := strings.Join(names, ","); got != "images:user,invoices:user,research:built-in" {
+		t.Fatalf("List() = %s", got)
+	}
+
+	if err := library.Remove("research"); err == nil {
+		t.Fatal("Remove() error = nil, want built-in error")
+	}
+	if err := library.Remove("images"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "images.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected user override to be removed, stat error = %v", err)
+	}
+	found, ok, err := library.Lookup("images")
+	if err != nil || !ok || found.Source != SourceBuiltin {
+		t.Fatalf("Lookup() after remove = %#v, %v, %v", found, ok, err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 )
 
 type ModelAnalytics struct {
+	Route            string `json:"route,omitempty"`
 	Provider         string `json:"provider"`
 	Model            string `json:"model"`
 	Requests         int    `json:"requests"`
@@ -46,6 +47,7 @@ type AnalyticsSummary struct {
 }
 
 type AnalyticsUsage struct {
+	Route            string
 	Provider         string
 	Model            string
 	PromptTokens     int
@@ -111,8 +113,9 @@ func (s *AnalyticsStore) RecordAIUsage(usage AnalyticsUsage) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	key := analyticsModelKey(usage.Provider, usage.Model)
+	key := analyticsModelKey(usage.Route, usage.Provider, usage.Model)
 	model := s.session.Models[key]
+	model.Route = usage.Route
 	model.Provider = usage.Provider
 	model.Model = usage.Model
 	model.Requests++
@@ -228,6 +231,7 @@ func mergeSessionIntoSummary(summary *AnalyticsSummary, session SessionAnalytics
 
 	for key, usage := range session.Models {
 		model := summary.Models[key]
+		model.Route = usage.Route
 		model.Provider = usage.Provider
 		model.Model = usage.Model
 		model.Requests += usage.Requests
@@ -239,8 +243,12 @@ func mergeSessionIntoSummary(summary *AnalyticsSummary, session SessionAnalytics
 	}
 }
 
-func analyticsModelKey(provider, model string) string {
-	return provider + ":" + model
+// analyticsModelKey keeps usage of the same model under different routes apart.
+func analyticsModelKey(route, provider, model string) string {
+	if route == "" {
+		return provider + ":" + model
+	}
+	return route + "|" + provider + ":" + model
 }
 
 func writeJSONFile(path string, value any) error {
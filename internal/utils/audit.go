@@ -0,0 +1,265 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// AuditKind says which kind of provider request an audit entry describes.
+type AuditKind string
+
+const (
+	AuditRename   AuditKind = "rename"
+	AuditRefine   AuditKind = "refine"
+	AuditTaxonomy AuditKind = "taxonomy"
+)
+
+// AuditEntry records one request to a provider and what came back.
+type AuditEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Kind       AuditKind `json:"kind"`
+	File       string    `json:"file,omitempty"`       // Path relative to the scanned directory
+	Attempt    int       `json:"attempt"`              // 1 for the first request for this file and kind
+	Route      string    `json:"route,omitempty"`      // ai.routes entry that handled the file
+	Provider   string    `json:"provider"`             // Provider the request was sent to
+	Model      string    `json:"model"`                // Model that answered
+	Prompt     string    `json:"prompt"`               // Resolved system prompt
+	Context    string    `json:"context"`              // User message, truncated or redacted per audit settings
+	Image      string    `json:"image,omitempty"`      // Image attached to the request
+	RetryHint  string    `json:"retry_hint,omitempty"` // Validation failure of the previous attempt
+	Response   string    `json:"response,omitempty"`   // Raw model response
+	Name       string    `json:"name,omitempty"`       // Normalized filename
+	Folder     string    `json:"folder,omitempty"`     // Taxonomy folder chosen
+	Confidence float64   `json:"confidence,omitempty"` // Confidence score of the suggestion
+	Error      string    `json:"error,omitempty"`      // Request or validation error
+	LatencyMS  int64     `json:"latency_ms"`
+}
+
+// AuditLog appends provider requests to .nomnom/audit/<session>.jsonl as they happen.
+type AuditLog struct {
+	path       string
+	maxContext int
+
+	// Scrub, when set, is applied to the context before it is written.
+	Scrub func(string) string
+
+	mu       sync.Mutex
+	file     *os.File
+	attempts map[string]int
+}
+
+// NewAuditLog opens a new audit session under baseDir. It returns nil when auditing is disabled.
+func NewAuditLog(baseDir string, config AuditConfig) (*AuditLog, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	auditDir := filepath.Join(baseDir, ".nomnom", "audit")
+	if err := os.MkdirAll(auditDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	path := filepath.Join(auditDir, fmt.Sprintf("%d.jsonl", time.Now().UnixNano()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return &AuditLog{
+		path:       path,
+		maxContext: config.MaxContext,
+		file:       file,
+		attempts:   make(map[string]int),
+	}, nil
+}
+
+// Path returns the file the audit log writes to.
+func (a *AuditLog) Path() string {
+	if a == nil {
+		return ""
+	}
+	return a.path
+}
+
+// Record numbers the attempt, applies the context settings and appends entry.
+func (a *AuditLog) Record(entry AuditEntry) error {
+	if a == nil {
+		return nil
+	}
+
+	if a.Scrub != nil {
+		entry.Context = a.Scrub(entry.Context)
+	}
+	entry.Context = truncateAuditText(entry.Context, a.maxContext)
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+
+	key := string(entry.Kind) + "\x00" + entry.File
+	a.attempts[key]++
+	if entry.Attempt == 0 {
+		entry.Attempt = a.attempts[key]
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	if _, err := a.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+func (a *AuditLog) Close() error {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return nil
+	}
+
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// ReadAuditLog loads every entry of an audit session file.
+func ReadAuditLog(path string) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// FindAuditLogs looks for .nomnom/audit in the directory of path and its
+// parents, and returns the session logs of the first one found, oldest first.
+func FindAuditLogs(path string) ([]string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		auditDir := filepath.Join(dir, ".nomnom", "audit")
+		if info, err := os.Stat(auditDir); err == nil && info.IsDir() {
+			return AuditLogsIn(auditDir)
+		}
+		if filepath.Dir(dir) == dir {
+			return nil, fmt.Errorf("no .nomnom/audit directory found for %s", path)
+		}
+	}
+}
+
+// AuditLogsIn lists the session logs of an audit directory, oldest first, or
+// returns path itself when it is a single log.
+func AuditLogsIn(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	// Sessions are named by their start time in nanoseconds.
+	logs, err := filepath.Glob(filepath.Join(path, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(logs)
+	return logs, nil
+}
+
+// AuditEntriesFor reads the entries recorded for path from logs. Entries name
+// files relative to the scanned directory, which holds the log in
+// .nomnom/audit; for a log moved elsewhere, path must be given relative to
+// the scanned directory.
+func AuditEntriesFor(logs []string, path string) ([]AuditEntry, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	var matched []AuditEntry
+	for _, log := range logs {
+		entries, err := ReadAuditLog(log)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", log, err)
+		}
+
+		target := filepath.Clean(path)
+		if root, ok := auditRoot(log); ok {
+			if target, err = filepath.Rel(root, abs); err != nil {
+				continue
+			}
+		}
+		for _, entry := range entries {
+			if entry.File != "" && filepath.FromSlash(entry.File) == target {
+				matched = append(matched, entry)
+			}
+		}
+	}
+	return matched, nil
+}
+
+// auditRoot returns the scanned directory of a log kept in .nomnom/audit.
+func auditRoot(log string) (string, bool) {
+	abs, err := filepath.Abs(log)
+	if err != nil {
+		return "", false
+	}
+	auditDir := filepath.Dir(abs)
+	if filepath.Base(auditDir) != "audit" || filepath.Base(filepath.Dir(auditDir)) != ".nomnom" {
+		return "", false
+	}
+	return filepath.Dir(filepath.Dir(auditDir)), true
+}
+
+func truncateAuditText(text string, limit int) string {
+	if limit <= 0 || len(text) <= limit {
+		return text
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s…[truncated %d bytes]", text[:cut], len(text)-cut)
+}
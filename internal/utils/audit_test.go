@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLogRecordsAttemptsAndTruncates(t *testing.T) {
+	baseDir := t.TempDir()
+	audit, err := NewAuditLog(baseDir, AuditConfig{Enabled: true, MaxContext: 10})
+	if err != nil {
+		t.Fatalf("NewAuditLog() error = %v", err)
+	}
+	audit.Scrub = func(text string) string { return strings.ReplaceAll(text, "secret", "[x]") }
+
+	if filepath.Dir(audit.Path()) != filepath.Join(baseDir, ".nomnom", "audit") {
+		t.Fatalf("unexpected audit path %q", audit.Path())
+	}
+
+	if err := audit.Record(AuditEntry{Kind: AuditRename, File: "a.txt", Context: "secret notes about taxes", Error: "invalid response"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := audit.Record(AuditEntry{Kind: AuditRename, File: "a.txt", Context: "short", Name: "tax_notes.txt"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := audit.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	entries, err := ReadAuditLog(audit.Path())
+	if err != nil {
+		t.Fatalf("ReadAuditLog() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ReadAuditLog() len = %d, want 2", len(entries))
+	}
+	if entries[0].Attempt != 1 || entries[1].Attempt != 2 {
+		t.Fatalf("attempts = %d, %d, want 1, 2", entries[0].Attempt, entries[1].Attempt)
+	}
+	if entries[0].Context != "[x] notes …[truncated 11 bytes]" {
+		t.Fatalf("context = %q", entries[0].Context)
+	}
+	if entries[1].Name != "tax_notes.txt" {
+		t.Fatalf("name = %q", entries[1].Name)
+	}
+}
+
+func TestNewAuditLogDisabled(t *testing.T) {
+	audit, err := NewAuditLog(t.TempDir(), AuditConfig{})
+	if err != nil || audit != nil {
+		t.Fatalf("NewAuditLog() = %v, %v, want nil, nil", audit, err)
+	}
+	if err := audit.Record(AuditEntry{}); err != nil {
+		t.Fatalf("Record() on nil log error = %v", err)
+	}
+}
+
+func TestAuditEntriesForFile(t *testing.T) {
+	baseDir := t.TempDir()
+	for _, name := range []string{"first.pdf", "second.pdf"} {
+		audit, err := NewAuditLog(baseDir, AuditConfig{Enabled: true})
+		if err != nil {
+			t.Fatalf("NewAuditLog() error = %v", err)
+		}
+		for _, entry := range []AuditEntry{
+			{Kind: AuditRename, File: filepath.Join("taxes", "scan.pdf"), Name: name},
+			{Kind: AuditRename, File: "scan.pdf", Name: "other.pdf"},
+			{Kind: AuditTaxonomy},
+		} {
+			if err := audit.Record(entry); err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+		}
+		if err := audit.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	target := filepath.Join(baseDir, "taxes", "scan.pdf")
+	logs, err := FindAuditLogs(target)
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("FindAuditLogs() = %v, %v, want both sessions", logs, err)
+	}
+
+	entries, err := AuditEntriesFor(logs, target)
+	if err != nil {
+		t.Fatalf("AuditEntriesFor() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "first.pdf" || entries[1].Name != "second.pdf" {
+		t.Fatalf("AuditEntriesFor() = %+v, want the entries of both sessions in order", entries)
+	}
+
+	// A log copied out of .nomnom/audit matches paths relative to the scanned directory.
+	data, err := os.ReadFile(logs[1])
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	copied := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(copied, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	single, err := AuditLogsIn(copied)
+	if err != nil {
+		t.Fatalf("AuditLogsIn() error = %v", err)
+	}
+	entries, err = AuditEntriesFor(single, filepath.Join("taxes", "scan.pdf"))
+	if err != nil || len(entries) != 1 || entries[0].Name != "second.pdf" {
+		t.Fatalf("AuditEntriesFor() = %+v, %v, want the second session's entry", entries, err)
+	}
+
+	if _, err := FindAuditLogs(filepath.Join(t.TempDir(), "scan.pdf")); err == nil {
+		t.Fatal("FindAuditLogs() error = nil, want no audit directory found")
+	}
+}
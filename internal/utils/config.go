@@ -14,6 +14,9 @@ type Config struct {
 	Output            string                  `json:"output"`             // Output directory for processed files
 	Case              string                  `json:"case"`               // Case identifier or name
 	AI                AIConfig                `json:"ai"`                 // AI-related settings
+	Naming            NamingConfig            `json:"naming"`             // Naming consistency settings
+	Redaction         RedactionConfig         `json:"redaction"`          // Scrubbing of file content sent to hosted providers
+	Audit             AuditConfig             `json:"audit"`              // Request and response audit trail
 	FileHandling      FileHandlingConfig      `json:"file_handling"`      // File processing settings
 	ContentExtraction ContentExtractionConfig `json:"content_extraction"` // Content extraction settings
 	Performance       PerformanceConfig       `json:"performance"`        // Performance tuning settings
@@ -28,13 +31,68 @@ type VisionConfig struct {
 
 // AIConfig contains settings for AI provider integration
 type AIConfig struct {
-	Provider    string       `json:"provider"`          // AI service provider name
-	Model       string       `json:"model"`             // AI model to use
-	APIKey      string       `json:"api_key,omitempty"` // API key for AI service
-	Vision      VisionConfig `json:"vision"`            // Vision processing settings
-	MaxTokens   int          `json:"max_tokens"`        // Maximum tokens for AI responses
-	Temperature float64      `json:"temperature"`       // AI response creativity control
-	Prompt      string       `json:"prompt"`            // Default prompt for AI
+	Provider    string         `json:"provider"`           // AI service provider name
+	Model       string         `json:"model"`              // AI model to use
+	APIKey      string         `json:"api_key,omitempty"`  // API key for AI service
+	Vision      VisionConfig   `json:"vision"`             // Vision processing settings
+	MaxTokens   int            `json:"max_tokens"`         // Maximum tokens for AI responses
+	Temperature float64        `json:"temperature"`        // AI response creativity control
+	Prompt      string         `json:"prompt"`             // Default prompt for AI
+	HTTP        HTTPConfig     `json:"http,omitempty"`     // HTTP transport settings shared by provider clients
+	Metadata    MetadataConfig `json:"metadata,omitempty"` // Templates for the offline metadata provider
+	// MinConfidence leaves files whose suggestion scores below it (0-1) untouched; 0 disables the check
+	MinConfidence float64 `json:"min_confidence,omitempty"`
+	// LowConfidence is "review" (default) to flag uncertain files for review or "keep" to silently keep their name
+	LowConfidence string `json:"low_confidence,omitempty"`
+	// Routes send matching files to their own provider, model and prompt; the first match wins
+	Routes []RouteConfig `json:"routes,omitempty"`
+}
+
+// RouteConfig sends files of some categories or extensions to a specific provider
+type RouteConfig struct {
+	Name       string   `json:"name,omitempty"`       // Label used in analytics, defaults to the matched categories and extensions
+	Categories []string `json:"categories,omitempty"` // Categories to match, e.g. "Images"
+	Extensions []string `json:"extensions,omitempty"` // Extensions to match, e.g. ".heic"
+	Provider   string   `json:"provider,omitempty"`   // Provider for matching files, defaults to ai.provider
+	Model      string   `json:"model,omitempty"`      // Model for matching files
+	APIKey     string   `json:"api_key,omitempty"`    // API key when the provider differs from ai.provider
+	Prompt     string   `json:"prompt,omitempty"`     // Built-in prompt name or prompt text
+	Vision     *bool    `json:"vision,omitempty"`     // Send images and previews for matching files, defaults to ai.vision.enabled
+}
+
+// MetadataConfig configures the offline "metadata" provider
+type MetadataConfig struct {
+	Templates map[string]string `json:"templates,omitempty"` // Name templates keyed by category, "default" for the rest
+}
+
+// HTTPConfig customizes the HTTP client used for every AI provider
+type HTTPConfig struct {
+	Proxy              string            `json:"proxy,omitempty"`                // Proxy URL, overrides HTTP(S)_PROXY
+	CAFile             string            `json:"ca_file,omitempty"`              // PEM bundle trusted in addition to the system roots
+	InsecureSkipVerify bool              `json:"insecure_skip_verify,omitempty"` // Skip TLS verification, for local servers only
+	Headers            map[string]string `json:"headers,omitempty"`              // Extra headers sent with every request
+	BaseURL            string            `json:"base_url,omitempty"`             // Override the provider API base URL
+}
+
+// NamingConfig controls how generated names relate to each other
+type NamingConfig struct {
+	SiblingContext bool   `json:"sibling_context"`    // Include neighboring and already chosen names in each request
+	Language       string `json:"language,omitempty"` // Language generated names are written in, e.g. "English"
+	ASCII          bool   `json:"ascii"`              // Transliterate generated names to ASCII
+}
+
+// RedactionConfig controls what is scrubbed from file content before it is sent to a hosted provider
+type RedactionConfig struct {
+	Disabled bool              `json:"disabled"`           // Send file content unmodified
+	Skip     []string          `json:"skip,omitempty"`     // Built-in detectors to turn off, e.g. "phone"
+	Patterns map[string]string `json:"patterns,omitempty"` // Extra regular expressions keyed by the label used in the report
+}
+
+// AuditConfig controls the per-session JSONL record of every provider request
+type AuditConfig struct {
+	Enabled    bool `json:"enabled"`               // Write .nomnom/audit/<session>.jsonl
+	MaxContext int  `json:"max_context,omitempty"` // Truncate recorded context to this many bytes, 0 keeps it whole
+	Redact     bool `json:"redact"`                // Apply the redaction detectors to recorded context, even for local providers
 }
 
 // FileHandlingConfig defines how files are processed
@@ -50,6 +108,21 @@ type ContentExtractionConfig struct {
 	MaxContentLength int  `json:"max_content_length"` // Maximum content length to process
 	SkipLargeFiles   bool `json:"skip_large_files"`   // Skip files exceeding size limits
 	ReadContext      bool `json:"read_context"`       // Enable context reading
+	// Extractors run local commands for matching files before the built-in extractors; the first match wins
+	Extractors []ExternalExtractorConfig `json:"extractors,omitempty"`
+}
+
+// ExternalExtractorConfig runs a local command whose output becomes the text of matching files
+type ExternalExtractorConfig struct {
+	Name       string   `json:"name,omitempty"`       // Label used in warnings, defaults to the program name
+	Extensions []string `json:"extensions,omitempty"` // Extensions to match, e.g. ".pdf"
+	MIMETypes  []string `json:"mime_types,omitempty"` // Detected content types to match, "image/*" matches a family
+	// Command is the program and its arguments; {path} is replaced by the file and {preview}
+	// by an image path the command may write. Without {path}, the file is the last argument.
+	Command  []string `json:"command"`
+	Timeout  string   `json:"timeout,omitempty"`  // Time limit per file, defaults to 30s
+	Preview  string   `json:"preview,omitempty"`  // Image format written to {preview}: png (default), jpg or webp
+	Category string   `json:"category,omitempty"` // Output folder, defaults to the category of the built-in format
 }
 
 // PerformanceConfig holds performance optimization settings
@@ -6,6 +6,8 @@ const (
 	ApprovalYes ApprovalDecision = "yes"
 	ApprovalNo  ApprovalDecision = "no"
 	ApprovalAll ApprovalDecision = "all"
+	// ApprovalRefine asks for another suggestion based on user feedback.
+	ApprovalRefine ApprovalDecision = "refine"
 )
 
 type Reporter interface {
@@ -18,6 +20,21 @@ type Approver interface {
 	Approve(action, oldName, newName string) (ApprovalDecision, error)
 }
 
+// FeedbackCollector asks the user how a suggested name should change.
+type FeedbackCollector interface {
+	Feedback(oldName, newName string) (string, error)
+}
+
+// TaxonomyFolder is a destination folder proposed for a whole scan.
+type TaxonomyFolder struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type TaxonomyReviewer interface {
+	ReviewTaxonomy(folders []TaxonomyFolder) ([]TaxonomyFolder, error)
+}
+
 type NopReporter struct{}
 
 func (NopReporter) Infof(string, ...any)  {}
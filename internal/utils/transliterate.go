@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations covers letters that do not decompose into an ASCII base
+// letter plus combining marks, and German umlauts which are conventionally
+// expanded rather than stripped.
+var transliterations = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue",
+	"ß", "ss", "ẞ", "SS",
+	"æ", "ae", "Æ", "AE", "œ", "oe", "Œ", "OE",
+	"ø", "o", "Ø", "O", "å", "aa", "Å", "Aa",
+	"ł", "l", "Ł", "L", "đ", "d", "Đ", "D", "ð", "d", "Ð", "D",
+	"þ", "th", "Þ", "Th", "ı", "i",
+	"–", "-", "—", "-", "‘", "", "’", "", "“", "", "”", "",
+)
+
+// ToASCII transliterates s to ASCII where a Latin equivalent exists.
+// Characters without one, such as kana or CJK ideographs, are left in place so
+// callers can detect them with IsASCII.
+func ToASCII(s string) string {
+	// Compose first so decomposed umlauts, as macOS writes file names, are
+	// expanded like precomposed ones.
+	s = transliterations.Replace(norm.NFC.String(s))
+	stripMarks := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	result, _, err := transform.String(stripMarks, s)
+	if err != nil {
+		return s
+	}
+	return result
+}
+
+// IsASCII reports whether s only contains ASCII characters.
+func IsASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
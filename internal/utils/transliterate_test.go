@@ -0,0 +1,32 @@
+package utils
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "jahresabschluss_größe_übersicht", expected: "jahresabschluss_groesse_uebersicht"},
+		{input: "café_crème", expected: "cafe_creme"},
+		{input: "łódź_straße", expected: "lodz_strasse"},
+		{input: "plain_ascii", expected: "plain_ascii"},
+		{input: "ｆｕｌｌｗｉｄｔｈ", expected: "fullwidth"},
+		{input: "u\u0308bersicht_Mu\u0308ller", expected: "uebersicht_Mueller"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := ToASCII(tt.input); result != tt.expected {
+				t.Errorf("ToASCII(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToASCIILeavesUntransliterableRunes(t *testing.T) {
+	result := ToASCII("かいぎ_notes")
+	if IsASCII(result) {
+		t.Fatalf("ToASCII() = %q, want kana to remain so callers can reject it", result)
+	}
+}